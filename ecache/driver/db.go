@@ -1,6 +1,9 @@
 package driver
 
-import "time"
+import (
+	"bytes"
+	"time"
+)
 
 type DB interface {	
 	TX(tx interface{}) TX
@@ -31,4 +34,26 @@ type TX interface{
 	// iterate all the keys have the same prefix, the the feed to fn is not been cut off prefix, you can do this operation by you self
 	// the key passed in fn has been trimed out the prefix
 	Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64)error) error
-}
\ No newline at end of file
+}
+
+// SeekTX is optional for TX, it iterates the keys have the prefix from the first key >= prefix + start
+type SeekTX interface {
+	IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64)error) error
+}
+
+// IterateFrom iterates the keys have the prefix from the first key >= prefix + start, like Iterate,
+// it seeks to start directly if tx implements SeekTX, or else it skips the keys before start
+func IterateFrom(tx TX, prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64)error) error {
+	if st, ok := tx.(SeekTX); ok {
+		return st.IterateFrom(prefix, start, fn)
+	}
+
+	idx := -1
+	return tx.Iterate(prefix, func(_ int, key []byte, val []byte, expiredAt uint64) error {
+		if bytes.Compare(key, start) < 0 {
+			return nil
+		}
+		idx += 1
+		return fn(idx, key, val, expiredAt)
+	})
+}
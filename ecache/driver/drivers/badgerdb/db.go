@@ -1,14 +1,34 @@
 package badgerdb
 
 import (
+	"errors"
+	"fmt"
+
 	badger "github.com/dgraph-io/badger/v4"
 	"github.com/dgraph-io/badger/v4/options"
 	"github.com/ziyht/eden_go/ecache/driver"
 )
 
 type cfg struct {
-	Dir       string
-	InMemory  bool
+	Dir              string
+	InMemory         bool
+	Sync             bool
+	ReadOnly         bool
+	MemTableSize     int64    // <= 0 means using default value
+	BlockCache       int64    //  < 0 means using default value
+	Compression      string
+	ValueThreshold   int64    // <= 0 means using default value
+	ValueLogFileSize int64    // <= 0 means using default value
+	NumMemtables     int64    // <= 0 means using default value
+	NumCompactors    int64    // <= 0 means using default value
+	IndexCache       int64    //  < 0 means using default value
+	ZSTDLevel        int64    // <= 0 means using default value
+}
+
+var compressions = map[string]options.CompressionType{
+	"none"  : options.None,
+	"snappy": options.Snappy,
+	"zstd"  : options.ZSTD,
 }
 
 type DB struct {
@@ -18,10 +38,31 @@ type DB struct {
 
 func newDB(cfg *cfg) (*DB, error){
 
-	opts := badger.DefaultOptions(cfg.Dir)
+	dir := cfg.Dir
+	if cfg.InMemory {
+		dir = ""
+	}
+
+	opts := badger.DefaultOptions(dir)
 	opts = opts.WithInMemory(cfg.InMemory)
 	opts = opts.WithLoggingLevel(badger.WARNING)
-	opts = opts.WithCompression(options.Snappy)
+	opts = opts.WithSyncWrites(cfg.Sync)
+	opts = opts.WithReadOnly(cfg.ReadOnly)
+
+	compression, ok := compressions[cfg.Compression]
+	if !ok {
+		compression = options.Snappy
+	}
+	opts = opts.WithCompression(compression)
+
+	if cfg.MemTableSize     >  0 { opts = opts.WithMemTableSize(cfg.MemTableSize) }
+	if cfg.BlockCache       >= 0 { opts = opts.WithBlockCacheSize(cfg.BlockCache) }
+	if cfg.ValueThreshold   >  0 { opts = opts.WithValueThreshold(cfg.ValueThreshold) }
+	if cfg.ValueLogFileSize >  0 { opts = opts.WithValueLogFileSize(cfg.ValueLogFileSize) }
+	if cfg.NumMemtables     >  0 { opts = opts.WithNumMemtables(int(cfg.NumMemtables)) }
+	if cfg.NumCompactors    >  0 { opts = opts.WithNumCompactors(int(cfg.NumCompactors)) }
+	if cfg.IndexCache       >= 0 { opts = opts.WithIndexCacheSize(cfg.IndexCache) }
+	if cfg.ZSTDLevel        >  0 { opts = opts.WithZSTDCompressionLevel(int(cfg.ZSTDLevel)) }
 
 	db, err := badger.Open(opts)
 	if err != nil {
@@ -38,24 +79,34 @@ func (db *DB)TX(tx interface{}) driver.TX {
 	return &TX{txn: tx.(*badger.Txn)}
 }
 
+// __mapErr maps the native errors of badger to the sentinel errors of driver
+func __mapErr(err error) error {
+	switch {
+	case err == nil                        : return nil
+	case err == badger.ErrConflict         : return driver.ErrTxConflict
+	case errors.Is(err, badger.ErrDBClosed): return fmt.Errorf("badger: %w", driver.ErrClosed)
+	}
+	return err
+}
+
 func (db *DB)Update(fn func(tx driver.TX) error) error {
-	return db.db.Update(func(txn *badger.Txn)error{
+	return __mapErr(db.db.Update(func(txn *badger.Txn)error{
 		return fn(db.TX(txn))
-	})
+	}))
 }
 
 func (db *DB)View(fn func(tx driver.TX) error) error {
-	return db.db.View(func(txn *badger.Txn)error{
+	return __mapErr(db.db.View(func(txn *badger.Txn)error{
 		return fn(db.TX(txn))
-	})
+	}))
 }
 
 func(db *DB)DropPrefix(prefix []byte) (error) {
-	return db.db.DropPrefix(prefix)
+	return __mapErr(db.db.DropPrefix(prefix))
 }
 
 func(db *DB)Truncate() (error) {
-	return db.db.DropAll()
+	return __mapErr(db.db.DropAll())
 }
 
 func (db *DB)Close() error{
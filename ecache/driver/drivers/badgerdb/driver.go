@@ -4,6 +4,23 @@ import (
 	"github.com/ziyht/eden_go/ecache/driver"
 )
 
+/*
+  params supported by badger driver:
+
+    -- name --------------   -- type --   -- desc --
+    sync                     bool         sync writes to disk before return, default false
+    readonly                 bool         open the db in readonly mode, default false
+    mem_table_size           size         the size of each memtable, default 64MB
+    block_cache              size         the size of block cache, default 256MB
+    compression              enum         none, snappy, zstd, default snappy
+    in_memory                bool         run the db in memory, the dir will be ignored, default false
+    value_threshold          size         the values larger than it will be stored in value log, default 1MB
+    value_log_file_size      size         the max size of a value log file, default 1GB
+    num_memtables            int          the max number of memtables, default 5
+    num_compactors           int          the number of compaction workers, default 4
+    index_cache              size         the size of index cache, default 0(all indices are kept in memory)
+    zstd_level               int          the zstd compression level, default 1
+*/
 
 type myDriver struct {
 }
@@ -11,10 +28,40 @@ type myDriver struct {
 var driverName = "badger"
 var insDriver  = &myDriver{}
 
+var params = []driver.Param{
+	{Name: driver.PARAM_SYNC          , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_READONLY      , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_MEM_TABLE_SIZE, Kind: driver.PARAM_SIZE},
+	{Name: driver.PARAM_BLOCK_CACHE   , Kind: driver.PARAM_SIZE},
+	{Name: driver.PARAM_COMPRESSION   , Kind: driver.PARAM_ENUM, Enums: []string{"none", "snappy", "zstd"}},
+	{Name: driver.PARAM_IN_MEMORY     , Kind: driver.PARAM_BOOL, Aliases: []string{"memory", "in-memory"}},
+	{Name: "value_threshold"          , Kind: driver.PARAM_SIZE},
+	{Name: "value_log_file_size"      , Kind: driver.PARAM_SIZE},
+	{Name: "num_memtables"            , Kind: driver.PARAM_INT},
+	{Name: "num_compactors"           , Kind: driver.PARAM_INT},
+	{Name: "index_cache"              , Kind: driver.PARAM_SIZE},
+	{Name: "zstd_level"               , Kind: driver.PARAM_INT},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
 func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, error) {
 	cfg := cfg{
-		Dir     : path,
-		InMemory: driver.GetBool(params, "memory") || driver.GetBool(params, "in-memory"),
+		Dir              : path,
+		InMemory         : driver.GetBool(params, driver.PARAM_IN_MEMORY),
+		Sync             : driver.GetBool(params, driver.PARAM_SYNC),
+		ReadOnly         : driver.GetBool(params, driver.PARAM_READONLY),
+		MemTableSize     : driver.GetSize(params, driver.PARAM_MEM_TABLE_SIZE, 0),
+		BlockCache       : driver.GetSize(params, driver.PARAM_BLOCK_CACHE, -1),
+		Compression      : driver.GetStr (params, driver.PARAM_COMPRESSION, "snappy"),
+		ValueThreshold   : driver.GetSize(params, "value_threshold", 0),
+		ValueLogFileSize : driver.GetSize(params, "value_log_file_size", 0),
+		NumMemtables     : driver.GetInt (params, "num_memtables", 0),
+		NumCompactors    : driver.GetInt (params, "num_compactors", 0),
+		IndexCache       : driver.GetSize(params, "index_cache", -1),
+		ZSTDLevel        : driver.GetInt (params, "zstd_level", 0),
 	}
 
 	return  newDB(&cfg)
@@ -22,4 +69,4 @@ func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, erro
 
 func init() {
 	driver.Register(driverName, insDriver)
-}
\ No newline at end of file
+}
@@ -59,11 +59,15 @@ func (tx *TX)Del(prefix []byte, key []byte) (error){
 }
 
 func (tx *TX)Iterate(prefix []byte, fn func(idx int, key []byte, val[]byte, expiresAt uint64)error) (error){
+	return tx.IterateFrom(prefix, nil, fn)
+}
+
+func (tx *TX)IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val[]byte, expiresAt uint64)error) (error){
 	it := tx.txn.NewIterator(badger.DefaultIteratorOptions)
 	defer it.Close()
 	idx := -1
 	prelen := len(prefix)
-	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+	for it.Seek(append(append([]byte{}, prefix...), start...)); it.ValidForPrefix(prefix); it.Next() {
 		idx += 1
 		e := it.Item()
 		key := e.KeyCopy(nil)
@@ -0,0 +1,173 @@
+package bbolt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+type cfg struct {
+	Path            string
+	Sync            bool
+	ReadOnly        bool
+	Timeout         time.Duration
+	InitialMmapSize int64
+	NoFreelistSync  bool
+	GCInterval      time.Duration
+}
+
+// all the keys are stored in this bucket
+var bucketName = []byte("ecache")
+
+type DB struct {
+	db      *bolt.DB
+	cfg     *cfg
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+func newDB(cfg *cfg) (*DB, error){
+	if !cfg.ReadOnly {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	opts := &bolt.Options{
+		Timeout        : cfg.Timeout,
+		ReadOnly       : cfg.ReadOnly,
+		InitialMmapSize: int(cfg.InitialMmapSize),
+		NoFreelistSync : cfg.NoFreelistSync,
+		FreelistType   : bolt.FreelistMapType,
+	}
+
+	db, err := bolt.Open(cfg.Path, 0644, opts)
+	if err != nil {
+		return nil, err
+	}
+	db.NoSync = !cfg.Sync
+
+	if !cfg.ReadOnly {
+		if err = db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(bucketName)
+			return err
+		}); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	out := &DB{db: db, cfg: cfg}
+	if !cfg.ReadOnly && cfg.GCInterval > 0 {
+		out.quit = make(chan struct{})
+		out.done = make(chan struct{})
+		go out.gcLoop()
+	}
+
+	return out, nil
+}
+
+func (db *DB)gcLoop() {
+	defer close(db.done)
+
+	ticker := time.NewTicker(db.cfg.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C: db.gc()
+		case <-db.quit : return
+		}
+	}
+}
+
+// gc removes all the expired keys
+func (db *DB)gc() error {
+	return db.db.Update(func(txn *bolt.Tx) error {
+		b := txn.Bucket(bucketName)
+		if b == nil {
+			return nil
+		}
+
+		now := uint64(time.Now().UnixNano())
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; {
+			if __isExpired(v, now) {
+				next := append([]byte(nil), k...)
+				if err := c.Delete(); err != nil {
+					return err
+				}
+				k, v = c.Seek(next)
+				continue
+			}
+			k, v = c.Next()
+		}
+		return nil
+	})
+}
+
+func (db *DB)TX(tx interface{}) driver.TX {
+	return &TX{txn: tx.(*bolt.Tx)}
+}
+
+// __mapErr maps the native errors of bbolt to the sentinel errors of driver
+func __mapErr(err error) error {
+	if errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		return fmt.Errorf("bbolt: %w", driver.ErrClosed)
+	}
+	return err
+}
+
+func (db *DB)Update(fn func(tx driver.TX) error) error {
+	return __mapErr(db.db.Update(func(txn *bolt.Tx)error{
+		return fn(db.TX(txn))
+	}))
+}
+
+func (db *DB)View(fn func(tx driver.TX) error) error {
+	return __mapErr(db.db.View(func(txn *bolt.Tx)error{
+		return fn(db.TX(txn))
+	}))
+}
+
+func(db *DB)DropPrefix(prefix []byte) (error) {
+	return __mapErr(db.db.Update(func(txn *bolt.Tx)error{
+		b := txn.Bucket(bucketName)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func(db *DB)Truncate() (error) {
+	return __mapErr(db.db.Update(func(txn *bolt.Tx)error{
+		if err := txn.DeleteBucket(bucketName); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err := txn.CreateBucket(bucketName)
+		return err
+	}))
+}
+
+func (db *DB)Close() error{
+	if db.quit != nil {
+		close(db.quit)
+		<-db.done
+		db.quit = nil
+	}
+	return db.db.Close()
+}
@@ -0,0 +1,58 @@
+package bbolt
+
+import (
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+/*
+  params supported by bbolt driver:
+
+    -- name ------------   -- type --   -- desc --
+    sync                   bool         sync writes to disk before return, default false
+    readonly               bool         open the db in readonly mode, default false
+    timeout                duration     the max waiting time to obtain the file lock, default 1s
+    initial_mmap_size      size         the initial mmap size of the db file, default 0
+    no_freelist_sync       bool         do not sync freelist to disk, default true
+    gc_interval            duration     the interval to remove the expired keys, default 1m, 0 means disable
+
+  the path in dsn is the path of db file, like: bbolt:./cache/ecache.db
+*/
+
+type myDriver struct {
+}
+
+var driverName = "bbolt"
+var insDriver  = &myDriver{}
+
+var params = []driver.Param{
+	{Name: driver.PARAM_SYNC    , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_READONLY, Kind: driver.PARAM_BOOL},
+	{Name: "timeout"            , Kind: driver.PARAM_DURATION},
+	{Name: "initial_mmap_size"  , Kind: driver.PARAM_SIZE},
+	{Name: "no_freelist_sync"   , Kind: driver.PARAM_BOOL},
+	{Name: "gc_interval"        , Kind: driver.PARAM_DURATION},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
+func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, error) {
+	cfg := cfg{
+		Path           : path,
+		Sync           : driver.GetBool(params, driver.PARAM_SYNC),
+		ReadOnly       : driver.GetBool(params, driver.PARAM_READONLY),
+		Timeout        : driver.GetDuration(params, "timeout", time.Second),
+		InitialMmapSize: driver.GetSize(params, "initial_mmap_size", 0),
+		NoFreelistSync : !driver.Has(params, "no_freelist_sync") || driver.GetBool(params, "no_freelist_sync"),
+		GCInterval     : driver.GetDuration(params, "gc_interval", time.Minute),
+	}
+
+	return newDB(&cfg)
+}
+
+func init() {
+	driver.Register(driverName, insDriver)
+}
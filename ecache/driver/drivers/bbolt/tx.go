@@ -0,0 +1,164 @@
+package bbolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+  the stored value is: [expiresAt(8 bytes, big endian, unix nano, 0 means never expire)][val]
+*/
+
+const __metaLen = 8
+
+type TX struct {
+	txn *bolt.Tx
+}
+
+func __genStoreKey(prefix []byte, setkey []byte)(storeKey []byte) {
+	out := make([]byte, 0, len(prefix) + len(setkey))
+	out = append(out, prefix...)
+	out = append(out, setkey...)
+	return out
+}
+
+func __genStoreVal(val []byte, ttl ...time.Duration) []byte {
+	var expiresAt uint64
+	if len(ttl) > 0 && ttl[0] > 0 {
+		expiresAt = uint64(time.Now().Add(ttl[0]).UnixNano())
+	}
+
+	out := make([]byte, __metaLen, __metaLen + len(val))
+	binary.BigEndian.PutUint64(out, expiresAt)
+	return append(out, val...)
+}
+
+func __expiresAt(stored []byte) uint64 {
+	if len(stored) < __metaLen {
+		return 0
+	}
+	return binary.BigEndian.Uint64(stored)
+}
+
+func __isExpired(stored []byte, now uint64) bool {
+	expiresAt := __expiresAt(stored)
+	return expiresAt != 0 && expiresAt <= now
+}
+
+// __parseStoreVal returns a copy of val and the expiresAt in unix seconds
+func __parseStoreVal(stored []byte) ([]byte, uint64, error) {
+	if len(stored) < __metaLen {
+		return nil, 0, fmt.Errorf("invalid stored data, must be at least %d bytes", __metaLen)
+	}
+
+	val := make([]byte, len(stored) - __metaLen)
+	copy(val, stored[__metaLen:])
+
+	return val, __expiresAt(stored) / uint64(time.Second), nil
+}
+
+func (tx *TX)bucket() (*bolt.Bucket, error) {
+	b := tx.txn.Bucket(bucketName)
+	if b == nil {
+		if !tx.txn.Writable() {
+			return nil, nil
+		}
+		return tx.txn.CreateBucket(bucketName)
+	}
+	return b, nil
+}
+
+func (tx *TX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error{
+	b, err := tx.bucket()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return bolt.ErrTxNotWritable
+	}
+
+	return b.Put(__genStoreKey(prefix, key), __genStoreVal(val, ttl...))
+}
+
+// Get gets the val of key, del is only valid in Update, ErrTxNotWritable will be returned if del in View
+func (tx *TX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error){
+	if len(del) > 0 && del[0] && !tx.txn.Writable() {
+		return nil, 0, bolt.ErrTxNotWritable
+	}
+
+	b, err := tx.bucket()
+	if err != nil || b == nil {
+		return nil, 0, err
+	}
+
+	k := __genStoreKey(prefix, key)
+	stored := b.Get(k)
+	if stored == nil || __isExpired(stored, uint64(time.Now().UnixNano())) {
+		return nil, 0, nil
+	}
+
+	val, expiresAt, err := __parseStoreVal(stored)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(del) > 0 && del[0] {
+		if err = b.Delete(k); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return val, expiresAt, nil
+}
+
+func (tx *TX)Del(prefix []byte, key []byte) (error){
+	b, err := tx.bucket()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return bolt.ErrTxNotWritable
+	}
+
+	return b.Delete(__genStoreKey(prefix, key))
+}
+
+func (tx *TX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiresAt uint64)error) (error){
+	return tx.IterateFrom(prefix, nil, fn)
+}
+
+func (tx *TX)IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiresAt uint64)error) (error){
+	b, err := tx.bucket()
+	if err != nil || b == nil {
+		return err
+	}
+
+	idx := -1
+	prelen := len(prefix)
+	now := uint64(time.Now().UnixNano())
+	c := b.Cursor()
+	for k, v := c.Seek(__genStoreKey(prefix, start)); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if __isExpired(v, now) {
+			continue
+		}
+
+		val, expiresAt, err := __parseStoreVal(v)
+		if err != nil {
+			return fmt.Errorf("parse value of key '%s' failed: %s", k, err)
+		}
+
+		idx += 1
+		key := make([]byte, len(k) - prelen)
+		copy(key, k[prelen:])
+
+		if err = fn(idx, key, val, expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package nutsdb
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/xujiajun/nutsdb"
@@ -8,8 +9,25 @@ import (
 )
 
 type cfg struct {
-	Dir       string
-	InMemory  bool
+	Dir                  string
+	Sync                 bool
+	SegmentSize          int64    // <= 0 means using default value
+	RWMode               string
+	StartFileLoadingMode string
+	EntryIdxMode         string
+	MaxFdNums            int64    // <= 0 means using default value
+	InMemory             bool     // ignored, kept for the old DSNs
+}
+
+var rwModes = map[string]nutsdb.RWMode{
+	"fileio": nutsdb.FileIO,
+	"mmap"  : nutsdb.MMap,
+}
+
+var entryIdxModes = map[string]nutsdb.EntryIdxMode{
+	"key_val"   : nutsdb.HintKeyValAndRAMIdxMode,
+	"key"       : nutsdb.HintKeyAndRAMIdxMode,
+	"bpt_sparse": nutsdb.HintBPTSparseIdxMode,
 }
 
 type DB struct {
@@ -22,7 +40,23 @@ func newDB(cfg *cfg) (*DB, error){
 	opts := nutsdb.DefaultOptions
 	opts.RWMode      = nutsdb.MMap
 	opts.Dir         = cfg.Dir
-	opts.SyncEnable  = false
+	opts.SyncEnable  = cfg.Sync
+
+	if m, ok := rwModes[cfg.RWMode]; ok {
+		opts.RWMode = m
+	}
+	if m, ok := rwModes[cfg.StartFileLoadingMode]; ok {
+		opts.StartFileLoadingMode = m
+	}
+	if m, ok := entryIdxModes[cfg.EntryIdxMode]; ok {
+		opts.EntryIdxMode = m
+	}
+	if cfg.SegmentSize > 0 {
+		opts.SegmentSize = cfg.SegmentSize
+	}
+	if cfg.MaxFdNums > 0 {
+		opts.MaxFdNumsInCache = int(cfg.MaxFdNums)
+	}
 	
 	db, err := nutsdb.Open(opts)
 	if err != nil {
@@ -39,22 +73,30 @@ func (db *DB)TX(tx interface{}) driver.TX {
 	return &TX{txn: tx.(*nutsdb.Tx)}
 }
 
+// __mapErr maps the native errors of nutsdb to the sentinel errors of driver
+func __mapErr(err error) error {
+	if nutsdb.IsDBClosed(err) {
+		return fmt.Errorf("nutsdb: %w", driver.ErrClosed)
+	}
+	return err
+}
+
 func (db *DB)Update(fn func(tx driver.TX) error) error {
-	return db.db.Update(func(txn *nutsdb.Tx)error{
+	return __mapErr(db.db.Update(func(txn *nutsdb.Tx)error{
 		return fn(db.TX(txn))
-	})
+	}))
 }
 
 func (db *DB)View(fn func(tx driver.TX) error) error {
-	return db.db.View(func(txn *nutsdb.Tx)error{
+	return __mapErr(db.db.View(func(txn *nutsdb.Tx)error{
 		return fn(db.TX(txn))
-	})
+	}))
 }
 
 func(db *DB)DropPrefix(prefix []byte) (error) {
-	return db.db.Update(func(tx *nutsdb.Tx)error{
+	return __mapErr(db.db.Update(func(tx *nutsdb.Tx)error{
 		return tx.DeleteBucket(nutsdb.DataStructureBPTree, string(prefix))
-	})
+	}))
 }
 
 func(db *DB)Truncate() (error) {
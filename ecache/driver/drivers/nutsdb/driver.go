@@ -4,16 +4,50 @@ import (
 	"github.com/ziyht/eden_go/ecache/driver"
 )
 
+/*
+  params supported by nutsdb driver:
+
+    -- name ----------------   -- type --   -- desc --
+    sync                       bool         sync writes to disk before return, default false
+    segment_size               size         the size of each data file, default 256MB
+    rw_mode                    enum         fileio, mmap, the read and write mode, default mmap
+    start_file_loading_mode    enum         fileio, mmap, the mode to load files when open the db, default mmap
+    entry_idx_mode             enum         key_val, key, bpt_sparse, the mode to index the entries in memory, default key_val
+    max_fd_nums                int          the max numbers of fd in cache, default 0(no limit)
+    in_memory                  bool         accepted for compatibility, 'memory' and 'in-memory' are aliases of it,
+                                            it is ignored since nutsdb always writes to disk
+*/
+
 type myDriver struct {
 }
 
 var driverName = "nutsdb"
 var insDriver  = &myDriver{}
 
+var params = []driver.Param{
+	{Name: driver.PARAM_SYNC        , Kind: driver.PARAM_BOOL},
+	{Name: "segment_size"           , Kind: driver.PARAM_SIZE},
+	{Name: "rw_mode"                , Kind: driver.PARAM_ENUM, Enums: []string{"fileio", "mmap"}},
+	{Name: "start_file_loading_mode", Kind: driver.PARAM_ENUM, Enums: []string{"fileio", "mmap"}},
+	{Name: "entry_idx_mode"         , Kind: driver.PARAM_ENUM, Enums: []string{"key_val", "key", "bpt_sparse"}},
+	{Name: "max_fd_nums"            , Kind: driver.PARAM_INT},
+	{Name: driver.PARAM_IN_MEMORY   , Kind: driver.PARAM_BOOL, Aliases: []string{"memory", "in-memory"}},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
 func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, error) {
 	cfg := cfg{
-		Dir     : path,
-		InMemory: driver.GetBool(params, "memory") || driver.GetBool(params, "in-memory"),
+		Dir                 : path,
+		Sync                : driver.GetBool(params, driver.PARAM_SYNC),
+		SegmentSize         : driver.GetSize(params, "segment_size", 0),
+		RWMode              : driver.GetStr (params, "rw_mode", "mmap"),
+		StartFileLoadingMode: driver.GetStr (params, "start_file_loading_mode", "mmap"),
+		EntryIdxMode        : driver.GetStr (params, "entry_idx_mode", "key_val"),
+		MaxFdNums           : driver.GetInt (params, "max_fd_nums", 0),
+		InMemory            : driver.GetBool(params, driver.PARAM_IN_MEMORY),
 	}
 
 	return newDB(&cfg)
@@ -21,4 +55,4 @@ func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, erro
 
 func init() {
 	driver.Register(driverName, insDriver)
-}
\ No newline at end of file
+}
@@ -1,6 +1,7 @@
 package nutsdb
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -26,6 +27,14 @@ func __validTTL(ttl ...time.Duration) uint32 {
 	return 0
 }
 
+// __expiresAt returns 0 for the entries without ttl
+func __expiresAt(e *nutsdb.Entry) uint64 {
+	if e.Meta.TTL == nutsdb.Persistent {
+		return 0
+	}
+	return e.Meta.Timestamp + uint64(e.Meta.TTL)
+}
+
 func (tx *TX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error{
 	return tx.txn.Put(string(prefix), key, snappy.Encode(nil, val), __validTTL(ttl...))
 }
@@ -33,9 +42,10 @@ func (tx *TX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) er
 func (tx *TX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error){
 	e, err := tx.txn.Get(string(prefix), key)
 	if err != nil {
-		if err != nutsdb.ErrKeyNotFound{
-			return nil, 0, err
+		if errors.Is(err, nutsdb.ErrKeyNotFound) || errors.Is(err, nutsdb.ErrNotFoundKey) || errors.Is(err, nutsdb.ErrBucketNotFound) {
+			return nil, 0, nil
 		}
+		return nil, 0, err
 	}
 
 	if len(del) > 0 && del[0] {
@@ -50,7 +60,7 @@ func (tx *TX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error)
 		return nil, 0, fmt.Errorf("decode data failed: %s", err)
 	}
 
-	return v, e.Meta.Timestamp + uint64(e.Meta.TTL), nil
+	return v, __expiresAt(e), nil
 }
 
 func (tx *TX)Del(prefix []byte, key []byte) (error){
@@ -71,7 +81,7 @@ func (tx *TX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, exp
 			return fmt.Errorf("decode data failed: %s", err)
 		}
 
-		err = fn(i, e.Key, v, e.Meta.Timestamp + uint64(e.Meta.TTL))
+		err = fn(i, e.Key, v, __expiresAt(e))
 
 		if err != nil {
 			return err
@@ -2,20 +2,53 @@ package pebble
 
 import (
 	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
 )
 
 type cfg struct {
-	Dir       string
-	InMemory  bool
+	Dir          string
+	InMemory     bool
+	Sync         bool
+	ReadOnly     bool
+	MemTableSize int64    // <= 0 means using default value
+	BlockCache   int64    // <= 0 means using default value
+	Compression  string
+	DisableWAL   bool
+}
+
+var compressions = map[string]pebble.Compression{
+	"none"  : pebble.NoCompression,
+	"snappy": pebble.SnappyCompression,
+	"zstd"  : pebble.ZstdCompression,
 }
 
 type DB struct {
 	db *pebble.DB
+	wo *pebble.WriteOptions
 }
 
 func newDB(cfg *cfg) (*DB, error){
 
 	opt := pebble.Options{}
+	opt.ReadOnly   = cfg.ReadOnly
+	opt.DisableWAL = cfg.DisableWAL
+	if cfg.InMemory {
+		opt.FS = vfs.NewMem()
+	}
+	if cfg.MemTableSize > 0 {
+		opt.MemTableSize = int(cfg.MemTableSize)
+	}
+	if cfg.BlockCache > 0 {
+		c := pebble.NewCache(cfg.BlockCache)
+		defer c.Unref()
+		opt.Cache = c
+	}
+	if c, ok := compressions[cfg.Compression]; ok {
+		opt.Levels = make([]pebble.LevelOptions, 7)
+		for i := range opt.Levels {
+			opt.Levels[i].Compression = c
+		}
+	}
 
 	db, err := pebble.Open(cfg.Dir, &opt)
 	if err != nil {
@@ -28,13 +61,17 @@ func newDB(cfg *cfg) (*DB, error){
 	// db.DeleteRange()
 	// db.Get()
 	
+	wo := pebble.NoSync
+	if cfg.Sync {
+		wo = pebble.Sync
+	}
 
-	return &DB{db: db}, nil
+	return &DB{db: db, wo: wo}, nil
 }
 
 
 func(db *DB)Set(k []byte, v []byte) error {
 
-	return db.db.Set(k, v, nil)
+	return db.db.Set(k, v, db.wo)
 
 }
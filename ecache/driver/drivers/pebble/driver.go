@@ -1,5 +1,23 @@
 package pebble
 
+import (
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+/*
+  params supported by pebble driver:
+
+    -- name ----------   -- type --   -- desc --
+    sync                 bool         sync writes to disk before return, default false
+    readonly             bool         open the db in readonly mode, default false
+    mem_table_size       size         the size of each memtable, default 4MB
+    block_cache          size         the size of block cache, default 8MB
+    compression          enum         none, snappy, zstd, default snappy
+    in_memory            bool         run the db in memory, default false
+    disable_wal          bool         disable the write-ahead log, default false
+
+  note: this driver is not registered now, for the DB has not implemented driver.DB yet
+*/
 
 type myDriver struct {
 }
@@ -7,3 +25,29 @@ type myDriver struct {
 var driverName = "pebble"
 var insDriver  = &myDriver{}
 
+var params = []driver.Param{
+	{Name: driver.PARAM_SYNC          , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_READONLY      , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_MEM_TABLE_SIZE, Kind: driver.PARAM_SIZE},
+	{Name: driver.PARAM_BLOCK_CACHE   , Kind: driver.PARAM_SIZE},
+	{Name: driver.PARAM_COMPRESSION   , Kind: driver.PARAM_ENUM, Enums: []string{"none", "snappy", "zstd"}},
+	{Name: driver.PARAM_IN_MEMORY     , Kind: driver.PARAM_BOOL, Aliases: []string{"memory", "in-memory"}},
+	{Name: "disable_wal"              , Kind: driver.PARAM_BOOL},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
+func (d *myDriver)parseCfg(path string, params map[string][]string) cfg {
+	return cfg{
+		Dir         : path,
+		InMemory    : driver.GetBool(params, driver.PARAM_IN_MEMORY),
+		Sync        : driver.GetBool(params, driver.PARAM_SYNC),
+		ReadOnly    : driver.GetBool(params, driver.PARAM_READONLY),
+		MemTableSize: driver.GetSize(params, driver.PARAM_MEM_TABLE_SIZE, 0),
+		BlockCache  : driver.GetSize(params, driver.PARAM_BLOCK_CACHE, 0),
+		Compression : driver.GetStr (params, driver.PARAM_COMPRESSION, "snappy"),
+		DisableWAL  : driver.GetBool(params, "disable_wal"),
+	}
+}
@@ -0,0 +1,165 @@
+package redis
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/internal/resp"
+)
+
+type conn struct {
+	nc      net.Conn
+	r       *resp.Reader
+	w       *resp.Writer
+	timeout time.Duration
+	broken  bool
+}
+
+func __toBytes(arg any) []byte {
+	switch a := arg.(type) {
+	case []byte: return a
+	case string: return []byte(a)
+	case int   : return []byte(strconv.Itoa(a))
+	case int64 : return []byte(strconv.FormatInt(a, 10))
+	case uint64: return []byte(strconv.FormatUint(a, 10))
+	}
+	return []byte(fmt.Sprint(arg))
+}
+
+func dial(cfg *cfg) (*conn, error) {
+	nc, err := net.DialTimeout("tcp", cfg.Addr, cfg.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{nc: nc, r: resp.NewReader(nc), w: resp.NewWriter(nc), timeout: cfg.ReadTimeout}
+
+	if cfg.Password != "" {
+		args := []any{"AUTH", cfg.Password}
+		if cfg.Username != "" {
+			args = []any{"AUTH", cfg.Username, cfg.Password}
+		}
+		if _, err = c.do(args...); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("auth failed: %s", err)
+		}
+	}
+
+	if cfg.DB != 0 {
+		if _, err = c.do("SELECT", cfg.DB); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("select db %d failed: %s", cfg.DB, err)
+		}
+	}
+
+	return c, nil
+}
+
+// pipeline sends all the cmds and then reads all the replies,
+// the error replies will be returned in replies instead of err
+func (c *conn) pipeline(cmds ...[]any) (replies []any, err error) {
+	if c.timeout > 0 {
+		c.nc.SetDeadline(time.Now().Add(c.timeout))
+	}
+
+	for _, cmd := range cmds {
+		args := make([][]byte, len(cmd))
+		for i, a := range cmd {
+			args[i] = __toBytes(a)
+		}
+		c.w.WriteCommand(args...)
+	}
+	if err = c.w.Flush(); err != nil {
+		c.broken = true
+		return nil, err
+	}
+
+	replies = make([]any, len(cmds))
+	for i := range cmds {
+		if replies[i], err = c.r.Read(); err != nil {
+			c.broken = true
+			return nil, err
+		}
+	}
+
+	return replies, nil
+}
+
+// do sends a cmd and reads the reply, the error reply will be returned as err
+func (c *conn) do(args ...any) (any, error) {
+	replies, err := c.pipeline(args)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := replies[0].(resp.Error); ok {
+		return nil, e
+	}
+	return replies[0], nil
+}
+
+func (c *conn) close() error {
+	return c.nc.Close()
+}
+
+type pool struct {
+	cfg    *cfg
+	idle   chan *conn
+	sem    chan struct{}
+	closed atomic.Bool
+}
+
+func newPool(cfg *cfg) *pool {
+	return &pool{
+		cfg : cfg,
+		idle: make(chan *conn, cfg.PoolSize),
+		sem : make(chan struct{}, cfg.PoolSize),
+	}
+}
+
+func (p *pool) get() (*conn, error) {
+	if p.closed.Load() {
+		return nil, fmt.Errorf("redis: %w", driver.ErrClosed)
+	}
+
+	p.sem <- struct{}{}
+
+	select {
+	case c := <-p.idle: return c, nil
+	default:
+	}
+
+	c, err := dial(p.cfg)
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *pool) put(c *conn) {
+	defer func() { <-p.sem }()
+
+	if c.broken || p.closed.Load() {
+		c.close()
+		return
+	}
+
+	select {
+	case p.idle <- c:
+	default: c.close()
+	}
+}
+
+func (p *pool) close() error {
+	p.closed.Store(true)
+	for {
+		select {
+		case c := <-p.idle: c.close()
+		default: return nil
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+type cfg struct {
+	Addr        string
+	DB          int64
+	Username    string
+	Password    string
+	Namespace   string
+	PoolSize    int64
+	DialTimeout time.Duration
+	ReadTimeout time.Duration
+	ScanCount   int64
+}
+
+// ErrTxConflict will be returned by Update when the keys read in it are modified by others before committing
+var ErrTxConflict = driver.ErrTxConflict
+
+type DB struct {
+	cfg  *cfg
+	pool *pool
+	ns   []byte
+}
+
+func newDB(cfg *cfg) (*DB, error){
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("the address of redis server is empty")
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 1
+	}
+	if cfg.ScanCount <= 0 {
+		cfg.ScanCount = 1000
+	}
+
+	out := &DB{cfg: cfg, pool: newPool(cfg)}
+	if cfg.Namespace != "" {
+		out.ns = []byte(cfg.Namespace + ":")
+	}
+
+	// checking the connection
+	c, err := out.pool.get()
+	if err != nil {
+		return nil, err
+	}
+	defer out.pool.put(c)
+	if _, err = c.do("PING"); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (db *DB)__storeKey(prefix []byte, key []byte) []byte {
+	out := make([]byte, 0, len(db.ns) + len(prefix) + len(key))
+	out = append(out, db.ns...)
+	out = append(out, prefix...)
+	out = append(out, key...)
+	return out
+}
+
+// __globEscape escapes the special chars of glob-style pattern
+func __globEscape(b []byte) []byte {
+	out := make([]byte, 0, len(b) + 8)
+	for _, c := range b {
+		switch c {
+		case '*', '?', '[', ']', '\\': out = append(out, '\\')
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// __scanKeys returns all the sorted store keys which have the prefix
+func (db *DB)__scanKeys(c *conn, storePrefix []byte) ([][]byte, error) {
+	pattern := append(__globEscape(storePrefix), '*')
+	found   := map[string]bool{}
+	cursor  := "0"
+
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", pattern, "COUNT", db.cfg.ScanCount)
+		if err != nil {
+			return nil, err
+		}
+
+		arr, ok := reply.([]any)
+		if !ok || len(arr) != 2 {
+			return nil, fmt.Errorf("redis: invalid reply of SCAN: %v", reply)
+		}
+		next, _ := arr[0].([]byte)
+		keys, _ := arr[1].([]any)
+		for _, k := range keys {
+			if kb, ok := k.([]byte); ok {
+				found[string(kb)] = true
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			break
+		}
+	}
+
+	out := make([][]byte, 0, len(found))
+	for k := range found {
+		out = append(out, []byte(k))
+	}
+	sort.Slice(out, func(i, j int) bool { return string(out[i]) < string(out[j]) })
+
+	return out, nil
+}
+
+func (db *DB)TX(tx interface{}) driver.TX {
+	return tx.(*TX)
+}
+
+func (db *DB)Update(fn func(tx driver.TX) error) error {
+	c, err := db.pool.get()
+	if err != nil {
+		return err
+	}
+	defer db.pool.put(c)
+
+	tx := newTX(db, c, true)
+	if err = fn(db.TX(tx)); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	return tx.commit()
+}
+
+func (db *DB)View(fn func(tx driver.TX) error) error {
+	c, err := db.pool.get()
+	if err != nil {
+		return err
+	}
+	defer db.pool.put(c)
+
+	return fn(db.TX(newTX(db, c, false)))
+}
+
+func(db *DB)DropPrefix(prefix []byte) (error) {
+	c, err := db.pool.get()
+	if err != nil {
+		return err
+	}
+	defer db.pool.put(c)
+
+	keys, err := db.__scanKeys(c, db.__storeKey(prefix, nil))
+	if err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > 1000 {
+			n = 1000
+		}
+
+		args := make([]any, 0, n + 1)
+		args = append(args, "DEL")
+		for _, k := range keys[:n] {
+			args = append(args, k)
+		}
+		if _, err = c.do(args...); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+// Truncate deletes all the keys in the namespace
+func(db *DB)Truncate() (error) {
+	return db.DropPrefix(nil)
+}
+
+func (db *DB)Close() error{
+	return db.pool.close()
+}
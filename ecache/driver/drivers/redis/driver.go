@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+/*
+  params supported by redis driver:
+
+    -- name ------------   -- type --   -- desc --
+    db                     int          the db index to select, default 0
+    username               string       the username for AUTH, default empty
+    password               string       the password for AUTH, default empty(no AUTH)
+    namespace              string       the namespace of keys, the real key will be <namespace>:<prefix><key>, default ecache
+    pool_size              int          the max number of connections, default 16
+    dial_timeout           duration     the timeout for connecting, default 5s
+    read_timeout           duration     the timeout for each request, default 10s
+    scan_count             int          the COUNT hint for SCAN, default 1000
+
+  the path in dsn is the address of redis server, like: redis:127.0.0.1:6379?db=1&namespace=app1
+
+  notes:
+    1. the writes in Update are buffered and committed by MULTI/EXEC at the end, the keys read in Update are WATCHed,
+       so Update returns ErrTxConflict if any of them are modified by others before committing
+    2. the TTLs are set by PEXPIREAT, and Iterate is implemented by SCAN
+*/
+
+type myDriver struct {
+}
+
+var driverName = "redis"
+var insDriver  = &myDriver{}
+
+var params = []driver.Param{
+	{Name: "db"          , Kind: driver.PARAM_INT},
+	{Name: "username"    , Kind: driver.PARAM_STRING},
+	{Name: "password"    , Kind: driver.PARAM_STRING},
+	{Name: "namespace"   , Kind: driver.PARAM_STRING},
+	{Name: "pool_size"   , Kind: driver.PARAM_INT},
+	{Name: "dial_timeout", Kind: driver.PARAM_DURATION},
+	{Name: "read_timeout", Kind: driver.PARAM_DURATION},
+	{Name: "scan_count"  , Kind: driver.PARAM_INT},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
+func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, error) {
+	cfg := cfg{
+		Addr       : path,
+		DB         : driver.GetInt(params, "db", 0),
+		Username   : driver.GetStr(params, "username", ""),
+		Password   : driver.GetStr(params, "password", ""),
+		Namespace  : driver.GetStr(params, "namespace", "ecache"),
+		PoolSize   : driver.GetInt(params, "pool_size", 16),
+		DialTimeout: driver.GetDuration(params, "dial_timeout", time.Second * 5),
+		ReadTimeout: driver.GetDuration(params, "read_timeout", time.Second * 10),
+		ScanCount  : driver.GetInt(params, "scan_count", 1000),
+	}
+
+	return newDB(&cfg)
+}
+
+func init() {
+	driver.Register(driverName, insDriver)
+}
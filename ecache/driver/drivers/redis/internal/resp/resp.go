@@ -0,0 +1,124 @@
+// Package resp implements the encoding and decoding of REdis Serialization Protocol(RESP2)
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Error is the error reply of RESP, like: -ERR unknown command
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+type Reader struct {
+	br *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{br: bufio.NewReader(r)}
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.br.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("resp: invalid line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// Read reads a reply, the type of returned value can be:
+//   nil     : null bulk string or null array
+//   string  : simple string
+//   Error   : error reply
+//   int64   : integer
+//   []byte  : bulk string
+//   []any   : array
+func (r *Reader) Read() (any, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("resp: empty line")
+	}
+
+	switch line[0] {
+	case '+': return string(line[1:]), nil
+	case '-': return Error(line[1:]), nil
+	case ':': return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("resp: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(r.br, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("resp: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = r.Read(); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("resp: invalid reply type %q", line[0])
+}
+
+type Writer struct {
+	bw *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{bw: bufio.NewWriter(w)}
+}
+
+func (w *Writer) writeHeader(t byte, n int64) {
+	w.bw.WriteByte(t)
+	w.bw.WriteString(strconv.FormatInt(n, 10))
+	w.bw.WriteString("\r\n")
+}
+
+// WriteCommand writes a command as an array of bulk strings
+func (w *Writer) WriteCommand(args ...[]byte) {
+	w.WriteArray(len(args))
+	for _, a := range args {
+		w.WriteBulk(a)
+	}
+}
+
+func (w *Writer) WriteArray(n int)          { w.writeHeader('*', int64(n)) }
+func (w *Writer) WriteInt(n int64)          { w.writeHeader(':', n) }
+func (w *Writer) WriteNull()                { w.bw.WriteString("$-1\r\n") }
+func (w *Writer) WriteNullArray()           { w.bw.WriteString("*-1\r\n") }
+func (w *Writer) WriteSimple(s string)      { w.bw.WriteString("+" + s + "\r\n") }
+func (w *Writer) WriteError(s string)       { w.bw.WriteString("-" + s + "\r\n") }
+func (w *Writer) WriteBulk(b []byte) {
+	w.writeHeader('$', int64(len(b)))
+	w.bw.Write(b)
+	w.bw.WriteString("\r\n")
+}
+
+func (w *Writer) Flush() error {
+	return w.bw.Flush()
+}
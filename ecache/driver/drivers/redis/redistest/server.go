@@ -0,0 +1,593 @@
+// Package redistest provides an in-process RESP server which can stand in for a real redis server in tests,
+// it supports the commands used by the redis driver of ecache:
+//   PING, AUTH, SELECT, QUIT, GET, SET, DEL, EXISTS, MGET, PEXPIREAT, PTTL, SCAN,
+//   WATCH, UNWATCH, MULTI, EXEC, DISCARD, FLUSHDB, DBSIZE
+package redistest
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/internal/resp"
+)
+
+type entry struct {
+	val       []byte
+	expiresAt int64    // unix milli, 0 means never expire
+}
+
+type store struct {
+	data     map[string]*entry
+	versions map[string]uint64
+}
+
+func newStore() *store {
+	return &store{data: map[string]*entry{}, versions: map[string]uint64{}}
+}
+
+type Server struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	dbs      map[int64]*store
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+	closed   bool
+}
+
+type session struct {
+	db      int64
+	authed  bool
+	watched map[string]uint64     // "db:key" -> version
+	multi   bool
+	queued  [][][]byte
+	dirty   bool                  // an error occurred when queuing commands
+}
+
+// NewServer starts a server listening on a random port of 127.0.0.1,
+// if password is set, the clients must AUTH before any other commands
+func NewServer(password ...string) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		ln   : ln,
+		dbs  : map[int64]*store{},
+		conns: map[net.Conn]bool{},
+	}
+	if len(password) > 0 {
+		s.password = password[0]
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns the address the server listening on, like 127.0.0.1:6379
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close stops the server and closes all the connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.ln.Close()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[c] = true
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.handle(c)
+	}
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	r := resp.NewReader(c)
+	w := resp.NewWriter(c)
+	ss := &session{authed: s.password == "", watched: map[string]uint64{}}
+
+	for {
+		req, err := r.Read()
+		if err != nil {
+			return
+		}
+
+		arr, ok := req.([]any)
+		if !ok || len(arr) == 0 {
+			w.WriteError("ERR invalid request")
+			w.Flush()
+			continue
+		}
+
+		args := make([][]byte, len(arr))
+		for i, a := range arr {
+			b, ok := a.([]byte)
+			if !ok {
+				b = []byte(fmt.Sprint(a))
+			}
+			args[i] = b
+		}
+
+		quit := s.dispatch(ss, w, args)
+		if err = w.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(ss *session, w *resp.Writer, args [][]byte) (quit bool) {
+	name := strings.ToUpper(string(args[0]))
+
+	switch name {
+	case "QUIT":
+		w.WriteSimple("OK")
+		return true
+	case "AUTH":
+		pwd := args[len(args)-1]
+		if len(args) < 2 || string(pwd) != s.password {
+			w.WriteError("WRONGPASS invalid username-password pair")
+			return
+		}
+		ss.authed = true
+		w.WriteSimple("OK")
+		return
+	}
+
+	if !ss.authed {
+		w.WriteError("NOAUTH Authentication required.")
+		return
+	}
+
+	if ss.multi {
+		switch name {
+		case "EXEC", "DISCARD", "MULTI", "WATCH":
+		default:
+			if _, ok := commands[name]; !ok {
+				ss.dirty = true
+				w.WriteError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+				return
+			}
+			ss.queued = append(ss.queued, args)
+			w.WriteSimple("QUEUED")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "MULTI":
+		if ss.multi {
+			w.WriteError("ERR MULTI calls can not be nested")
+			return
+		}
+		ss.multi = true
+		w.WriteSimple("OK")
+	case "DISCARD":
+		if !ss.multi {
+			w.WriteError("ERR DISCARD without MULTI")
+			return
+		}
+		ss.reset()
+		w.WriteSimple("OK")
+	case "EXEC":
+		if !ss.multi {
+			w.WriteError("ERR EXEC without MULTI")
+			return
+		}
+		defer ss.reset()
+		if ss.dirty {
+			w.WriteError("EXECABORT Transaction discarded because of previous errors.")
+			return
+		}
+		for k, v := range ss.watched {
+			if s.__version(k) != v {
+				w.WriteNullArray()
+				return
+			}
+		}
+		w.WriteArray(len(ss.queued))
+		for _, q := range ss.queued {
+			s.__exec(ss, w, strings.ToUpper(string(q[0])), q)
+		}
+	case "WATCH":
+		if ss.multi {
+			w.WriteError("ERR WATCH inside MULTI is not allowed")
+			return
+		}
+		for _, k := range args[1:] {
+			wk := ss.watchKey(k)
+			s.__expireIfNeed(s.__db(ss.db), string(k))
+			ss.watched[wk] = s.__version(wk)
+		}
+		w.WriteSimple("OK")
+	case "UNWATCH":
+		ss.watched = map[string]uint64{}
+		w.WriteSimple("OK")
+	default:
+		s.__exec(ss, w, name, args)
+	}
+
+	return
+}
+
+func (ss *session) reset() {
+	ss.multi   = false
+	ss.dirty   = false
+	ss.queued  = nil
+	ss.watched = map[string]uint64{}
+}
+
+func (ss *session) watchKey(k []byte) string {
+	return strconv.FormatInt(ss.db, 10) + ":" + string(k)
+}
+
+func (s *Server) __db(idx int64) *store {
+	st := s.dbs[idx]
+	if st == nil {
+		st = newStore()
+		s.dbs[idx] = st
+	}
+	return st
+}
+
+func (s *Server) __version(watchKey string) uint64 {
+	idx := strings.IndexByte(watchKey, ':')
+	db, _ := strconv.ParseInt(watchKey[:idx], 10, 64)
+	return s.__db(db).versions[watchKey[idx+1:]]
+}
+
+func (s *Server) __expireIfNeed(st *store, key string) *entry {
+	e := st.data[key]
+	if e == nil {
+		return nil
+	}
+	if e.expiresAt != 0 && e.expiresAt <= time.Now().UnixMilli() {
+		delete(st.data, key)
+		st.versions[key] += 1
+		return nil
+	}
+	return e
+}
+
+func (s *Server) __touch(st *store, key string) {
+	st.versions[key] += 1
+}
+
+type command func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte)
+
+var commands = map[string]command{}
+
+func init() {
+	commands["PING"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) > 1 {
+			w.WriteBulk(args[1])
+			return
+		}
+		w.WriteSimple("PONG")
+	}
+
+	commands["SELECT"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) != 2 {
+			w.WriteError("ERR wrong number of arguments for 'select' command")
+			return
+		}
+		idx, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil || idx < 0 {
+			w.WriteError("ERR DB index is out of range")
+			return
+		}
+		ss.db = idx
+		w.WriteSimple("OK")
+	}
+
+	commands["GET"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) != 2 {
+			w.WriteError("ERR wrong number of arguments for 'get' command")
+			return
+		}
+		e := s.__expireIfNeed(st, string(args[1]))
+		if e == nil {
+			w.WriteNull()
+			return
+		}
+		w.WriteBulk(e.val)
+	}
+
+	commands["MGET"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		w.WriteArray(len(args) - 1)
+		for _, k := range args[1:] {
+			e := s.__expireIfNeed(st, string(k))
+			if e == nil {
+				w.WriteNull()
+				continue
+			}
+			w.WriteBulk(e.val)
+		}
+	}
+
+	commands["SET"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) < 3 {
+			w.WriteError("ERR wrong number of arguments for 'set' command")
+			return
+		}
+		e := &entry{val: append([]byte(nil), args[2]...)}
+		for i := 3; i < len(args); i++ {
+			opt := strings.ToUpper(string(args[i]))
+			if (opt != "PX" && opt != "EX") || i+1 >= len(args) {
+				w.WriteError("ERR syntax error")
+				return
+			}
+			n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				w.WriteError("ERR invalid expire time in 'set' command")
+				return
+			}
+			if opt == "EX" {
+				n *= 1000
+			}
+			e.expiresAt = time.Now().UnixMilli() + n
+			i++
+		}
+		st.data[string(args[1])] = e
+		s.__touch(st, string(args[1]))
+		w.WriteSimple("OK")
+	}
+
+	commands["DEL"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		var n int64
+		for _, k := range args[1:] {
+			if s.__expireIfNeed(st, string(k)) != nil {
+				delete(st.data, string(k))
+				s.__touch(st, string(k))
+				n++
+			}
+		}
+		w.WriteInt(n)
+	}
+
+	commands["EXISTS"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		var n int64
+		for _, k := range args[1:] {
+			if s.__expireIfNeed(st, string(k)) != nil {
+				n++
+			}
+		}
+		w.WriteInt(n)
+	}
+
+	commands["PEXPIREAT"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) != 3 {
+			w.WriteError("ERR wrong number of arguments for 'pexpireat' command")
+			return
+		}
+		at, err := strconv.ParseInt(string(args[2]), 10, 64)
+		if err != nil {
+			w.WriteError("ERR value is not an integer or out of range")
+			return
+		}
+		e := s.__expireIfNeed(st, string(args[1]))
+		if e == nil {
+			w.WriteInt(0)
+			return
+		}
+		e.expiresAt = at
+		s.__touch(st, string(args[1]))
+		s.__expireIfNeed(st, string(args[1]))
+		w.WriteInt(1)
+	}
+
+	commands["PTTL"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) != 2 {
+			w.WriteError("ERR wrong number of arguments for 'pttl' command")
+			return
+		}
+		e := s.__expireIfNeed(st, string(args[1]))
+		switch {
+		case e == nil          : w.WriteInt(-2)
+		case e.expiresAt == 0  : w.WriteInt(-1)
+		default                : w.WriteInt(e.expiresAt - time.Now().UnixMilli())
+		}
+	}
+
+	commands["SCAN"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		if len(args) < 2 {
+			w.WriteError("ERR wrong number of arguments for 'scan' command")
+			return
+		}
+		cursor, err := strconv.Atoi(string(args[1]))
+		if err != nil || cursor < 0 {
+			w.WriteError("ERR invalid cursor")
+			return
+		}
+		var pattern []byte
+		count := 10
+		for i := 2; i+1 < len(args); i += 2 {
+			switch strings.ToUpper(string(args[i])) {
+			case "MATCH": pattern = args[i+1]
+			case "COUNT":
+				if count, err = strconv.Atoi(string(args[i+1])); err != nil || count <= 0 {
+					w.WriteError("ERR syntax error")
+					return
+				}
+			default:
+				w.WriteError("ERR syntax error")
+				return
+			}
+		}
+
+		keys := make([]string, 0, len(st.data))
+		for k := range st.data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var found [][]byte
+		next := 0
+		for i := cursor; i < len(keys); i++ {
+			if i - cursor >= count {
+				next = i
+				break
+			}
+			if s.__expireIfNeed(st, keys[i]) == nil {
+				continue
+			}
+			if pattern == nil || Match(pattern, []byte(keys[i])) {
+				found = append(found, []byte(keys[i]))
+			}
+		}
+
+		w.WriteArray(2)
+		w.WriteBulk([]byte(strconv.Itoa(next)))
+		w.WriteArray(len(found))
+		for _, k := range found {
+			w.WriteBulk(k)
+		}
+	}
+
+	commands["FLUSHDB"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		for k := range st.data {
+			delete(st.data, k)
+			s.__touch(st, k)
+		}
+		w.WriteSimple("OK")
+	}
+
+	commands["DBSIZE"] = func(s *Server, ss *session, st *store, w *resp.Writer, args [][]byte) {
+		for k := range st.data {
+			s.__expireIfNeed(st, k)
+		}
+		w.WriteInt(int64(len(st.data)))
+	}
+}
+
+func (s *Server) __exec(ss *session, w *resp.Writer, name string, args [][]byte) {
+	cmd := commands[name]
+	if cmd == nil {
+		w.WriteError(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+
+	cmd(s, ss, s.__db(ss.db), w, args)
+}
+
+// Match reports whether str matches the redis glob-style pattern, supports * ? [abc] [^a] [a-z] and \ escaping
+func Match(pattern, str []byte) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(str); i++ {
+				if Match(pattern[1:], str[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(str) == 0 {
+				return false
+			}
+			str = str[1:]
+		case '[':
+			if len(str) == 0 {
+				return false
+			}
+			end := bytes.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return false
+			}
+			set := pattern[1 : end+1]
+			not := len(set) > 0 && set[0] == '^'
+			if not {
+				set = set[1:]
+			}
+			matched := false
+			for i := 0; i < len(set); i++ {
+				if set[i] == '\\' && i+1 < len(set) {
+					i++
+					matched = matched || set[i] == str[0]
+				} else if i+2 < len(set) && set[i+1] == '-' {
+					lo, hi := set[i], set[i+2]
+					if lo > hi {
+						lo, hi = hi, lo
+					}
+					matched = matched || (str[0] >= lo && str[0] <= hi)
+					i += 2
+				} else {
+					matched = matched || set[i] == str[0]
+				}
+			}
+			if matched == not {
+				return false
+			}
+			pattern = pattern[end+1:]
+			str = str[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(str) == 0 || str[0] != pattern[0] {
+				return false
+			}
+			str = str[1:]
+		}
+		pattern = pattern[1:]
+	}
+
+	return len(str) == 0
+}
@@ -0,0 +1,284 @@
+package redis
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/internal/resp"
+)
+
+// the max count of keys loaded in one MGET when iterating
+const __iterBatch = 1000
+
+// pending is a buffered write in Update
+type pending struct {
+	val       []byte
+	del       bool
+	expiresAt uint64
+}
+
+type TX struct {
+	db       *DB
+	c        *conn
+	writable bool
+	cmds     [][]any
+	writes   map[string]*pending
+	watched  bool
+}
+
+func newTX(db *DB, c *conn, writable bool) *TX {
+	return &TX{db: db, c: c, writable: writable, writes: map[string]*pending{}}
+}
+
+func (tx *TX)__checkWritable() error {
+	if !tx.writable {
+		return fmt.Errorf("redis: can not write in a read-only transaction")
+	}
+	return nil
+}
+
+func (tx *TX)__bufDel(k []byte) {
+	tx.cmds = append(tx.cmds, []any{"DEL", k})
+	tx.writes[string(k)] = &pending{del: true}
+}
+
+func (tx *TX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error{
+	if err := tx.__checkWritable(); err != nil {
+		return err
+	}
+
+	k := tx.db.__storeKey(prefix, key)
+	p := &pending{val: append([]byte{}, val...)}
+	tx.cmds = append(tx.cmds, []any{"SET", k, p.val})
+	if len(ttl) > 0 && ttl[0] > 0 {
+		at := time.Now().Add(ttl[0]).UnixMilli()
+		tx.cmds = append(tx.cmds, []any{"PEXPIREAT", k, at})
+		p.expiresAt = uint64(at / 1000)
+	}
+	tx.writes[string(k)] = p
+
+	return nil
+}
+
+func __expiresAt(pttl any) uint64 {
+	ms, ok := pttl.(int64)
+	if !ok || ms < 0 {
+		return 0
+	}
+	return uint64((time.Now().UnixMilli() + ms) / 1000)
+}
+
+func (tx *TX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error){
+	doDel := len(del) > 0 && del[0]
+	if doDel {
+		if err := tx.__checkWritable(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	k := tx.db.__storeKey(prefix, key)
+	if p := tx.writes[string(k)]; p != nil {
+		if p.del {
+			return nil, 0, nil
+		}
+		if doDel {
+			tx.__bufDel(k)
+		}
+		return append([]byte{}, p.val...), p.expiresAt, nil
+	}
+
+	cmds := [][]any{{"GET", k}, {"PTTL", k}}
+	if tx.writable {
+		cmds = append([][]any{{"WATCH", k}}, cmds...)
+		tx.watched = true
+	}
+	replies, err := tx.c.pipeline(cmds...)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, r := range replies {
+		if e, ok := r.(resp.Error); ok {
+			return nil, 0, e
+		}
+	}
+	replies = replies[len(replies)-2:]
+
+	val, _ := replies[0].([]byte)
+	if val == nil {
+		return nil, 0, nil
+	}
+
+	if doDel {
+		tx.__bufDel(k)
+	}
+
+	return val, __expiresAt(replies[1]), nil
+}
+
+func (tx *TX)Del(prefix []byte, key []byte) (error){
+	if err := tx.__checkWritable(); err != nil {
+		return err
+	}
+
+	tx.__bufDel(tx.db.__storeKey(prefix, key))
+	return nil
+}
+
+type __record struct {
+	k         []byte
+	v         []byte
+	expiresAt uint64
+}
+
+func (tx *TX)__loadBatch(keys [][]byte) ([]__record, error) {
+	var toLoad [][]byte
+	for _, k := range keys {
+		if tx.writes[string(k)] == nil {
+			toLoad = append(toLoad, k)
+		}
+	}
+
+	loaded := map[string]*__record{}
+	if len(toLoad) > 0 {
+		mget := make([]any, 0, len(toLoad) + 1)
+		mget = append(mget, "MGET")
+		cmds := make([][]any, 0, len(toLoad) + 1)
+		cmds = append(cmds, nil)
+		for _, k := range toLoad {
+			mget = append(mget, k)
+			cmds = append(cmds, []any{"PTTL", k})
+		}
+		cmds[0] = mget
+
+		replies, err := tx.c.pipeline(cmds...)
+		if err != nil {
+			return nil, err
+		}
+		if e, ok := replies[0].(resp.Error); ok {
+			return nil, e
+		}
+		vals, _ := replies[0].([]any)
+		if len(vals) != len(toLoad) {
+			return nil, fmt.Errorf("redis: invalid reply of MGET: %v", replies[0])
+		}
+		for i, k := range toLoad {
+			v, _ := vals[i].([]byte)
+			if v == nil {
+				continue
+			}
+			loaded[string(k)] = &__record{k: k, v: v, expiresAt: __expiresAt(replies[i+1])}
+		}
+	}
+
+	out := make([]__record, 0, len(keys))
+	for _, k := range keys {
+		if p := tx.writes[string(k)]; p != nil {
+			out = append(out, __record{k: k, v: append([]byte{}, p.val...), expiresAt: p.expiresAt})
+		} else if r := loaded[string(k)]; r != nil {
+			out = append(out, *r)
+		}
+	}
+
+	return out, nil
+}
+
+func (tx *TX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiresAt uint64)error) (error){
+	storePrefix := tx.db.__storeKey(prefix, nil)
+	keys, err := tx.db.__scanKeys(tx.c, storePrefix)
+	if err != nil {
+		return err
+	}
+
+	// merge the buffered writes
+	if len(tx.writes) > 0 {
+		set := map[string]bool{}
+		for _, k := range keys {
+			set[string(k)] = true
+		}
+		for k, p := range tx.writes {
+			if !bytes.HasPrefix([]byte(k), storePrefix) {
+				continue
+			}
+			if p.del {
+				delete(set, k)
+			} else {
+				set[k] = true
+			}
+		}
+
+		keys = keys[:0]
+		for k := range set {
+			keys = append(keys, []byte(k))
+		}
+		sort.Slice(keys, func(i, j int) bool { return string(keys[i]) < string(keys[j]) })
+	}
+
+	idx := -1
+	prelen := len(storePrefix)
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > __iterBatch {
+			n = __iterBatch
+		}
+
+		rs, err := tx.__loadBatch(keys[:n])
+		if err != nil {
+			return err
+		}
+		for _, r := range rs {
+			idx += 1
+			if err = fn(idx, r.k[prelen:], r.v, r.expiresAt); err != nil {
+				return err
+			}
+		}
+
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+func (tx *TX)rollback() {
+	if tx.watched {
+		tx.c.do("UNWATCH")
+	}
+}
+
+func (tx *TX)commit() error {
+	if len(tx.cmds) == 0 {
+		tx.rollback()
+		return nil
+	}
+
+	cmds := make([][]any, 0, len(tx.cmds) + 2)
+	cmds = append(cmds, []any{"MULTI"})
+	cmds = append(cmds, tx.cmds...)
+	cmds = append(cmds, []any{"EXEC"})
+
+	replies, err := tx.c.pipeline(cmds...)
+	if err != nil {
+		return err
+	}
+
+	// a command failed to be queued, the transaction has been aborted by EXEC with EXECABORT, return the queued error
+	for _, r := range replies[:len(replies)-1] {
+		if e, ok := r.(resp.Error); ok {
+			return e
+		}
+	}
+
+	switch r := replies[len(replies)-1].(type) {
+	case nil       : return ErrTxConflict
+	case resp.Error: return r
+	case []any     :
+		for _, rr := range r {
+			if e, ok := rr.(resp.Error); ok {
+				return e
+			}
+		}
+	}
+
+	return nil
+}
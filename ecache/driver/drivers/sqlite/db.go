@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+	_ "modernc.org/sqlite"
+)
+
+type cfg struct {
+	Path        string
+	Sync        bool
+	ReadOnly    bool
+	BlockCache  int64     // <= 0 means using default value
+	InMemory    bool
+	JournalMode string
+	BusyTimeout time.Duration
+	GCInterval  time.Duration
+}
+
+const __schema = `
+CREATE TABLE IF NOT EXISTS ecache (
+	k          BLOB    PRIMARY KEY,
+	v          BLOB    NOT NULL,
+	expires_at INTEGER NOT NULL DEFAULT 0
+) WITHOUT ROWID;
+CREATE INDEX IF NOT EXISTS ecache_expires_at ON ecache(expires_at) WHERE expires_at != 0;
+`
+
+type DB struct {
+	db      *sql.DB
+	cfg     *cfg
+	quit    chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+}
+
+func (cfg *cfg)genSqliteDsn() string {
+	q := url.Values{}
+	q.Add("_txlock", "immediate")
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", cfg.BusyTimeout.Milliseconds()))
+	if cfg.Sync {
+		q.Add("_pragma", "synchronous(FULL)")
+	} else {
+		q.Add("_pragma", "synchronous(NORMAL)")
+	}
+	if cfg.BlockCache > 0 {
+		q.Add("_pragma", fmt.Sprintf("cache_size(%d)", -cfg.BlockCache / 1024))
+	}
+
+	if cfg.InMemory {
+		return ":memory:?" + q.Encode()
+	}
+
+	q.Add("_pragma", fmt.Sprintf("journal_mode(%s)", cfg.JournalMode))
+	if cfg.ReadOnly {
+		q.Add("mode", "ro")
+	}
+	return "file:" + cfg.Path + "?" + q.Encode()
+}
+
+func newDB(cfg *cfg) (*DB, error){
+	if !cfg.InMemory && !cfg.ReadOnly {
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite", cfg.genSqliteDsn())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.InMemory {
+		// each connection has its own memory db, so we can only use one
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxLifetime(0)
+		db.SetConnMaxIdleTime(0)
+	}
+
+	if !cfg.ReadOnly {
+		if _, err = db.Exec(__schema); err != nil {
+			db.Close()
+			return nil, err
+		}
+	} else if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	out := &DB{db: db, cfg: cfg}
+	if !cfg.ReadOnly && cfg.GCInterval > 0 {
+		out.quit = make(chan struct{})
+		out.done = make(chan struct{})
+		go out.gcLoop()
+	}
+
+	return out, nil
+}
+
+func (db *DB)gcLoop() {
+	defer close(db.done)
+
+	ticker := time.NewTicker(db.cfg.GCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C: db.gc()
+		case <-db.quit : return
+		}
+	}
+}
+
+// gc removes all the expired keys
+func (db *DB)gc() error {
+	_, err := db.db.Exec("DELETE FROM ecache WHERE expires_at != 0 AND expires_at <= ?", time.Now().UnixNano())
+	return err
+}
+
+func (db *DB)TX(tx interface{}) driver.TX {
+	return &TX{txn: tx.(*sql.Tx)}
+}
+
+// __mapErr maps the errors after closed to driver.ErrClosed, database/sql does not export its closed error
+func (db *DB)__mapErr(err error) error {
+	if err != nil && (db.closed.Load() || errors.Is(err, sql.ErrConnDone)) {
+		return fmt.Errorf("sqlite: %w", driver.ErrClosed)
+	}
+	return err
+}
+
+func (db *DB)__exec(readOnly bool, fn func(tx driver.TX) error) error {
+	txn, err := db.db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return db.__mapErr(err)
+	}
+	// rollback if fn failed or panicked, it does nothing after the txn committed
+	defer txn.Rollback()
+
+	if err = fn(db.TX(txn)); err != nil {
+		return err
+	}
+
+	if readOnly {
+		return nil
+	}
+	return db.__mapErr(txn.Commit())
+}
+
+func (db *DB)Update(fn func(tx driver.TX) error) error {
+	return db.__exec(false, fn)
+}
+
+func (db *DB)View(fn func(tx driver.TX) error) error {
+	return db.__exec(true, fn)
+}
+
+func(db *DB)DropPrefix(prefix []byte) (error) {
+	where, args := __prefixCond(prefix)
+	_, err := db.db.Exec("DELETE FROM ecache WHERE 1=1" + where, args...)
+	return db.__mapErr(err)
+}
+
+func(db *DB)Truncate() (error) {
+	_, err := db.db.Exec("DELETE FROM ecache")
+	return db.__mapErr(err)
+}
+
+func (db *DB)Close() error{
+	db.closed.Store(true)
+	if db.quit != nil {
+		close(db.quit)
+		<-db.done
+		db.quit = nil
+	}
+	return db.db.Close()
+}
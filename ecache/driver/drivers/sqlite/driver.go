@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+/*
+  params supported by sqlite driver:
+
+    -- name ------------   -- type --   -- desc --
+    sync                   bool         sync writes to disk before return(synchronous=FULL), default false(synchronous=NORMAL)
+    readonly               bool         open the db in readonly mode, default false
+    block_cache            size         the size of page cache, default 2MB
+    in_memory              bool         run the db in memory, the path will be ignored, default false
+    journal_mode           enum         wal, delete, truncate, persist, memory, off, default wal
+    busy_timeout           duration     the max waiting time when the db is locked by others, default 5s
+    gc_interval            duration     the interval to remove the expired keys, default 1m, 0 means disable
+
+  the path in dsn is the path of db file, like: sqlite:./cache/ecache.sqlite
+
+  note: in in_memory mode, only one connection is used, so do not operate the db in the callbacks of Update/View
+*/
+
+type myDriver struct {
+}
+
+var driverName = "sqlite"
+var insDriver  = &myDriver{}
+
+var params = []driver.Param{
+	{Name: driver.PARAM_SYNC       , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_READONLY   , Kind: driver.PARAM_BOOL},
+	{Name: driver.PARAM_BLOCK_CACHE, Kind: driver.PARAM_SIZE},
+	{Name: driver.PARAM_IN_MEMORY  , Kind: driver.PARAM_BOOL, Aliases: []string{"memory", "in-memory"}},
+	{Name: "journal_mode"          , Kind: driver.PARAM_ENUM, Enums: []string{"wal", "delete", "truncate", "persist", "memory", "off"}},
+	{Name: "busy_timeout"          , Kind: driver.PARAM_DURATION},
+	{Name: "gc_interval"           , Kind: driver.PARAM_DURATION},
+}
+
+func (d *myDriver)Params() []driver.Param {
+	return params
+}
+
+func (d *myDriver)Open(path string, params map[string][]string) (driver.DB, error) {
+	cfg := cfg{
+		Path       : path,
+		Sync       : driver.GetBool(params, driver.PARAM_SYNC),
+		ReadOnly   : driver.GetBool(params, driver.PARAM_READONLY),
+		BlockCache : driver.GetSize(params, driver.PARAM_BLOCK_CACHE, 0),
+		InMemory   : driver.GetBool(params, driver.PARAM_IN_MEMORY),
+		JournalMode: driver.GetStr (params, "journal_mode", "wal"),
+		BusyTimeout: driver.GetDuration(params, "busy_timeout", time.Second * 5),
+		GCInterval : driver.GetDuration(params, "gc_interval", time.Minute),
+	}
+
+	return newDB(&cfg)
+}
+
+func init() {
+	driver.Register(driverName, insDriver)
+}
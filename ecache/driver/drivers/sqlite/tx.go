@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// the max count of records loaded in one query when iterating
+const __iterBatch = 1000
+
+type TX struct {
+	txn *sql.Tx
+}
+
+func __genStoreKey(prefix []byte, setkey []byte)(storeKey []byte) {
+	out := make([]byte, 0, len(prefix) + len(setkey))
+	out = append(out, prefix...)
+	out = append(out, setkey...)
+	return out
+}
+
+// __prefixEnd returns the smallest key which is greater than all the keys have the prefix,
+// returns nil if there is no such key
+func __prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] += 1
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// __prefixCond returns the where condition(starts with " AND") and args to match the keys have the prefix
+func __prefixCond(prefix []byte) (string, []any) {
+	if len(prefix) == 0 {
+		return "", nil
+	}
+
+	if end := __prefixEnd(prefix); end != nil {
+		return " AND k >= ? AND k < ?", []any{prefix, end}
+	}
+	return " AND k >= ?", []any{prefix}
+}
+
+func __expiresAtSecs(expiresAt int64) uint64 {
+	return uint64(expiresAt) / uint64(time.Second)
+}
+
+func (tx *TX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error{
+	var expiresAt int64
+	if len(ttl) > 0 && ttl[0] > 0 {
+		expiresAt = time.Now().Add(ttl[0]).UnixNano()
+	}
+	if val == nil {
+		val = []byte{}
+	}
+
+	_, err := tx.txn.Exec(`INSERT INTO ecache(k, v, expires_at) VALUES(?, ?, ?) 
+		ON CONFLICT(k) DO UPDATE SET v = excluded.v, expires_at = excluded.expires_at`, __genStoreKey(prefix, key), val, expiresAt)
+	return err
+}
+
+func (tx *TX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error){
+	k := __genStoreKey(prefix, key)
+
+	var val []byte
+	var expiresAt int64
+	err := tx.txn.QueryRow("SELECT v, expires_at FROM ecache WHERE k = ? AND (expires_at = 0 OR expires_at > ?)", k, time.Now().UnixNano()).Scan(&val, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	if len(del) > 0 && del[0] {
+		if _, err = tx.txn.Exec("DELETE FROM ecache WHERE k = ?", k); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return val, __expiresAtSecs(expiresAt), nil
+}
+
+func (tx *TX)Del(prefix []byte, key []byte) (error){
+	_, err := tx.txn.Exec("DELETE FROM ecache WHERE k = ?", __genStoreKey(prefix, key))
+	return err
+}
+
+type __record struct {
+	k         []byte
+	v         []byte
+	expiresAt int64
+}
+
+// __loadBatch loads the records after the key after, or from the key from if after is nil
+func (tx *TX)__loadBatch(prefix []byte, from []byte, after []byte, now int64) ([]__record, error) {
+	where, args := __prefixCond(prefix)
+	if after != nil {
+		where += " AND k > ?"
+		args   = append(args, after)
+	} else if from != nil {
+		where += " AND k >= ?"
+		args   = append(args, from)
+	}
+	args = append(args, now, __iterBatch)
+
+	rows, err := tx.txn.Query("SELECT k, v, expires_at FROM ecache WHERE 1=1" + where + " AND (expires_at = 0 OR expires_at > ?) ORDER BY k LIMIT ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []__record
+	for rows.Next() {
+		var r __record
+		if err = rows.Scan(&r.k, &r.v, &r.expiresAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+
+	return out, rows.Err()
+}
+
+// Iterate loads records in batches, so the operations on tx in fn will not conflict with the reading rows
+func (tx *TX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiresAt uint64)error) (error){
+	return tx.IterateFrom(prefix, nil, fn)
+}
+
+func (tx *TX)IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiresAt uint64)error) (error){
+	idx := -1
+	prelen := len(prefix)
+	now := time.Now().UnixNano()
+
+	var from, after []byte
+	if len(start) > 0 {
+		from = append(append([]byte{}, prefix...), start...)
+	}
+	for {
+		rs, err := tx.__loadBatch(prefix, from, after, now)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rs {
+			idx += 1
+			if err = fn(idx, r.k[prelen:], r.v, __expiresAtSecs(r.expiresAt)); err != nil {
+				return err
+			}
+		}
+
+		if len(rs) < __iterBatch {
+			return nil
+		}
+		after = rs[len(rs)-1].k
+	}
+}
@@ -0,0 +1,26 @@
+package driver
+
+import (
+	"errors"
+)
+
+// the sentinel errors of ecache, the errors returned are wrapped with more details and stack traces,
+// check them by errors.Is
+var (
+	// ErrNotFound means the key or the named thing(cache, index, ...) can not be found,
+	// note: TX.Get returns (nil, 0, nil) rather than ErrNotFound for a missing key
+	ErrNotFound       = errors.New("not found")
+
+	// ErrClosed should be returned when operating on a closed db
+	ErrClosed         = errors.New("db closed")
+
+	// ErrInvalidDsn means the dsn or the params in it are invalid
+	ErrInvalidDsn     = errors.New("invalid dsn")
+
+	// ErrDriverNotFound means the driver set in dsn is not registered
+	ErrDriverNotFound = errors.New("driver not found")
+
+	// ErrTxConflict should be returned by Update when the transaction can not be committed because the data read in it
+	// are modified by others, the caller can retry the Update
+	ErrTxConflict     = errors.New("transaction conflict")
+)
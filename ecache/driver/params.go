@@ -0,0 +1,221 @@
+package driver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+  params are passed to drivers by the query part of dsn, like:
+    badger:./cache/c1?sync=true&mem_table_size=64MB&compression=zstd
+
+  the common params, a driver may not support all of them, an error will be returned from CheckDsn and OpenDsn
+  when passing a param not supported by the driver:
+
+    -- name --------   -- type --   -- desc --
+    sync               bool         sync writes to disk before return
+    readonly           bool         open the db in readonly mode
+    mem_table_size     size         the size of each memtable
+    block_cache        size         the size of block cache
+    compression        enum         the compression algorithm of stored data, like none, snappy, zstd
+    in_memory          bool         run the db in memory, nothing will be write to disk, 'memory' and 'in-memory' are aliases of it
+
+  the value format of types:
+    bool: true, false, True, False, 1, 0
+    int : 10, -1
+    size: 1024, 512KB, 64MB, 1GB, the unit is case insensitive and based on 1024
+    duration: 100ms, 10s, 1m, the format is the same as time.ParseDuration
+    enum: one of the valid values defined by the driver
+    string: any string, it should be escaped by url encoding if needed
+
+  the driver specific params are documented in each driver.
+*/
+
+type ParamKind int
+
+const (
+	PARAM_BOOL ParamKind = iota
+	PARAM_INT
+	PARAM_SIZE
+	PARAM_ENUM
+	PARAM_DURATION
+	PARAM_STRING
+)
+
+const (
+	PARAM_SYNC           = "sync"
+	PARAM_READONLY       = "readonly"
+	PARAM_MEM_TABLE_SIZE = "mem_table_size"
+	PARAM_BLOCK_CACHE    = "block_cache"
+	PARAM_COMPRESSION    = "compression"
+	PARAM_IN_MEMORY      = "in_memory"
+)
+
+// Param defines a param supported by a driver
+type Param struct {
+	Name    string
+	Kind    ParamKind
+	Enums   []string   // the valid values when Kind is PARAM_ENUM
+	Aliases []string   // the aliases of Name, they will be replaced by Name after checking
+	Desc    string
+}
+
+// ParamsDriver - a driver implements this will check the params in dsn before open,
+// the params not returned by Params() will be considered as invalid
+type ParamsDriver interface {
+	Params() []Param
+}
+
+func (k ParamKind) String() string {
+	switch k {
+	case PARAM_BOOL: return "bool"
+	case PARAM_INT : return "int"
+	case PARAM_SIZE: return "size"
+	case PARAM_ENUM: return "enum"
+	case PARAM_DURATION: return "duration"
+	case PARAM_STRING  : return "string"
+	}
+	return fmt.Sprintf("ParamKind(%d)", int(k))
+}
+
+func (p *Param) check(val string) error {
+	switch p.Kind {
+	case PARAM_BOOL: _, err := parseBool(val); return err
+	case PARAM_INT : _, err := strconv.ParseInt(val, 10, 64); return err
+	case PARAM_SIZE: _, err := ParseSize(val); return err
+	case PARAM_ENUM:
+		for _, e := range p.Enums {
+			if e == val {
+				return nil
+			}
+		}
+		return fmt.Errorf("valid values are %v", p.Enums)
+	case PARAM_DURATION: _, err := time.ParseDuration(val); return err
+	case PARAM_STRING  : return nil
+	}
+	return fmt.Errorf("unknown param kind %s", p.Kind)
+}
+
+// checkParams checks params by the Params() of the driver, and replace the aliases with the real name
+func checkParams(driverName string, driver Driver, params map[string][]string) error {
+	pd, ok := driver.(ParamsDriver)
+	if !ok || len(params) == 0 {
+		return nil
+	}
+
+	defs  := map[string]*Param{}
+	names := []string{}
+	for _, p := range pd.Params() {
+		p := p
+		defs[p.Name] = &p
+		names = append(names, p.Name)
+		for _, a := range p.Aliases {
+			defs[a] = &p
+		}
+	}
+	sort.Strings(names)
+
+	for key, vals := range params {
+		p := defs[key]
+		if p == nil {
+			return fmt.Errorf("unknown param '%s' for driver '%s', supported params are: %v", key, driverName, names)
+		}
+		if len(vals) != 1 {
+			return fmt.Errorf("param '%s' for driver '%s' should be set only once, got %d values", key, driverName, len(vals))
+		}
+		if err := p.check(vals[0]); err != nil {
+			return fmt.Errorf("invalid value '%s' of %s param '%s' for driver '%s': %s", vals[0], p.Kind, key, driverName, err)
+		}
+		if key != p.Name {
+			if _, ok := params[p.Name]; ok {
+				return fmt.Errorf("param '%s' for driver '%s' is conflict with its alias '%s'", p.Name, driverName, key)
+			}
+			params[p.Name] = vals
+			delete(params, key)
+		}
+	}
+
+	return nil
+}
+
+func parseBool(val string) (bool, error) {
+	switch val {
+	case "false", "False", "0": return false, nil
+	case "true" , "True" , "1": return true , nil
+	}
+	return false, fmt.Errorf("valid values are [true, false, True, False, 1, 0]")
+}
+
+// ParseSize parses size string like 1024, 512KB, 64MB, 1GB, the unit is case insensitive and based on 1024
+func ParseSize(val string) (int64, error) {
+	s    := strings.ToUpper(strings.TrimSpace(val))
+	mul  := int64(1)
+	for _, u := range []struct{ suffix string; mul int64 }{
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+		{"K" , 1 << 10}, {"M" , 1 << 20}, {"G" , 1 << 30}, {"T" , 1 << 40},
+		{"B" , 1},
+	}{
+		if strings.HasSuffix(s, u.suffix) {
+			s   = strings.TrimSpace(s[:len(s)-len(u.suffix)])
+			mul = u.mul
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s', the valid format is like 1024, 512KB, 64MB, 1GB", val)
+	}
+
+	return n * mul, nil
+}
+
+// GetInt returns the int value of key in params, returns df if not set or invalid
+func GetInt(params map[string][]string, key string, df int64) int64 {
+	vals := params[key]
+	if len(vals) > 0 {
+		if n, err := strconv.ParseInt(vals[0], 10, 64); err == nil {
+			return n
+		}
+	}
+	return df
+}
+
+// GetSize returns the size value of key in params, returns df if not set or invalid
+func GetSize(params map[string][]string, key string, df int64) int64 {
+	vals := params[key]
+	if len(vals) > 0 {
+		if n, err := ParseSize(vals[0]); err == nil {
+			return n
+		}
+	}
+	return df
+}
+
+// GetDuration returns the duration value of key in params, returns df if not set or invalid
+func GetDuration(params map[string][]string, key string, df time.Duration) time.Duration {
+	vals := params[key]
+	if len(vals) > 0 {
+		if d, err := time.ParseDuration(vals[0]); err == nil {
+			return d
+		}
+	}
+	return df
+}
+
+// GetStr returns the string value of key in params, returns df if not set
+func GetStr(params map[string][]string, key string, df string) string {
+	vals := params[key]
+	if len(vals) > 0 {
+		return vals[0]
+	}
+	return df
+}
+
+// Has returns true if key is set in params
+func Has(params map[string][]string, key string) bool {
+	return len(params[key]) > 0
+}
@@ -1,22 +1,18 @@
 package driver
 
 import (
-	"fmt"
 	"net/url"
 	"strings"
+
+	"github.com/ziyht/eden_go/eerr"
 )
 
+// GetBool returns the bool value of key in params, returns false if not set or invalid
 func GetBool(params map[string][]string, key string)bool{
 	vals := params[key]
 	if len(vals) > 0 {
-		switch vals[0]{
-		case "false": return false
-		case "False": return false
-		case "0"    : return false
-		case "true" : return true
-		case "True" : return true
-		case "1"    : return true
-		}
+		v, _ := parseBool(vals[0])
+		return v
 	}
 
 	return false
@@ -26,12 +22,12 @@ func parsingDsn(dsn string) (Driver, string, url.Values, error){
 	// get and find driver
 	idx := strings.Index(dsn, ":")
 	if idx < 0 {
-		return nil, "", nil, fmt.Errorf("invalid dsn(%s), the valid format is: %s", dsn, VALID_FORMAT)
+		return nil, "", nil, eerr.Errorf("%w(%s), the valid format is: %s", ErrInvalidDsn, dsn, VALID_FORMAT)
 	}
 	driverName := dsn[:idx]
 	driver := drivers[driverName]
 	if driver == nil {
-		return nil, "", nil, fmt.Errorf("driver named '%s' can not be found, now support drivers are: %s", driverName, driverNames)
+		return nil, "", nil, eerr.Errorf("%w: driver named '%s' can not be found, now support drivers are: %s", ErrDriverNotFound, driverName, driverNames)
 	}
 
 	// parsing path
@@ -40,7 +36,7 @@ func parsingDsn(dsn string) (Driver, string, url.Values, error){
 	{
 		idx := strings.Index(pathStr, "?")
 		if idx == 0 {
-			return nil, "", nil, fmt.Errorf("can not find path in dsn(%s), the valid format is: %s", dsn, VALID_FORMAT)
+			return nil, "", nil, eerr.Errorf("%w: can not find path in dsn(%s), the valid format is: %s", ErrInvalidDsn, dsn, VALID_FORMAT)
 		} else if idx > 0{
 			paramsStr = pathStr[idx+1:]
 			pathStr   = pathStr[:idx]
@@ -53,9 +49,13 @@ func parsingDsn(dsn string) (Driver, string, url.Values, error){
 	if paramsStr != "" {
 		params, err = url.ParseQuery(paramsStr)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("parse params failed: %s, input dsn is '%s'", err, dsn)
+			return nil, "", nil, eerr.Errorf("%w: parse params failed: %s, input dsn is '%s'", ErrInvalidDsn, err, dsn)
 		}
 	}
 
+	if err = checkParams(driverName, driver, params); err != nil {
+		return nil, "", nil, eerr.Errorf("%w: %s, input dsn is '%s'", ErrInvalidDsn, err, dsn)
+	}
+
 	return driver, pathStr, params, nil
 }
\ No newline at end of file
@@ -5,19 +5,32 @@ import (
 	"strings"
 
 	_ "github.com/ziyht/eden_go/ecache/driver/drivers/badgerdb"
+	_ "github.com/ziyht/eden_go/ecache/driver/drivers/bbolt"
 	_ "github.com/ziyht/eden_go/ecache/driver/drivers/nutsdb"
+	_ "github.com/ziyht/eden_go/ecache/driver/drivers/redis"
+	_ "github.com/ziyht/eden_go/ecache/driver/drivers/sqlite"
 )
 
 const (
 	BADGER = "badger"
 	NUTSDB = "nutsdb"
+	BBOLT  = "bbolt"     // single file db, the dir in dsn is the path of db file
+	SQLITE = "sqlite"    // single file db, the dir in dsn is the path of db file
+	REDIS  = "redis"     // remote redis server, the dir in dsn is the address of server, like redis:127.0.0.1:6379
 )
 
+// DBCacheOpts - the opts to create a DBCache
+//
+// the params of driver can be set in Params or in the query part of Dsn, like:
+//   badger:./cache/c1?sync=true&mem_table_size=64MB&compression=zstd
+// the common params are sync, readonly, mem_table_size, block_cache, compression and in_memory,
+// see package driver and each driver for the details, an unknown or invalid param will fail the creation
 type DBCacheOpts struct {
 	Dsn     string   // if set, the other proporty will take no effect, format: <dirvername>:<dir>[?<arg1=val1>[&<arg2=val2>]...]
 	Driver  string   // if not set will using nutsdb in default
 	Dir     string   
   Params  map[string][]string
+	Hooks   []Hook   // called after every driver call, see SlowOpLogger and LatencyHistogram for the ready-made ones
 }
 
 func NewDBCache(opts DBCacheOpts) (c *DBCache, err error) {
@@ -77,18 +90,41 @@ func NewTypedItemRegion[T Item](r* Region, keys ...string)(*ItemRegion[T]){
 	return newItemRegion[T](r.db, r.meta.keys)
 }
 
-// InitFromConfigFile will init dbcache from a config file, support multi file types like yaml, yml, json, toml...
+// NewLocker creates a Locker whose lock records are stored in r, see Locker for the details
+func NewLocker(r *Region)(*Locker){
+	return newLocker(r)
+}
+
+// InitFromConfigFile will init dbcache and memcache from a config file, support multi file types like yaml, yml, json, toml...
 // 
 // the format should like follows:
 // ecache:
 //   c1:
 //     dsn: badger:./cache/ecache/c1
+// ecache_mem:
+//   m1:
+//     max_cost    : 1048576
+//     df_ttl      : 10m
+//     max_ttl     : 1h
+//     auto_rerent : true
+//     counters_num: 10240
 //
-// after init, you can get dbcache by call GetDBCache("c1")
+// after init, you can get dbcache by call GetDBCache("c1") and memcache by call GetMemCache[K, V]("m1")
 func InitFromConfigFile(path string)error{
 	return initFromFile(path)
 }
 
+// WatchConfigFile will init dbcaches and memcaches from a config file like InitFromConfigFile, and keep watching the file after that,
+// when the file changes:
+//   1. the caches newly declared will be opened
+//   2. the caches removed will be closed after the in-flight operations on them finished
+//   3. the caches with changed DSN will be reopened in place
+//   4. the memcaches with changed cfg will be updated in place, except counters_num
+// the handles returned by GetDBCache and GetMemCache before will keep working, except the closed ones
+func WatchConfigFile(path string)(*CfgWatcher, error){
+	return watchConfigFile(path)
+}
+
 func GetDBCacheFromFile(path string, key string)(c *DBCache, err error){
 	cfg, err := cfgsFromFileKey(path, key)
 	if err != nil {
@@ -105,3 +141,9 @@ func GetDBCache(name ...string)(c *DBCache, err error){
 
 	return getDBCache(name[0])
 }
+
+// GetMemCache returns the memcache declared in config file by name, the cache will be created in the first call,
+// it returns an error if the name not declared or the cache has been created with other types of K and V
+func GetMemCache[K Key, V any](name string)(c *MemCache[K, V], err error){
+	return getMemCache[K, V](name)
+}
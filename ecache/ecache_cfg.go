@@ -3,6 +3,7 @@ package ecache
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/ziyht/eden_go/ecfg"
 )
@@ -19,6 +20,20 @@ type Cfgs struct {
 	Cfgs map[string]*Cfg
 }
 
+// MemCfg - the config of a named MemCache, see MemCacheOpts for the meaning of each field
+type MemCfg struct {
+	MaxCost     int64         `mapstructure:"max_cost"`
+	DfTTL       time.Duration `mapstructure:"df_ttl"`
+	MaxTTL      time.Duration `mapstructure:"max_ttl"`
+	AutoReRent  bool          `mapstructure:"auto_rerent"`
+	CountersNum int64         `mapstructure:"counters_num"`
+	cfgFile     string
+}
+
+type MemCfgs struct {
+	Cfgs map[string]*MemCfg
+}
+
 func dfCfg() *Cfg {
 	return &Cfg{Dsn: "nutsdb:./cache/ecache/df/nutsdb"}
 }
@@ -58,4 +73,40 @@ func cfgsFromFileKey(path string, key string)(Cfg, error){
 	}
 	
 	return out, nil
+}
+
+func memCfgsFromFile(path string, key string)(MemCfgs, error){
+	var out MemCfgs
+
+	out.Cfgs = map[string]*MemCfg{}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return out, err
+	}
+
+	err = ecfg.ParsingFromCfgFile(path, key, &out.Cfgs)
+	if err != nil {
+		return out, fmt.Errorf("failed to parse %s from path %s: %s", key, path, err)
+	}
+
+	for _, cfg := range out.Cfgs{
+		cfg.cfgFile = path
+	}
+
+	return out, nil
+}
+
+func memCacheOptsFromCfg[V any](cfg *MemCfg) MemCacheOpts[V] {
+	return MemCacheOpts[V]{
+		MaxCost    : cfg.MaxCost,
+		DfTTL      : cfg.DfTTL,
+		MaxTTL     : cfg.MaxTTL,
+		AutoReRent : cfg.AutoReRent,
+		CountersNum: cfg.CountersNum,
+	}
+}
+
+func (c *MemCfg)equal(o *MemCfg) bool {
+	return c.MaxCost == o.MaxCost && c.DfTTL == o.DfTTL && c.MaxTTL == o.MaxTTL && c.AutoReRent == o.AutoReRent && c.CountersNum == o.CountersNum
 }
\ No newline at end of file
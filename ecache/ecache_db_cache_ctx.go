@@ -0,0 +1,250 @@
+package ecache
+
+import (
+	"context"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+// ctxDB checks the ctx before each transaction and each operation in it,
+// so a cancelled operation stops between the iterated keys and the batches,
+// the error returned is always ctx.Err() once the ctx is done, whatever the driver returns
+type ctxDB struct {
+	driver.DB
+	ctx context.Context
+}
+
+type ctxTX struct {
+	driver.TX
+	ctx context.Context
+}
+
+// __ctxErr returns ctx.Err() instead of err if the ctx is done, the drivers may return their own errors
+// when the operations are interrupted
+func __ctxErr(ctx context.Context, err error) error {
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
+		}
+	}
+	return err
+}
+
+func (d *ctxDB)Update(fn func(tx driver.TX)error) error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+
+	return __ctxErr(d.ctx, d.DB.Update(func(tx driver.TX) error {
+		return fn(&ctxTX{TX: tx, ctx: d.ctx})
+	}))
+}
+
+func (d *ctxDB)View(fn func(tx driver.TX)error) error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+
+	return __ctxErr(d.ctx, d.DB.View(func(tx driver.TX) error {
+		return fn(&ctxTX{TX: tx, ctx: d.ctx})
+	}))
+}
+
+func (d *ctxDB)DropPrefix(prefix []byte) error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+	return __ctxErr(d.ctx, d.DB.DropPrefix(prefix))
+}
+
+func (d *ctxDB)Truncate() error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+	return __ctxErr(d.ctx, d.DB.Truncate())
+}
+
+func (tx *ctxTX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error {
+	if err := tx.ctx.Err(); err != nil {
+		return err
+	}
+	return tx.TX.Set(prefix, key, val, ttl...)
+}
+
+func (tx *ctxTX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error) {
+	if err := tx.ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	return tx.TX.Get(prefix, key, del...)
+}
+
+func (tx *ctxTX)Del(prefix []byte, key []byte) error {
+	if err := tx.ctx.Err(); err != nil {
+		return err
+	}
+	return tx.TX.Del(prefix, key)
+}
+
+func (tx *ctxTX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64) error) error {
+	if err := tx.ctx.Err(); err != nil {
+		return err
+	}
+	return tx.TX.Iterate(prefix, func(idx int, key []byte, val []byte, expiredAt uint64) error {
+		if err := tx.ctx.Err(); err != nil {
+			return err
+		}
+		return fn(idx, key, val, expiredAt)
+	})
+}
+
+func (tx *ctxTX)IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64) error) error {
+	if err := tx.ctx.Err(); err != nil {
+		return err
+	}
+	return driver.IterateFrom(tx.TX, prefix, start, func(idx int, key []byte, val []byte, expiredAt uint64) error {
+		if err := tx.ctx.Err(); err != nil {
+			return err
+		}
+		return fn(idx, key, val, expiredAt)
+	})
+}
+
+// withCtx returns a db whose operations are bound to ctx, it returns db itself if ctx can never be cancelled
+func (db_ *db)withCtx(ctx context.Context) *db {
+	if ctx == nil || ctx.Done() == nil {
+		return db_
+	}
+
+	return &db{dsn: db_.dsn, db: &ctxDB{DB: db_.db, ctx: ctx}, live: db_.live, quotas: db_.quotas}
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// Region
+
+func (r *Region)__withCtx(ctx context.Context) *Region {
+	return &Region{db: r.db, meta: r.meta, ttl: r.ttl, ctx: ctx}
+}
+
+// SetCtx is like Set, it returns ctx.Err() if ctx is done before the writing
+func (r *Region)SetCtx(ctx context.Context, key any, val any, ttl ...time.Duration) error {
+	return r.__withCtx(ctx).Set(key, val, ttl...)
+}
+
+// SetsCtx is like Sets, the ctx is checked before each key and between the batches,
+// the batches committed before the cancellation are kept
+func (r *Region)SetsCtx(ctx context.Context, keys any, vals any, ttls ...time.Duration) error {
+	return r.__withCtx(ctx).Sets(keys, vals, ttls...)
+}
+
+// SetObjsCtx is like SetObjs, the ctx is checked before each obj and between the batches
+func (r *Region)SetObjsCtx(ctx context.Context, items []any, fn func(int, any)(k []byte, v any, ttl time.Duration))error{
+	return r.__withCtx(ctx).SetObjs(items, fn)
+}
+
+// GetCtx is like Get, it returns ctx.Err() if ctx is done before the reading
+func (r *Region)GetCtx(ctx context.Context, key any, del ...bool)(Val, error){
+	return r.__withCtx(ctx).Get(key, del...)
+}
+
+// GetExCtx is like GetEx, it returns ctx.Err() if ctx is done before the reading
+func (r *Region)GetExCtx(ctx context.Context, key any, del ...bool)(Val, uint64, error){
+	return r.__withCtx(ctx).GetEx(key, del...)
+}
+
+// GetsCtx is like Gets, the ctx is checked before each key
+func (r *Region)GetsCtx(ctx context.Context, keys any, del ...bool)([]Val, error){
+	return r.__withCtx(ctx).Gets(keys, del...)
+}
+
+// GetAllCtx is like GetAll, the ctx is checked between the iterated keys
+func (r *Region)GetAllCtx(ctx context.Context)([][]byte, []Val, error){
+	return r.__withCtx(ctx).GetAll()
+}
+
+// UpdateCtx is like Update, nothing will be changed if ctx is done before committing
+func (r *Region)UpdateCtx(ctx context.Context, key any, fn func(old Val)(val any, ttl time.Duration, err error)) error {
+	return r.__withCtx(ctx).Update(key, fn)
+}
+
+// DelCtx is like Del, it returns ctx.Err() if ctx is done before the deleting
+func (r *Region)DelCtx(ctx context.Context, key any)(error){
+	return r.__withCtx(ctx).Del(key)
+}
+
+// DelsCtx is like Dels, the ctx is checked before each key
+func (r *Region)DelsCtx(ctx context.Context, keys ...any)(error){
+	return r.__withCtx(ctx).Dels(keys...)
+}
+
+// DoForAllCtx is like DoForAll, the ctx is checked between the iterated keys
+func (r *Region)DoForAllCtx(ctx context.Context, fn func(idx int, key []byte, val Val) error)error{
+	return r.__withCtx(ctx).DoForAll(fn)
+}
+
+// DoForKeysCtx is like DoForKeys, the ctx is checked before each key
+func (r *Region)DoForKeysCtx(ctx context.Context, keys any, fn func(idx int, key []byte, val Val) error)error{
+	return r.__withCtx(ctx).DoForKeys(keys, fn)
+}
+
+// TruncateCtx is like Truncate, it returns ctx.Err() if ctx is done before the truncating
+func (r *Region)TruncateCtx(ctx context.Context) error {
+	return r.__withCtx(ctx).Truncate()
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// ItemRegion
+
+func (r *ItemRegion[T])__withCtx(ctx context.Context) *ItemRegion[T] {
+	c := *r
+	c.db = r.db.withCtx(ctx)
+	return &c
+}
+
+// SetCtx is like Set, it returns ctx.Err() if ctx is done before the writing
+func (r *ItemRegion[T])SetCtx(ctx context.Context, key any, item T, ttl ...time.Duration) error {
+	return r.__withCtx(ctx).Set(key, item, ttl...)
+}
+
+// GetCtx is like Get, the mem cache is still checked first if enabled
+func (r *ItemRegion[T])GetCtx(ctx context.Context, key any, new func() T, del...bool)(out T, err error) {
+	return r.__withCtx(ctx).Get(key, new, del...)
+}
+
+// GetsCtx is like Gets, the ctx is checked before each key
+func (r *ItemRegion[T])GetsCtx(ctx context.Context, keys [][]byte, new func() T, skipErrs_Del_RetainNil...bool)(items []T, err error){
+	return r.__withCtx(ctx).Gets(keys, new, skipErrs_Del_RetainNil...)
+}
+
+// GetAllCtx is like GetAll, the ctx is checked between the iterated keys
+func (r *ItemRegion[T])GetAllCtx(ctx context.Context, new func() T, skipErrs... bool) ([]T, error) {
+	return r.__withCtx(ctx).GetAll(new, skipErrs...)
+}
+
+// DelCtx is like Del, it returns ctx.Err() if ctx is done before the deleting
+func (r *ItemRegion[T])DelCtx(ctx context.Context, key []byte) error {
+	return r.__withCtx(ctx).Del(key)
+}
+
+// ReloadItemsCtx is like ReloadItems, the ctx is checked between the iterated keys
+func (r *ItemRegion[T])ReloadItemsCtx(ctx context.Context, new func() T)(int, error) {
+	return r.__withCtx(ctx).ReloadItems(new)
+}
+
+// FindByCtx is like FindBy, the ctx is checked between the iterated index entries
+func (r *ItemRegion[T])FindByCtx(ctx context.Context, name string, value []byte, new func() T) (items []T, err error) {
+	return r.__withCtx(ctx).FindBy(name, value, new)
+}
+
+// RangeByCtx is like RangeBy, the ctx is checked between the iterated index entries
+func (r *ItemRegion[T])RangeByCtx(ctx context.Context, name string, from, to []byte, new func() T) (items []T, err error) {
+	return r.__withCtx(ctx).RangeBy(name, from, to, new)
+}
+
+// ---------------------------------------------------------------------------------------------------------------------
+// DBCache
+
+// TruncateCtx is like Truncate, it returns ctx.Err() if ctx is done before the truncating
+func (c *DBCache)TruncateCtx(ctx context.Context) error {
+	return c.db.withCtx(ctx).truncate()
+}
@@ -5,12 +5,15 @@ import (
 	"time"
 
 	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/eerr"
 	"github.com/ziyht/eden_go/eutils/ptr"
 )
 
 type db struct {
-  dsn string
-	db  driver.DB
+  dsn    string
+	db     driver.DB
+	live   *liveDB
+	quotas *quotas     // the quotas of regions, shared by the dbs derived from this
 }
 
 func newDB(opts *DBCacheOpts) (*db, error) {
@@ -27,7 +30,34 @@ func newDB(opts *DBCacheOpts) (*db, error) {
 		return nil, fmt.Errorf("invalid returned db(nil) checked from current driver in dsn(%s)", opts.Dsn)
 	}
 
-	return &db{dsn: opts.Dsn, db: db_}, nil
+	live := newLiveDB(db_)
+	return &db{dsn: opts.Dsn, db: newHookDB(live, opts.Hooks), live: live, quotas: &quotas{}}, nil
+}
+
+// reopen replaces the underlying driver db with a new one opened by dsn,
+// the handles built on current db will keep working on the new one
+func (db *db)reopen(dsn string) error {
+	if dsn == db.dsn {
+		return nil
+	}
+
+	open := func(dsn string) func() (driver.DB, error) {
+		return func() (driver.DB, error) {
+			db_, err := driver.OpenDsn(dsn)
+			if err == nil && db_ == nil {
+				err = fmt.Errorf("invalid returned db(nil) checked from current driver in dsn(%s)", dsn)
+			}
+			return db_, err
+		}
+	}
+
+	defer db.__resetQuotas()
+	if err := db.live.swap(open(dsn), open(db.dsn)); err != nil {
+		return err
+	}
+
+	db.dsn = dsn
+	return nil
 }
 
 func (db *db)setVal(pre []byte, key []byte, val Val, ttl ...time.Duration)error{
@@ -111,22 +141,30 @@ func (db *db)sets(prefix []byte, keys [][]byte, vals [][]byte, ttls ... time.Dur
 func (db *db)setsAny(prefix []byte, keys any, vals any, ttls ... time.Duration) error {
 	ks, err := toBytesArr(keys)
 	if err != nil {
-		return fmt.Errorf("invalid keys input: %s", err)
+		return fmt.Errorf("invalid keys input: %w", err)
 	}
 	vs, err := toBytesArr(vals)
 	if err != nil {
-		return fmt.Errorf("invalid keys input: %s", err)
+		return fmt.Errorf("invalid vals input: %w", err)
 	}
 	return db.sets(prefix, ks, vs, ttls...)
 }
 
+// __read runs fn in a read transaction, or in Update if the keys will be deleted by Get
+func (db *db)__read(del []bool, fn func(tx driver.TX) error) error {
+	if len(del) > 0 && del[0] {
+		return db.db.Update(fn)
+	}
+	return db.db.View(fn)
+}
+
 func (db *db)getAny(prefix []byte, key any, del ...bool)(val Val, err error) {
 	k, err := toBytesKey(key)
 	if err != nil {
 		return
 	}
 
-	err = db.db.View(func(tx driver.TX)error{
+	err = db.__read(del, func(tx driver.TX)error{
 		bin, _, err := tx.Get(prefix, k, del...)
 		val.unmarshal(bin)
 		return err
@@ -141,7 +179,7 @@ func (db *db)getAnyEx(prefix []byte, key any, del ...bool)(val Val, expiresAt ui
 		return
 	}
 
-	err = db.db.View(func(tx driver.TX)error{
+	err = db.__read(del, func(tx driver.TX)error{
 		val.d, expiresAt, err = tx.Get(prefix, k, del...)
 		val.unmarshal(val.d)
 		return err
@@ -151,7 +189,7 @@ func (db *db)getAnyEx(prefix []byte, key any, del ...bool)(val Val, expiresAt ui
 }
 
 func (db *db)getBytesExt(prefix []byte, key []byte, del ...bool)(val []byte, expiresAt uint64, err error) {
-	db.db.View(func(tx driver.TX)error{
+	err = db.__read(del, func(tx driver.TX)error{
 		val, expiresAt, err = tx.Get(prefix, key, del...)
 		return err
 	})
@@ -160,7 +198,7 @@ func (db *db)getBytesExt(prefix []byte, key []byte, del ...bool)(val []byte, exp
 }
 
 func (db *db)gets(prefix []byte, keys [][]byte, del ...bool)(vals []Val, err error) {
-	err = db.db.View(func(tx driver.TX)error{
+	err = db.__read(del, func(tx driver.TX)error{
 		var val Val
 		for _, key := range keys {
 			val.d, _, err = tx.Get(prefix, key, del...)
@@ -178,14 +216,14 @@ func (db *db)gets(prefix []byte, keys [][]byte, del ...bool)(vals []Val, err err
 }
 
 func (db *db)getsAny(prefix []byte, keys any, del ...bool)(vals []Val, err error) {
-	err = db.db.View(func(tx driver.TX)error{
+	err = db.__read(del, func(tx driver.TX)error{
 		var val Val
 		switch k := keys.(type) {
 			case string  : bin, _, err := tx.Get(prefix, ptr.StringToBytes(k), del...); if err != nil { return err }; val.unmarshal(bin); vals = append(vals, val)
 			case []byte  : bin, _, err := tx.Get(prefix,                   k , del...); if err != nil { return err }; val.unmarshal(bin); vals = append(vals, val)
 			case []string: for _, tk := range k { bin, _, err := tx.Get(prefix, ptr.StringToBytes(tk), del...); if err != nil { return err }; val.unmarshal(bin); vals = append(vals, val)  }
 			case [][]byte: for _, tk := range k { bin, _, err := tx.Get(prefix,                   tk , del...); if err != nil { return err }; val.unmarshal(bin); vals = append(vals, val)  }
-			default      : return fmt.Errorf("invalid key type, only support: string, []string, []byte and [][]byte")
+			default      : return eerr.Pack(&TypeMismatchError{Want: "string, []string, []byte or [][]byte", Got: fmt.Sprintf("%T", k)})
 		}
 
 		return nil
@@ -209,6 +247,45 @@ func (db *db)getAll(prefix []byte, restore... bool)(keys [][]byte, vals []Val, e
 	return
 }
 
+// updateAny loads the val of key and stores the one returned by fn in one transaction
+func (db *db)updateAny(prefix []byte, key any, fn func(old Val)(val any, ttl time.Duration, err error)) error {
+	k, err := toBytesKey(key)
+	if err != nil {
+		return err
+	}
+
+	return db.db.Update(func(tx driver.TX)error{
+		bin, _, err := tx.Get(prefix, k)
+		if err != nil {
+			return err
+		}
+
+		var old Val
+		if bin != nil {
+			old.unmarshal(bin)
+		}
+
+		val, ttl, err := fn(old)
+		if err != nil {
+			return err
+		}
+		if val == nil {
+			if bin == nil {
+				return nil
+			}
+			return tx.Del(prefix, k)
+		}
+
+		raw, err := NewVal(val)
+		if err != nil {
+			return err
+		}
+		defer recycleVal(raw)
+
+		return tx.Set(prefix, k, raw.marshal(), ttl)
+	})
+}
+
 func (db *db)del(prefix []byte, key []byte)(err error) {
 	return db.db.Update(func(tx driver.TX)error{
 		return tx.Del(prefix, key)
@@ -244,7 +321,7 @@ func (db *db)delsAny(prefix []byte, keys ...any) error {
 				case []byte  : if err := tx.Del(prefix,        k ); err != nil { return err }
 				case []string: for _, tk := range k { if err := tx.Del(prefix, []byte(tk)); err != nil { return err }  }
 				case [][]byte: for _, tk := range k { if err := tx.Del(prefix,        tk ); err != nil { return err }  }
-				default: return fmt.Errorf("invalid key type(%t) at idx(:%d), only support: string, []string, []byte and [][]byte", k, idx)
+				default: return eerr.Errorf("invalid key at idx(%d): %w", idx, &TypeMismatchError{Want: "string, []string, []byte or [][]byte", Got: fmt.Sprintf("%T", k)})
 			}
 		}
 		return nil
@@ -322,7 +399,7 @@ func (db *db)doForKeys(prefix []byte, keys [][]byte, fn func(idx int, key []byte
 func (db *db)doForKeysAny(prefix []byte, keys any, fn func(idx int, key []byte, val Val) error) (err error) {
 	ks, err := toBytesArr(keys)
 	if err != nil {
-		return fmt.Errorf("invalid type(%t) of keys: %s", keys, err)
+		return fmt.Errorf("invalid type(%T) of keys: %w", keys, err)
 	}
 
 	var val Val
@@ -347,9 +424,15 @@ func (db *db)close() error {
 }
 
 func (db *db)truncate() error {
+	defer db.__resetQuotas()
 	return db.db.Truncate()
 }
 
+// __resetQuotas clears the cached quotas, they will be reloaded from the info records
+func (db *db)__resetQuotas() {
+	db.quotas.m.Range(func(k, _ any) bool { db.quotas.m.Delete(k); return true })
+}
+
 
 
 
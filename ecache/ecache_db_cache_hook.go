@@ -0,0 +1,320 @@
+package ecache
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/elog"
+)
+
+type Op string
+
+const (
+	OP_SET      Op = "set"
+	OP_GET      Op = "get"
+	OP_DEL      Op = "del"
+	OP_ITERATE  Op = "iterate"
+	OP_UPDATE   Op = "update"     // a read-write transaction, the keys and bytes are the sums of the calls in it
+	OP_VIEW     Op = "view"       // a read-only transaction, the keys and bytes are the sums of the calls in it
+	OP_DROP     Op = "drop"       // drop the records of a region, by Region.Truncate
+	OP_TRUNCATE Op = "truncate"   // truncate the whole db, by DBCache.Truncate
+)
+
+// OpEvent describes a finished driver call, it is only valid in the hook
+type OpEvent struct {
+	Region   string          // the region operated, like [key1,key2].[skey1], [] is the default region, empty for the whole db and the transactions
+	Op       Op
+	Keys     int             // the count of keys read, written or deleted
+	Bytes    int             // the bytes of keys and values read or written
+	Duration time.Duration
+	Err      error
+}
+
+// Hook will be called after every driver call(DB.Update, DB.View, TX.Set, TX.Get, TX.Del, TX.Iterate, TX.IterateFrom,
+// DB.DropPrefix and DB.Truncate) of a DBCache, the calls of TX are emitted before the transaction they are in, it is called synchronously, so it should be fast and never block
+type Hook func(e *OpEvent)
+
+// hookDB calls the hooks after each call of the wrapped driver.DB and its TXs
+type hookDB struct {
+	driver.DB
+	hooks []Hook
+	names sync.Map   // string(prefix) -> region name
+}
+
+type hookTX struct {
+	driver.TX
+	db    *hookDB
+	keys  int
+	bytes int
+}
+
+func newHookDB(db driver.DB, hooks []Hook) driver.DB {
+	if len(hooks) == 0 {
+		return db
+	}
+	return &hookDB{DB: db, hooks: hooks}
+}
+
+func (d *hookDB)__emit(prefix []byte, op Op, keys int, bytes int, start time.Time, err error) {
+	e := OpEvent{Op: op, Keys: keys, Bytes: bytes, Duration: time.Since(start), Err: err}
+	if prefix != nil {
+		e.Region = d.__regionName(prefix)
+	}
+	for _, hook := range d.hooks {
+		hook(&e)
+	}
+}
+
+// __regionName parses the region name from the prefix, see region meta for the format of prefixes
+func (d *hookDB)__regionName(prefix []byte) string {
+	if name, ok := d.names.Load(string(prefix)); ok {
+		return name.(string)
+	}
+
+	name := ""
+	if len(prefix) > 1 {
+		ks := prefix[1:]
+		if idx := bytes.IndexAny(ks, "\x06\x07"); idx >= 0 {
+			ks = ks[:idx]
+		}
+		var sb strings.Builder
+		sb.WriteByte('[')
+		for _, c := range ks {
+			switch c {
+				case __k_gap[0]   : sb.WriteByte(',')
+				case __sk_gap[0]  : sb.WriteString("].[")
+				default           : sb.WriteByte(c)
+			}
+		}
+		sb.WriteByte(']')
+		name = sb.String()
+	}
+
+	d.names.Store(string(prefix), name)
+	return name
+}
+
+func (d *hookDB)Update(fn func(tx driver.TX)error) (err error) {
+	start := time.Now()
+	htx := &hookTX{db: d}
+	err = d.DB.Update(func(tx driver.TX) error {
+		htx.TX = tx
+		return fn(htx)
+	})
+	d.__emit(nil, OP_UPDATE, htx.keys, htx.bytes, start, err)
+	return
+}
+
+func (d *hookDB)View(fn func(tx driver.TX)error) (err error) {
+	start := time.Now()
+	htx := &hookTX{db: d}
+	err = d.DB.View(func(tx driver.TX) error {
+		htx.TX = tx
+		return fn(htx)
+	})
+	d.__emit(nil, OP_VIEW, htx.keys, htx.bytes, start, err)
+	return
+}
+
+func (d *hookDB)DropPrefix(prefix []byte) (err error) {
+	start := time.Now()
+	err = d.DB.DropPrefix(prefix)
+	d.__emit(prefix, OP_DROP, 0, 0, start, err)
+	return
+}
+
+func (d *hookDB)Truncate() (err error) {
+	start := time.Now()
+	err = d.DB.Truncate()
+	d.__emit(nil, OP_TRUNCATE, 0, 0, start, err)
+	return
+}
+
+// __emit emits the call and sums it to the transaction
+func (tx *hookTX)__emit(prefix []byte, op Op, keys int, bytes int, start time.Time, err error) {
+	tx.keys  += keys
+	tx.bytes += bytes
+	tx.db.__emit(prefix, op, keys, bytes, start, err)
+}
+
+func (tx *hookTX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) (err error) {
+	start := time.Now()
+	err = tx.TX.Set(prefix, key, val, ttl...)
+	tx.__emit(prefix, OP_SET, 1, len(key) + len(val), start, err)
+	return
+}
+
+func (tx *hookTX)Get(prefix []byte, key []byte, del ...bool) (val []byte, expiresAt uint64, err error) {
+	start := time.Now()
+	val, expiresAt, err = tx.TX.Get(prefix, key, del...)
+	op := OP_GET
+	if len(del) > 0 && del[0] {
+		op = OP_DEL
+	}
+	tx.__emit(prefix, op, 1, len(key) + len(val), start, err)
+	return
+}
+
+func (tx *hookTX)Del(prefix []byte, key []byte) (err error) {
+	start := time.Now()
+	err = tx.TX.Del(prefix, key)
+	tx.__emit(prefix, OP_DEL, 1, len(key), start, err)
+	return
+}
+
+func (tx *hookTX)Iterate(prefix []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64) error) (err error) {
+	start := time.Now()
+	keys, bytes := 0, 0
+	err = tx.TX.Iterate(prefix, func(idx int, key []byte, val []byte, expiredAt uint64) error {
+		keys  += 1
+		bytes += len(key) + len(val)
+		return fn(idx, key, val, expiredAt)
+	})
+	tx.__emit(prefix, OP_ITERATE, keys, bytes, start, err)
+	return
+}
+
+// IterateFrom seeks by the wrapped TX if it supports, so the hooks do not slow down FindBy and RangeBy of ItemRegion
+func (tx *hookTX)IterateFrom(prefix []byte, start []byte, fn func(idx int, key []byte, val []byte, expiredAt uint64) error) (err error) {
+	begin := time.Now()
+	keys, bytes := 0, 0
+	err = driver.IterateFrom(tx.TX, prefix, start, func(idx int, key []byte, val []byte, expiredAt uint64) error {
+		keys  += 1
+		bytes += len(key) + len(val)
+		return fn(idx, key, val, expiredAt)
+	})
+	tx.__emit(prefix, OP_ITERATE, keys, bytes, begin, err)
+	return
+}
+
+// SlowOpLogger returns a Hook which logs the operations cost more than threshold by logger, the default logger of ecache
+// will be used if logger is not set
+func SlowOpLogger(threshold time.Duration, logger ...elog.Elog) Hook {
+	l := log
+	if len(logger) > 0 && logger[0] != nil {
+		l = logger[0]
+	}
+
+	return func(e *OpEvent) {
+		if e.Duration < threshold {
+			return
+		}
+		l.Warnf("slow op: region=%s op=%s keys=%d bytes=%d cost=%s err=%v", e.Region, e.Op, e.Keys, e.Bytes, e.Duration, e.Err)
+	}
+}
+
+// LatencyHistogram records the latencies of operations in buckets for each region and op,
+// use LatencyHistogram.Hook as the Hook of DBCache
+type LatencyHistogram struct {
+	bounds []time.Duration
+	mu     sync.RWMutex
+	stats  map[latencyKey]*latencyStat
+}
+
+type latencyKey struct {
+	region string
+	op     Op
+}
+
+type latencyStat struct {
+	count   atomic.Uint64
+	errs    atomic.Uint64
+	sum     atomic.Int64
+	buckets []atomic.Uint64   // len(bounds) + 1, the last one is for the latencies > the last bound
+}
+
+// LatencyStat is a snapshot of the latencies of an op on a region
+type LatencyStat struct {
+	Region  string
+	Op      Op
+	Count   uint64
+	Errs    uint64
+	Sum     time.Duration
+	Bounds  []time.Duration   // the upper bounds of buckets
+	Buckets []uint64          // the counts of latencies <= Bounds[i], the last one is for the latencies > the last bound
+}
+
+var DfLatencyBounds = []time.Duration{
+	time.Microsecond * 100, time.Microsecond * 500, time.Millisecond, time.Millisecond * 5, time.Millisecond * 10,
+	time.Millisecond * 50, time.Millisecond * 100, time.Millisecond * 500, time.Second,
+}
+
+// NewLatencyHistogram creates a LatencyHistogram with the upper bounds of buckets, DfLatencyBounds will be used if not set
+func NewLatencyHistogram(bounds ...time.Duration) *LatencyHistogram {
+	if len(bounds) == 0 {
+		bounds = DfLatencyBounds
+	}
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	return &LatencyHistogram{bounds: bounds, stats: map[latencyKey]*latencyStat{}}
+}
+
+func (h *LatencyHistogram)__stat(k latencyKey) *latencyStat {
+	h.mu.RLock()
+	s := h.stats[k]
+	h.mu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s = h.stats[k]; s == nil {
+		s = &latencyStat{buckets: make([]atomic.Uint64, len(h.bounds) + 1)}
+		h.stats[k] = s
+	}
+	return s
+}
+
+// Hook returns the Hook to record the latencies
+func (h *LatencyHistogram)Hook() Hook {
+	return h.Record
+}
+
+// Record records the latency of e
+func (h *LatencyHistogram)Record(e *OpEvent) {
+	s := h.__stat(latencyKey{region: e.Region, op: e.Op})
+	s.count.Add(1)
+	s.sum.Add(int64(e.Duration))
+	if e.Err != nil {
+		s.errs.Add(1)
+	}
+	idx := sort.Search(len(h.bounds), func(i int) bool { return e.Duration <= h.bounds[i] })
+	s.buckets[idx].Add(1)
+}
+
+// Snapshot returns the stats of all regions and ops, sorted by region and op
+func (h *LatencyHistogram)Snapshot() []LatencyStat {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	out := make([]LatencyStat, 0, len(h.stats))
+	for k, s := range h.stats {
+		st := LatencyStat{Region: k.region, Op: k.op, Count: s.count.Load(), Errs: s.errs.Load(),
+			Sum: time.Duration(s.sum.Load()), Bounds: h.bounds, Buckets: make([]uint64, len(s.buckets))}
+		for i := range s.buckets {
+			st.Buckets[i] = s.buckets[i].Load()
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Region != out[j].Region {
+			return out[i].Region < out[j].Region
+		}
+		return out[i].Op < out[j].Op
+	})
+	return out
+}
+
+// Reset clears all the stats
+func (h *LatencyHistogram)Reset() {
+	h.mu.Lock()
+	h.stats = map[latencyKey]*latencyStat{}
+	h.mu.Unlock()
+}
@@ -6,6 +6,7 @@ import (
 
 	//"github.com/dgraph-io/ristretto"
 	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/eerr"
 )
 
 type Item interface {
@@ -19,10 +20,11 @@ type ItemRegion[V Item] struct {
 	ttl      time.Duration
 	mem      *MemCache[[]byte, V]
 	Metrics  *Metrics
+	x        *itemIndexes[V]
 }
 
 func newItemRegion[T Item](db *db, ks []string) (*ItemRegion[T]) {
-	r := &ItemRegion[T]{db: db}
+	r := &ItemRegion[T]{db: db, x: &itemIndexes[T]{}}
 	r.meta.initItem(ks)	
 
 	return r
@@ -50,6 +52,42 @@ func (r *ItemRegion[T])SetDefaultTTL(ttl time.Duration){
 	r.ttl = ttl
 }
 
+// __db returns the db to write the items, it maintains the usage if the region has a quota
+func (r *ItemRegion[T])__db() (*db, error) {
+	q, err := r.db.quotaOf(&r.meta)
+	if err != nil || q == nil {
+		return r.db, err
+	}
+	if c, ok := r.db.db.(*ctxDB); ok {
+		return q.db.withCtx(c.ctx), nil
+	}
+	return q.db, nil
+}
+
+// SetQuota limits the count of keys and the bytes(key + value) of items in this region like Region.SetQuota,
+// the quota is separated from the one of Region with the same keys
+func (r *ItemRegion[T])SetQuota(maxKeys, maxBytes int64, policy QuotaPolicy) error {
+	return r.db.setQuota(&r.meta, maxKeys, maxBytes, policy)
+}
+
+// Quota returns the quota of this region, ok is false if it has no quota or failed to read it
+func (r *ItemRegion[T])Quota() (maxKeys, maxBytes int64, policy QuotaPolicy, ok bool) {
+	q, err := r.db.quotaOf(&r.meta)
+	if err != nil || q == nil {
+		return 0, 0, QUOTA_REJECT, false
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.maxKeys, q.maxBytes, q.policy, true
+}
+
+// Usage returns the count of keys and the bytes(key + value) of items in this region,
+// the keys expired may still be counted if the region has a quota
+func (r *ItemRegion[T])Usage() (keys int64, bytes int64, err error) {
+	return r.db.usage(&r.meta)
+}
+
 func (r *ItemRegion[T])setToMem(k []byte, v T, cost int64, ttl ...time.Duration) {
 	if r.mem == nil {
 		return
@@ -82,8 +120,10 @@ func (r *ItemRegion[T])Del(key []byte) error {
 		return err
 	}
 
-	r.mem.Del(key)
-	return r.db.del(r.meta.kpre, k)
+	if r.mem != nil {
+		r.mem.Del(key)
+	}
+	return r.__dels(k)
 }
 
 // key and val can only be string or []byte
@@ -105,7 +145,14 @@ func (r *ItemRegion[T])Set(key any, item T, ttl ...time.Duration) error {
 	}
 
 	r.setToMem(k, item, 1, valid_ttl)
-	return r.db.setVal(r.meta.kpre, k, raw, valid_ttl)
+	if r.__hasIndexes() {
+		return r.__setIndexed(k, item, &raw, valid_ttl)
+	}
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.setVal(r.meta.kpre, k, raw, valid_ttl)
 }
 
 func (r *ItemRegion[T])__valToItem(val Val, new func() T)(out T, err error){
@@ -117,7 +164,7 @@ func (r *ItemRegion[T])__valToItem(val Val, new func() T)(out T, err error){
 	}
 
 	if val.Type() != ITEM{
-		err = fmt.Errorf("invalid Raw type(%s)", val.__typeStr())
+		err = eerr.Pack(&TypeMismatchError{Want: ITEM.String(), Got: val.__typeStr()})
 		return
 	}
 
@@ -135,16 +182,16 @@ func (r *ItemRegion[T])Get(key any, new func() T, del...bool)(out T, err error)
 		return 
 	}
 
-	c, ok := r.getFromMem(k, del...)
-	if ok {
-		if len(del) > 0 && del[0] {
-			r.Del(k)
-		}
+	if len(del) > 0 && del[0] {
+		return r.__getDel(k, new)
+	}
 
+	c, ok := r.getFromMem(k)
+	if ok {
 		return c, nil
 	}
 
-	bin, expire, err := r.db.getBytesExt(r.meta.kpre, k, del...)
+	bin, expire, err := r.db.getBytesExt(r.meta.kpre, k)
 	if err != nil {
 		return
 	}
@@ -159,9 +206,7 @@ func (r *ItemRegion[T])Get(key any, new func() T, del...bool)(out T, err error)
 		return
 	}
 
-	if len(del) > 0 && del[0] {
-		r.db.del(r.meta.kpre, k)
-	} else if expire == 0 {
+	if expire == 0 {
 		r.setToMem(k, i, 1, time.Duration(0))
 	} else {
 		r.setToMem(k, i, 1, time.Until(time.Unix(int64(expire), 0)))
@@ -170,11 +215,47 @@ func (r *ItemRegion[T])Get(key any, new func() T, del...bool)(out T, err error)
 	return i, nil
 }
 
+// __getDel reads and deletes the key with its index entries in one transaction,
+// so only one of the concurrent callers gets the item
+func (r *ItemRegion[T])__getDel(k []byte, new func() T) (out T, err error) {
+	if r.mem != nil {
+		r.mem.Del(k)
+	}
+
+	db, err := r.__db()
+	if err != nil {
+		return
+	}
+
+	indexed := r.__hasIndexes()
+	var i T
+	if err = db.db.Update(func(tx driver.TX) error {
+		bin, _, err := tx.Get(r.meta.kpre, k, true)
+		if err != nil {
+			return err
+		}
+		if bin != nil {
+			var val Val; val.unmarshal(bin)
+			if i, err = r.__valToItem(val, new); err != nil {
+				return err
+			}
+		}
+		if indexed {
+			return r.meta.__unindex(tx, k)
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+
+	return i, nil
+}
+
 // Gets
 //   keys: can only be string, []string, []byte or [][]byte
 //   skipErrs_Del_RetainNil: this is a three-bool-value options:
 //     skipErrs : if is true, it will continue when err occurs in Unmarshal operations
-//     Del      : if is true, the keys will be deleted after all the operations
+//     Del      : if is true, the keys will be deleted with the reading in one transaction
 //     RetainNil: if is true, the nil value which created by err and not_found will be retain in the results
 func (r *ItemRegion[T])Gets(keys [][]byte, new func() T, skipErrs_Del_RetainNil...bool)(items []T, err error){
 	// ks, err := toBytesArr(keys)
@@ -189,24 +270,51 @@ func (r *ItemRegion[T])Gets(keys [][]byte, new func() T, skipErrs_Del_RetainNil.
 	}
 
 	skipErr   := len(skipErrs_Del_RetainNil) > 0 && skipErrs_Del_RetainNil[0]
+	del       := len(skipErrs_Del_RetainNil) > 1 && skipErrs_Del_RetainNil[1]
 	retainNil := len(skipErrs_Del_RetainNil) > 2 && skipErrs_Del_RetainNil[2]
 
-	if err := r.db.db.View(func(tx driver.TX)error{
+	// the keys are read from db only and deleted in the same transaction if del
+	exec := r.db.db.View
+	if del {
+		if r.mem != nil {
+			for _, key := range ks {
+				r.mem.Del(key)
+			}
+			r.mem.Wait()
+		}
+		db, err := r.__db()
+		if err != nil {
+			return nil, err
+		}
+		exec = db.db.Update
+	}
+	indexed := del && r.__hasIndexes()
+
+	if err := exec(func(tx driver.TX)error{
 		for _, key := range ks {
-			i, ok := r.getFromMem(key)
-			if ok {
-				items = append(items, i)
-				continue
+			var i T
+			if !del {
+				var ok bool
+				if i, ok = r.getFromMem(key); ok {
+					items = append(items, i)
+					continue
+				}
 			}
 
-			bin, _, err := tx.Get(r.meta.kpre, key)
+			bin, _, err := tx.Get(r.meta.kpre, key, del)
+			if indexed {
+				if err := r.meta.__unindex(tx, key); err != nil {
+					return err
+				}
+			}
 			if err == nil {
 				var val Val; val.unmarshal(bin)
-				i2, err := r.__valToItem(val, new)
-				if err == nil {
+				var i2 T
+				if i2, err = r.__valToItem(val, new); err == nil {
 					items = append(items, i2)
 					continue
 				}
+				err = fmt.Errorf("key '%s': %w", key, err)
 			}
 			
 			if skipErr {
@@ -224,14 +332,6 @@ func (r *ItemRegion[T])Gets(keys [][]byte, new func() T, skipErrs_Del_RetainNil.
 		return nil, err
 	}
 
-	if len(skipErrs_Del_RetainNil) > 1 && skipErrs_Del_RetainNil[1] {
-		for _, key := range ks {
-			r.mem.Del(key)
-		}
-		r.db.dels(r.meta.kpre, ks...)
-		r.mem.Wait()
-	} 
-
 	return 
 }
 
@@ -245,7 +345,7 @@ func (r *ItemRegion[T])GetAll(new func() T, skipErrs... bool) ([]T, error) {
 			items = append(items, i)
 			r.setToMem(key, i, 1, time.Until(time.Unix(int64(expiresAt), 0)))
 		} else if !skipErr{
-			return fmt.Errorf("do Unmarshal failed for key '%s': %s", key, err)
+			return fmt.Errorf("do Unmarshal failed for key '%s': %w", key, err)
 		}
 
 		return nil
@@ -0,0 +1,384 @@
+package ecache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/eerr"
+)
+
+// IndexFunc extracts the index values from an item, an item can have multiple values or none for an index
+type IndexFunc[T Item] func(T) [][]byte
+
+// itemIndexes holds the indexes registered on an ItemRegion
+type itemIndexes[T Item] struct {
+	mu sync.RWMutex
+	m  map[string]IndexFunc[T]
+}
+
+var __x_refs = []byte{0}   // the prefix of the entries records of pkeys in xpre
+
+/*
+  the entries are stored as (prefix: xpre name 0, key: value 0 1 pkey) and the entries records are stored as
+  (prefix: xpre 0, key: pkey), so the prefixes passed to driver.TX are the same in Set and Iterate for an index,
+  it is required by the drivers that use the prefix as the bucket name, like nutsdb
+*/
+
+// __xEncode encodes the value to keep the order of values, the 0 is escaped as 0 255 and it ends with 0 1
+func __xEncode(v []byte) []byte {
+	out := make([]byte, 0, len(v) + 2)
+	for _, c := range v {
+		out = append(out, c)
+		if c == 0 {
+			out = append(out, 255)
+		}
+	}
+	return append(out, 0, 1)
+}
+
+// __xDecode decodes the value from the beginning of b, and returns the rest of b
+func __xDecode(b []byte) (v []byte, rest []byte, ok bool) {
+	for i := 0; i < len(b) - 1; i++ {
+		if b[i] != 0 {
+			v = append(v, b[i])
+			continue
+		}
+
+		switch b[i+1] {
+		case 255: v = append(v, 0); i++
+		case 1  : return v, b[i+2:], true
+		default : return nil, nil, false
+		}
+	}
+	return nil, nil, false
+}
+
+func __xName(name string) []byte {
+	return append([]byte(name), 0)
+}
+
+func __xEncodeRefs(entries [][]byte) []byte {
+	var out []byte
+	for _, e := range entries {
+		out = binary.AppendUvarint(out, uint64(len(e)))
+		out = append(out, e...)
+	}
+	return out
+}
+
+func __xDecodeRefs(b []byte) (entries [][]byte) {
+	for len(b) > 0 {
+		l, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b) - n) < l {
+			return
+		}
+		entries = append(entries, b[n:n+int(l)])
+		b = b[n+int(l):]
+	}
+	return
+}
+
+// AddIndex registers a secondary index named name, the index entries are maintained in the same transaction
+// on Set and Del of this handle, so register the indexes before writing.
+// the items written before registering will not be indexed, call RebuildIndexes to index them
+func (r *ItemRegion[T])AddIndex(name string, fn IndexFunc[T]) error {
+	if name == "" || bytes.IndexByte([]byte(name), 0) >= 0 {
+		return fmt.Errorf("invalid index name '%s', it should not be empty or contain '\\x00'", name)
+	}
+	if fn == nil {
+		return fmt.Errorf("invalid index func for index '%s', it should not be nil", name)
+	}
+
+	r.x.mu.Lock()
+	defer r.x.mu.Unlock()
+
+	if _, ok := r.x.m[name]; ok {
+		return fmt.Errorf("index '%s' already exists", name)
+	}
+	if r.x.m == nil {
+		r.x.m = map[string]IndexFunc[T]{}
+	}
+	r.x.m[name] = fn
+	return nil
+}
+
+func (r *ItemRegion[T])__hasIndexes() bool {
+	r.x.mu.RLock()
+	defer r.x.mu.RUnlock()
+	return len(r.x.m) > 0
+}
+
+// __indexEntries returns the sorted entries of the item for all the indexes
+func (r *ItemRegion[T])__indexEntries(k []byte, item T) [][]byte {
+	r.x.mu.RLock()
+	defer r.x.mu.RUnlock()
+
+	set := map[string]bool{}
+	for name, fn := range r.x.m {
+		for _, v := range fn(item) {
+			set[string(__catBytes(__xName(name), __xEncode(v), k))] = true
+		}
+	}
+
+	out := make([][]byte, 0, len(set))
+	for e := range set {
+		out = append(out, []byte(e))
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i], out[j]) < 0 })
+	return out
+}
+
+func (m *rMeta)__refsPre() []byte {
+	return __catBytes(m.xpre, __x_refs)
+}
+
+func (r *ItemRegion[T])__indexPre(name string) []byte {
+	return __catBytes(r.meta.xpre, __xName(name))
+}
+
+// __splitEntry splits the entry(name 0 value 0 1 pkey) to the prefix and key passed to driver.TX
+func (m *rMeta)__splitEntry(e []byte) (prefix []byte, key []byte) {
+	idx := bytes.IndexByte(e, 0)
+	return __catBytes(m.xpre, e[:idx+1]), e[idx+1:]
+}
+
+// __unindex removes all the index entries of k, it is used by the quota to evict the keys of ItemRegion too
+func (m *rMeta)__unindex(tx driver.TX, k []byte) error {
+	raw, _, err := tx.Get(m.__refsPre(), k, true)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range __xDecodeRefs(raw) {
+		pre, key := m.__splitEntry(e)
+		if err = tx.Del(pre, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ItemRegion[T])__index(tx driver.TX, k []byte, entries [][]byte, ttl time.Duration) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	for _, e := range entries {
+		pre, key := r.meta.__splitEntry(e)
+		if err := tx.Set(pre, key, []byte{0}, ttl); err != nil {
+			return err
+		}
+	}
+	return tx.Set(r.meta.__refsPre(), k, __xEncodeRefs(entries), ttl)
+}
+
+// __setIndexed sets the item and updates its index entries in one transaction
+func (r *ItemRegion[T])__setIndexed(k []byte, item T, raw *Val, ttl time.Duration) error {
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+
+	entries := r.__indexEntries(k, item)
+	return db.db.Update(func(tx driver.TX) error {
+		if err := r.meta.__unindex(tx, k); err != nil {
+			return err
+		}
+		if err := tx.Set(r.meta.kpre, k, raw.marshal(), ttl); err != nil {
+			return err
+		}
+		return r.__index(tx, k, entries, ttl)
+	})
+}
+
+// __dels deletes the keys and their index entries in one transaction
+func (r *ItemRegion[T])__dels(keys ...[]byte) error {
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	if !r.__hasIndexes() {
+		return db.dels(r.meta.kpre, keys...)
+	}
+
+	return db.db.Update(func(tx driver.TX) error {
+		for _, k := range keys {
+			if err := r.meta.__unindex(tx, k); err != nil {
+				return err
+			}
+			if err := tx.Del(r.meta.kpre, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ItemRegion[T])__checkIndex(name string) error {
+	r.x.mu.RLock()
+	defer r.x.mu.RUnlock()
+	if _, ok := r.x.m[name]; !ok {
+		return eerr.Errorf("index '%s' %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+// __scanIndex iterates the entries of index name in the order of values from the value from, fn returns false to stop
+func (r *ItemRegion[T])__scanIndex(tx driver.TX, name string, from []byte, fn func(v []byte, pkey []byte) bool) error {
+	var start []byte
+	if from != nil {
+		start = __xEncode(from)
+	}
+	err := driver.IterateFrom(tx, r.__indexPre(name), start, func(_ int, key []byte, _ []byte, _ uint64) error {
+		v, pkey, ok := __xDecode(key)
+		if !ok {
+			return nil
+		}
+		if !fn(v, pkey) {
+			return errStopIter
+		}
+		return nil
+	})
+	if err == errStopIter {
+		err = nil
+	}
+	return err
+}
+
+// __loadItems loads the items of pkeys in tx, the missing ones are skipped
+func (r *ItemRegion[T])__loadItems(tx driver.TX, pkeys [][]byte, new func() T) (items []T, err error) {
+	for _, k := range pkeys {
+		if i, ok := r.getFromMem(k); ok {
+			items = append(items, i)
+			continue
+		}
+
+		bin, _, err := tx.Get(r.meta.kpre, k)
+		if err != nil {
+			return nil, err
+		}
+		if bin == nil {
+			continue
+		}
+
+		var val Val; val.unmarshal(bin)
+		i, err := r.__valToItem(val, new)
+		if err != nil {
+			return nil, fmt.Errorf("do Unmarshal failed for key '%s': %w", k, err)
+		}
+		items = append(items, i)
+	}
+	return
+}
+
+// FindBy returns the items whose values of index name equal to value, in the order of primary keys
+func (r *ItemRegion[T])FindBy(name string, value []byte, new func() T) (items []T, err error) {
+	if err = r.__checkIndex(name); err != nil {
+		return
+	}
+
+	// the entries of value are started with the encoded value, seek to it and stop at the first one not
+	enc := __xEncode(value)
+	err = r.db.db.View(func(tx driver.TX) error {
+		var pkeys [][]byte
+		err := driver.IterateFrom(tx, r.__indexPre(name), enc, func(_ int, key []byte, _ []byte, _ uint64) error {
+			if !bytes.HasPrefix(key, enc) {
+				return errStopIter
+			}
+			pkeys = append(pkeys, append([]byte{}, key[len(enc):]...))
+			return nil
+		})
+		if err != nil && err != errStopIter {
+			return err
+		}
+
+		items, err = r.__loadItems(tx, pkeys, new)
+		return err
+	})
+	return
+}
+
+// RangeBy returns the items whose values of index name are in [from, to), in the order of values,
+// from == nil means no lower bound and to == nil means no upper bound
+func (r *ItemRegion[T])RangeBy(name string, from, to []byte, new func() T) (items []T, err error) {
+	if err = r.__checkIndex(name); err != nil {
+		return
+	}
+
+	err = r.db.db.View(func(tx driver.TX) error {
+		var pkeys [][]byte
+		if err := r.__scanIndex(tx, name, from, func(v []byte, pkey []byte) bool {
+			if to != nil && bytes.Compare(v, to) >= 0 {
+				return false
+			}
+			pkeys = append(pkeys, append([]byte{}, pkey...))
+			return true
+		}); err != nil {
+			return err
+		}
+
+		items, err = r.__loadItems(tx, pkeys, new)
+		return err
+	})
+	return
+}
+
+// RebuildIndexes drops all the entries of the registered indexes and rebuilds them by scanning the items
+func (r *ItemRegion[T])RebuildIndexes(new func() T) error {
+	pres := [][]byte{r.meta.__refsPre()}
+	r.x.mu.RLock()
+	for name := range r.x.m {
+		pres = append(pres, r.__indexPre(name))
+	}
+	r.x.mu.RUnlock()
+
+	for _, pre := range pres {
+		if err := r.db.db.DropPrefix(pre); err != nil {
+			return err
+		}
+	}
+
+	type rec struct {
+		k    []byte
+		item T
+		ttl  time.Duration
+	}
+	var recs []rec
+	if err := r.db.doForAllEx(r.meta.kpre, func(_ int, key []byte, val Val, expiresAt uint64) error {
+		i, err := r.__valToItem(val, new)
+		if err != nil {
+			return fmt.Errorf("do Unmarshal failed for key '%s': %w", key, err)
+		}
+
+		ttl := time.Duration(0)
+		if expiresAt > 0 {
+			if ttl = time.Until(time.Unix(int64(expiresAt), 0)); ttl <= 0 {
+				return nil
+			}
+		}
+		recs = append(recs, rec{k: append([]byte{}, key...), item: i, ttl: ttl})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for i := 0; i < len(recs); {
+		if err := r.db.db.Update(func(tx driver.TX) error {
+			for cnt := 0; i < len(recs) && cnt < 1000; i, cnt = i + 1, cnt + 1 {
+				if err := r.__index(tx, recs[i].k, r.__indexEntries(recs[i].k, recs[i].item), recs[i].ttl); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
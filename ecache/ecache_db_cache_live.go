@@ -0,0 +1,185 @@
+package ecache
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/eerr"
+)
+
+// liveGen is one generation of the underlying driver.DB, it records the in-flight operations on it
+type liveGen struct {
+	db       driver.DB
+	inflight int
+}
+
+// liveDB wraps a driver.DB which can be replaced or closed while the handles(DBCache, Region, ...) built on it
+// are still in using, the old driver.DB will only be closed after all the in-flight operations on it finished
+type liveDB struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	cur      *liveGen
+	blocking bool
+	closed   bool
+}
+
+func newLiveDB(db driver.DB) *liveDB {
+	l := &liveDB{cur: &liveGen{db: db}}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+func (l *liveDB) acquire() (*liveGen, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.blocking {
+		l.cond.Wait()
+	}
+
+	if l.closed {
+		return nil, eerr.Pack(ErrClosed)
+	}
+
+	l.cur.inflight += 1
+	return l.cur, nil
+}
+
+func (l *liveDB) release(g *liveGen) {
+	l.mu.Lock()
+	g.inflight -= 1
+	if g.inflight == 0 {
+		l.cond.Broadcast()
+	}
+	l.mu.Unlock()
+}
+
+// __waitIdle waits all the in-flight operations on g finished, l.mu must be locked
+func (l *liveDB) __waitIdle(g *liveGen) {
+	for g.inflight > 0 {
+		l.cond.Wait()
+	}
+}
+
+// swap replaces the underlying driver.DB with the one opened by open(),
+//   it will try to open the new one first, if failed(like the dir is locked by the old one),
+//   it will block the new operations, close the old one after all in-flight operations finished, and then open the new one,
+//   the old dsn will be reopened by reopen() if the new one still can not be opened
+func (l *liveDB) swap(open func() (driver.DB, error), reopen func() (driver.DB, error)) error {
+	if n, err := open(); err == nil {
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			n.Close()
+			return eerr.Pack(ErrClosed)
+		}
+		old := l.cur
+		l.cur = &liveGen{db: n}
+		l.__waitIdle(old)
+		l.mu.Unlock()
+
+		return old.db.Close()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.blocking {
+		l.cond.Wait()
+	}
+	if l.closed {
+		return eerr.Pack(ErrClosed)
+	}
+
+	l.blocking = true
+	defer func() { l.blocking = false; l.cond.Broadcast() }()
+
+	l.__waitIdle(l.cur)
+	if err := l.cur.db.Close(); err != nil {
+		return err
+	}
+
+	n, err := open()
+	if err == nil {
+		l.cur = &liveGen{db: n}
+		return nil
+	}
+
+	o, err2 := reopen()
+	if err2 != nil {
+		l.closed = true
+		return fmt.Errorf("open new db failed: %s, and reopen the old one failed: %s", err, err2)
+	}
+
+	l.cur = &liveGen{db: o}
+	return fmt.Errorf("open new db failed: %s", err)
+}
+
+// TX wraps tx by the db of current generation, the generation is referenced while wrapping so it can not be closed
+// by reopen in the middle, it returns nil if closed
+func (l *liveDB) TX(tx interface{}) driver.TX {
+	g, err := l.acquire()
+	if err != nil {
+		return nil
+	}
+	defer l.release(g)
+
+	return g.db.TX(tx)
+}
+
+func (l *liveDB) Update(fn func(tx driver.TX) error) error {
+	g, err := l.acquire()
+	if err != nil {
+		return err
+	}
+	defer l.release(g)
+
+	return g.db.Update(fn)
+}
+
+func (l *liveDB) View(fn func(tx driver.TX) error) error {
+	g, err := l.acquire()
+	if err != nil {
+		return err
+	}
+	defer l.release(g)
+
+	return g.db.View(fn)
+}
+
+func (l *liveDB) DropPrefix(prefix []byte) error {
+	g, err := l.acquire()
+	if err != nil {
+		return err
+	}
+	defer l.release(g)
+
+	return g.db.DropPrefix(prefix)
+}
+
+func (l *liveDB) Truncate() error {
+	g, err := l.acquire()
+	if err != nil {
+		return err
+	}
+	defer l.release(g)
+
+	return g.db.Truncate()
+}
+
+// Close closes the underlying driver.DB after all the in-flight operations finished
+func (l *liveDB) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.blocking {
+		l.cond.Wait()
+	}
+	if l.closed {
+		return nil
+	}
+
+	l.closed = true
+	l.__waitIdle(l.cur)
+	return l.cur.db.Close()
+}
@@ -0,0 +1,274 @@
+package ecache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+var (
+	ErrLocked      = errors.New("the lock is held by others")
+	ErrLockNotHeld = errors.New("the lock is not held by this lease, it may be expired or released")
+)
+
+const (
+	__lock_sub    = "__locks"              // the sub region to store lock records
+	__fence_sub   = "__fences"             // the sub region to store fencing tokens
+	__lock_rec_sz = 16 + 8 + 8             // owner token + fencing token + expiresAt(unix nano)
+	__lock_ttl_ex = time.Second * 2        // the extra ttl set to driver, the expiration is checked by the expiresAt in record
+	__lock_poll   = time.Millisecond * 50
+)
+
+// Locker - the named locks stored in a Region, they can be used across goroutines and processes sharing the same cache
+//
+//   1. every lock acquired returns a Lease with a unique owner token, only the owner can renew or release it
+//   2. every lock has a ttl, it can be acquired by others after expired if the owner not renew it
+//   3. every acquisition gets a fencing token increased monotonically per name, it can be used to reject the
+//      writes from an expired owner
+//
+// the lock records are stored in the sub region '__locks' and '__fences' of the region,
+// the driver should support atomic get-and-set in one Update
+type Locker struct {
+	locks  *Region
+	fences *Region
+	poll   time.Duration
+}
+
+// Lease - a lock held by an owner
+type Lease struct {
+	name      string
+	token     []byte
+	fence     uint64
+	expiresAt atomic.Int64
+}
+
+func newLocker(r *Region) *Locker {
+	return &Locker{
+		locks : r.SubRegion(__lock_sub),
+		fences: r.SubRegion(__fence_sub),
+		poll  : __lock_poll,
+	}
+}
+
+func (le *Lease)Name() string           { return le.name }
+func (le *Lease)Token() string          { return uuid.FromBytesOrNil(le.token).String() }
+func (le *Lease)Fence() uint64          { return le.fence }
+func (le *Lease)ExpiresAt() time.Time   { return time.Unix(0, le.expiresAt.Load()) }
+func (le *Lease)Expired() bool          { return time.Now().UnixNano() >= le.expiresAt.Load() }
+
+// SetPollInterval sets the interval of retrying in Lock, default 50ms
+func (l *Locker)SetPollInterval(d time.Duration) {
+	if d > 0 {
+		l.poll = d
+	}
+}
+
+type __lockRec struct {
+	token     []byte
+	fence     uint64
+	expiresAt int64
+}
+
+func (r *__lockRec)marshal() []byte {
+	b := make([]byte, __lock_rec_sz)
+	copy(b, r.token)
+	binary.BigEndian.PutUint64(b[16:], r.fence)
+	binary.BigEndian.PutUint64(b[24:], uint64(r.expiresAt))
+
+	v := newVal()
+	defer recycleVal(v)
+	v.setBytes(b)
+	return v.marshal()
+}
+
+func (r *__lockRec)unmarshal(raw []byte) error {
+	var v Val
+	v.unmarshal(raw)
+	b, err := v.GetBytes()
+	if err != nil {
+		return err
+	}
+	if len(b) != __lock_rec_sz {
+		return fmt.Errorf("invalid lock record, the size should be %d, but got %d", __lock_rec_sz, len(b))
+	}
+
+	r.token     = append([]byte{}, b[:16]...)
+	r.fence     = binary.BigEndian.Uint64(b[16:])
+	r.expiresAt = int64(binary.BigEndian.Uint64(b[24:]))
+	return nil
+}
+
+// __getLock returns the lock record of name, returns nil if not exist or expired
+func (l *Locker)__getLock(tx driver.TX, name []byte, now int64) (*__lockRec, error) {
+	raw, _, err := tx.Get(l.locks.meta.kpre, name)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	rec := &__lockRec{}
+	if err = rec.unmarshal(raw); err != nil {
+		return nil, err
+	}
+	if rec.expiresAt <= now {
+		return nil, nil
+	}
+
+	return rec, nil
+}
+
+func (l *Locker)__setLock(tx driver.TX, name []byte, rec *__lockRec, ttl time.Duration) error {
+	return tx.Set(l.locks.meta.kpre, name, rec.marshal(), ttl + __lock_ttl_ex)
+}
+
+func (l *Locker)__nextFence(tx driver.TX, name []byte) (uint64, error) {
+	raw, _, err := tx.Get(l.fences.meta.kpre, name)
+	if err != nil {
+		return 0, err
+	}
+
+	fence := uint64(0)
+	if raw != nil {
+		var v Val
+		v.unmarshal(raw)
+		if fence, err = v.GetU64(); err != nil {
+			return 0, err
+		}
+	}
+	fence += 1
+
+	v := newVal()
+	defer recycleVal(v)
+	v.setU64(fence)
+	if err = tx.Set(l.fences.meta.kpre, name, v.marshal()); err != nil {
+		return 0, err
+	}
+
+	return fence, nil
+}
+
+// TryLock tries to acquire the lock once, returns ErrLocked if it is held by others
+func (l *Locker)TryLock(name string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid ttl(%s) for lock '%s', it should be > 0", ttl, name)
+	}
+
+	k     := []byte(name)
+	token := uuid.NewV4().Bytes()
+	var lease *Lease
+	err := l.locks.db.db.Update(func(tx driver.TX) error {
+		now := time.Now()
+		cur, err := l.__getLock(tx, k, now.UnixNano())
+		if err != nil {
+			return err
+		}
+		if cur != nil {
+			return ErrLocked
+		}
+
+		fence, err := l.__nextFence(tx, k)
+		if err != nil {
+			return err
+		}
+
+		rec := &__lockRec{token: token, fence: fence, expiresAt: now.Add(ttl).UnixNano()}
+		if err = l.__setLock(tx, k, rec, ttl); err != nil {
+			return err
+		}
+
+		lease = &Lease{name: name, token: token, fence: fence}
+		lease.expiresAt.Store(rec.expiresAt)
+		return nil
+	})
+	if errors.Is(err, driver.ErrTxConflict) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lease, nil
+}
+
+// Lock acquires the lock, it will block until the lock acquired or the ctx done
+func (l *Locker)Lock(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+	for {
+		lease, err := l.TryLock(name, ttl)
+		if err != ErrLocked {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done(): return nil, ctx.Err()
+		case <-time.After(l.poll):
+		}
+	}
+}
+
+// Renew extends the expiration of the lease to now + ttl, returns ErrLockNotHeld if the lease is expired or released,
+// the fencing token will not be changed
+func (l *Locker)Renew(lease *Lease, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("invalid ttl(%s) for lock '%s', it should be > 0", ttl, lease.name)
+	}
+
+	k := []byte(lease.name)
+	var expiresAt int64
+	err := l.locks.db.db.Update(func(tx driver.TX) error {
+		now := time.Now()
+		cur, err := l.__getLock(tx, k, now.UnixNano())
+		if err != nil {
+			return err
+		}
+		if cur == nil || string(cur.token) != string(lease.token) {
+			return ErrLockNotHeld
+		}
+
+		cur.expiresAt = now.Add(ttl).UnixNano()
+		expiresAt     = cur.expiresAt
+		return l.__setLock(tx, k, cur, ttl)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the lease is updated only after the txn committed
+	lease.expiresAt.Store(expiresAt)
+	return nil
+}
+
+// Unlock releases the lease, returns ErrLockNotHeld if the lease is expired or released
+func (l *Locker)Unlock(lease *Lease) error {
+	k := []byte(lease.name)
+	err := l.locks.db.db.Update(func(tx driver.TX) error {
+		cur, err := l.__getLock(tx, k, time.Now().UnixNano())
+		if err != nil {
+			return err
+		}
+		if cur == nil || string(cur.token) != string(lease.token) {
+			return ErrLockNotHeld
+		}
+
+		return tx.Del(l.locks.meta.kpre, k)
+	})
+	if err != nil {
+		return err
+	}
+
+	lease.expiresAt.Store(0)
+	return nil
+}
+
+// Fence returns the latest fencing token of the lock name, returns 0 if it never be acquired
+func (l *Locker)Fence(name string) (uint64, error) {
+	v, err := l.fences.Get(name)
+	if err != nil {
+		return 0, err
+	}
+	return v.U64(), nil
+}
@@ -1,6 +1,7 @@
 package ecache
 
 import (
+	"context"
 	"time"
 )
 
@@ -8,6 +9,7 @@ type Region struct {
   db       *db
 	meta     rMeta
 	ttl      time.Duration   // not used now
+	ctx      context.Context // the ctx of the operations, set by the Ctx variants
 }
 
 func newRegion(db *db, ks []string) (*Region) {
@@ -35,65 +37,161 @@ func (r *Region)SetDefaultTTL(ttl time.Duration) {
 	r.ttl = ttl
 }
 
+// __db returns the db to write the records, it maintains the usage if the region has a quota
+func (r *Region)__db() (*db, error) {
+	q, err := r.db.quotaOf(&r.meta)
+	if err != nil {
+		return nil, err
+	}
+	if q != nil {
+		return q.db.withCtx(r.ctx), nil
+	}
+	return r.db.withCtx(r.ctx), nil
+}
+
+// __rdb returns the db to read the records, the reads go through the quota only when deleting the keys
+func (r *Region)__rdb(del ...bool) (*db, error) {
+	if len(del) > 0 && del[0] {
+		return r.__db()
+	}
+	return r.db.withCtx(r.ctx), nil
+}
+
+// SetQuota limits the count of keys and the bytes(key + value) of records in this region, 0 means no limit,
+// the quota will be removed if both maxKeys and maxBytes <= 0, policy decides what to do when the quota is exceeded:
+//   QUOTA_REJECT              : the writes will fail with *ErrQuotaExceeded
+//   QUOTA_EVICT_OLDEST        : the oldest written keys will be evicted
+//   QUOTA_EVICT_NEAREST_EXPIRY: the keys nearest to expire will be evicted, then the oldest ones without ttl
+// the usage is kept in the info records of region and rebuilt by scanning the records when setting the quota first time,
+// the quota is checked on the next writes, the existing records will not be evicted immediately.
+// note: the sub regions are not counted in the usage
+func (r *Region)SetQuota(maxKeys, maxBytes int64, policy QuotaPolicy) error {
+	return r.db.setQuota(&r.meta, maxKeys, maxBytes, policy)
+}
+
+// Quota returns the quota of this region, ok is false if it has no quota or failed to read it
+func (r *Region)Quota() (maxKeys, maxBytes int64, policy QuotaPolicy, ok bool) {
+	q, err := r.db.quotaOf(&r.meta)
+	if err != nil || q == nil {
+		return 0, 0, QUOTA_REJECT, false
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.maxKeys, q.maxBytes, q.policy, true
+}
+
+// Usage returns the count of keys and the bytes(key + value) of records in this region,
+// the keys expired may still be counted if the region has a quota
+func (r *Region)Usage() (keys int64, bytes int64, err error) {
+	return r.db.usage(&r.meta)
+}
+
 // key and val can only be string or []byte
 func (r *Region)Set(key any, val any, ttl ...time.Duration) error {
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
 	if len(ttl) > 0 {
-		return r.db.setAny(r.meta.kpre, key, val, ttl...)
+		return db.setAny(r.meta.kpre, key, val, ttl...)
 	}
 
-	return r.db.setAny(r.meta.kpre, key, val, r.ttl)
+	return db.setAny(r.meta.kpre, key, val, r.ttl)
 }
 
 // key and val can only be string, []string, []byte or [][]byte
 func (r *Region)Sets(keys any, vals any, ttls ...time.Duration) error {
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
 	if len(ttls) > 0 {
-		return r.db.setsAny(r.meta.kpre, keys, vals, ttls...)
+		return db.setsAny(r.meta.kpre, keys, vals, ttls...)
 	}
 
-	return r.db.setsAny(r.meta.kpre, keys, vals, r.ttl)
+	return db.setsAny(r.meta.kpre, keys, vals, r.ttl)
 }
 
 func (r *Region)SetObjs(items []any, fn func(int, any)(k []byte, v any, ttl time.Duration))error{
-	return r.db.setObjs(r.meta.kpre, items, fn)
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.setObjs(r.meta.kpre, items, fn)
 }
 
 // key can only be string or []byte
 func (r *Region)Get(key any, del ...bool)(Val, error){
-	return r.db.getAny(r.meta.kpre, key, del...)
+	db, err := r.__rdb(del...)
+	if err != nil {
+		return Val{}, err
+	}
+	return db.getAny(r.meta.kpre, key, del...)
 }
 
 // key can only be string or []byte
 func (r *Region)GetEx(key any, del ...bool)(Val, uint64, error){
-	return r.db.getAnyEx(r.meta.kpre, key, del...)
+	db, err := r.__rdb(del...)
+	if err != nil {
+		return Val{}, 0, err
+	}
+	return db.getAnyEx(r.meta.kpre, key, del...)
 }
 
 // key can only be string, []strng, []byte or [][]byte
 func (r *Region)Gets(keys any, del ...bool)([]Val, error){
-	return r.db.getsAny(r.meta.kpre, keys, del...)
+	db, err := r.__rdb(del...)
+	if err != nil {
+		return nil, err
+	}
+	return db.getsAny(r.meta.kpre, keys, del...)
 }
 
 // GetAll - returns all keys and values in this region
 func (r *Region)GetAll()([][]byte, []Val, error){
-	return r.db.getAll(r.meta.kpre)
+	return r.db.withCtx(r.ctx).getAll(r.meta.kpre)
+}
+
+// Update loads the val of key and stores the val returned by fn in one transaction, key can only be string or []byte
+//   1. the old val passed to fn is a Nil Val if the key not exist, it is only valid in fn
+//   2. the key will be deleted if fn returns a nil val
+//   3. nothing will be changed if fn returns an error, and the error will be returned
+//   4. the ttl returned by fn will be set to the key, 0 means never expire
+// the driver may return driver.ErrTxConflict if the key is modified by others concurrently, you can retry it
+func (r *Region)Update(key any, fn func(old Val)(val any, ttl time.Duration, err error)) error {
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.updateAny(r.meta.kpre, key, fn)
 }
 
 // key can only be string or []byte
 func (r *Region)Del(key any)(error){
-	return r.db.delAny(r.meta.kpre, key)
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.delAny(r.meta.kpre, key)
 }
 
 // key can only be string, []strng, []byte or [][]byte
 func (r *Region)Dels(keys ...any) (error) {
-	return r.db.delsAny(r.meta.kpre, keys...)
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.delsAny(r.meta.kpre, keys...)
 }
 
 func (r *Region)DoForAll(fn func(idx int, key []byte, val Val) error)error{
-	return r.db.doForAll(r.meta.kpre, fn)
+	return r.db.withCtx(r.ctx).doForAll(r.meta.kpre, fn)
 }
 
 // key can only be string, []strng, []byte or [][]byte
 func (r *Region)DoForKeys(keys any, fn func(idx int, key []byte, val Val) error)error{
-	return r.db.doForKeysAny(r.meta.kpre, keys, fn)
+	return r.db.withCtx(r.ctx).doForKeysAny(r.meta.kpre, keys, fn)
 }
 
 func (r *Region)Truncate(/*including_subs ...bool*/) error {
@@ -102,5 +200,9 @@ func (r *Region)Truncate(/*including_subs ...bool*/) error {
 	// 	return fmt.Errorf("todo")
 	// }
 
-	return r.db.db.DropPrefix(r.meta.kpre)
+	db, err := r.__db()
+	if err != nil {
+		return err
+	}
+	return db.db.DropPrefix(r.meta.kpre)
 }
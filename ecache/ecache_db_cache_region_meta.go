@@ -36,6 +36,15 @@ var (
 
 	when we want to scan keys for a region, we can scan prefix like this: 7 key1 5 key2 6
 
+	secondary indexes for item regions:
+  -- store key ---------------------------------          -- region --
+	8 key1 5 key2 7 name 0 value 0 1 pkey                   [key1,key2]
+	8 key1 5 key2 7 0 pkey                'entries'         [key1,key2]
+	8 key1 5 key2 4 skey1 7 name 0 value 0 1 pkey           [key1,key2].[skey1]
+
+	the 0 in value is escaped as 0 255, so the entries of an index are sorted by value,
+	the second one records all the entries of pkey, it is used to remove them when updating or deleting pkey
+
 	*/
 	__r_pre = []byte{5}   // for regions list raw key building 
 	__r_gap = []byte{5}
@@ -50,6 +59,10 @@ var (
 	__I_gap = []byte{5}
 	__I_pos = []byte{7}
 
+	__x_pre = []byte{8}   // for item regions secondary indexes raw key building
+	__x_gap = []byte{5}
+	__x_pos = []byte{7}
+
 	__sk_gap = []byte{4}  // for connecting between region and sub regions
 )
 
@@ -59,7 +72,9 @@ type rMeta struct {
 	rpre      []byte        // caching the regions list    key prefix
   ipre      []byte        // caching the regions infos   key prefix
 	kpre      []byte        // caching the regions records key prefix
+	xpre      []byte        // caching the regions indexes key prefix
 	kpreLen   int
+	item      bool          // is the meta of ItemRegion
 }
 
 func (m *rMeta) init(keys []string)  {
@@ -67,6 +82,7 @@ func (m *rMeta) init(keys []string)  {
 	m.rpre  = m.genKeyPre(__r_pre, __r_gap, __r_pos, keys)
 	m.ipre  = m.genKeyPre(__i_pre, __i_gap, __i_pos, keys)
 	m.kpre  = m.genKeyPre(__k_pre, __k_gap, __k_pos, keys)
+	m.xpre  = m.genKeyPre(__x_pre, __x_gap, __x_pos, keys)
 
 	m.kpreLen = len(m.kpre)
 }
@@ -76,6 +92,8 @@ func (m *rMeta) initItem(keys []string)  {
 	m.rpre  = m.genKeyPre(__r_pre, __r_gap, __r_pos, keys)
 	m.ipre  = m.genKeyPre(__i_pre, __i_gap, __i_pos, keys)
 	m.kpre  = m.genKeyPre(__I_pre, __I_gap, __I_pos, keys)
+	m.xpre  = m.genKeyPre(__x_pre, __x_gap, __x_pos, keys)
+	m.item  = true
 
 	m.kpreLen = len(m.kpre)
 }
@@ -87,6 +105,7 @@ func (m *rMeta)genSubMeta(keys []string)	rMeta{
 	o.rpre = m.catSubForKeyPre(__r_pre, __r_gap, __r_pos, __sk_gap, m.rpre, keys)
 	o.ipre = m.catSubForKeyPre(__i_pre, __i_gap, __i_pos, __sk_gap, m.ipre, keys)
 	o.kpre = m.catSubForKeyPre(__k_pre, __k_gap, __k_pos, __sk_gap, m.kpre, keys)
+	o.xpre = m.catSubForKeyPre(__x_pre, __x_gap, __x_pos, __sk_gap, m.xpre, keys)
 	o.kpreLen = len(o.kpre)
 
 	return *o
@@ -0,0 +1,551 @@
+package ecache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+type QuotaPolicy int
+
+const (
+	QUOTA_REJECT               QuotaPolicy = iota  // reject the writes exceeding the quota with ErrQuotaExceeded
+	QUOTA_EVICT_OLDEST                             // evict the oldest written keys to make room
+	QUOTA_EVICT_NEAREST_EXPIRY                     // evict the keys nearest to expire to make room, the keys without ttl will be evicted at last by the oldest order
+)
+
+func (p QuotaPolicy) String() string {
+	switch p {
+	case QUOTA_REJECT              : return "reject"
+	case QUOTA_EVICT_OLDEST        : return "evict-oldest"
+	case QUOTA_EVICT_NEAREST_EXPIRY: return "evict-nearest-expiry"
+	}
+	return fmt.Sprintf("QuotaPolicy(%d)", int(p))
+}
+
+// ErrQuotaExceeded will be returned from the writes of a Region when its quota is exceeded and can not make room for them
+type ErrQuotaExceeded struct {
+	Region   []string
+	MaxKeys  int64
+	MaxBytes int64
+	Keys     int64   // the usage if the write is applied
+	Bytes    int64   // the usage if the write is applied
+}
+
+func (e *ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded for region [%s]: keys %d/%d, bytes %d/%d",
+		strings.Join(e.Region, ","), e.Keys, e.MaxKeys, e.Bytes, e.MaxBytes)
+}
+
+/*
+  the quota and usage of a region are stored in the info records of it:
+
+  -- info key --------------       -- val --
+  quota                            maxKeys | maxBytes | policy
+  usage                            keys | bytes
+  qs: key                          seq | expiresAt | size      the tracking record of each key
+  qo: seq key                      -                           the index of keys by writing order
+  qe: expiresAt key                -                           the index of keys by expiration, only for keys with ttl
+
+  seq and expiresAt are unix nano in big endian, so the keys can be iterated in order.
+
+  the keys expired are reclaimed from usage only when the quota is exceeded.
+
+  the info keys of ItemRegion are prefixed with 'items:', since it shares the info records with the Region of same keys.
+*/
+var (
+	__q_cfg   = []byte("quota")
+	__q_usage = []byte("usage")
+	__q_track = "qs:"
+	__q_old   = "qo:"
+	__q_exp   = "qe:"
+	__q_item  = "items:"
+)
+
+// the max count of keys evicted in one round
+const __q_evict_batch = 64
+
+var errStopIter = errors.New("stop iterating")
+
+type quota struct {
+	mu       sync.Mutex          // serializes the writes of region in process
+	meta     rMeta
+	maxKeys  int64
+	maxBytes int64
+	policy   QuotaPolicy
+	db       *db                 // the db maintaining the usage for the region
+
+	qpre     []byte              // prefix of quota info records
+	tpre     []byte              // prefix of tracking records
+	opre     []byte              // prefix of writing order index
+	epre     []byte              // prefix of expiration index
+}
+
+func __catBytes(bs ...[]byte) []byte {
+	return bytes.Join(bs, nil)
+}
+
+func __u64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// __qpre returns the prefix of the quota info records of region
+func (m *rMeta)__qpre() []byte {
+	if m.item {
+		return __catBytes(m.ipre, []byte(__q_item))
+	}
+	return m.ipre
+}
+
+func newQuota(base *db, m rMeta, maxKeys, maxBytes int64, policy QuotaPolicy) *quota {
+	q := &quota{meta: m, maxKeys: maxKeys, maxBytes: maxBytes, policy: policy}
+	q.qpre = m.__qpre()
+	q.tpre = __catBytes(q.qpre, []byte(__q_track))
+	q.opre = __catBytes(q.qpre, []byte(__q_old))
+	q.epre = __catBytes(q.qpre, []byte(__q_exp))
+	q.db   = &db{dsn: base.dsn, db: &quotaDB{DB: base.db, q: q}, live: base.live, quotas: base.quotas}
+	return q
+}
+
+func (q *quota)marshal() []byte {
+	return __catBytes(__u64(uint64(q.maxKeys)), __u64(uint64(q.maxBytes)), []byte{byte(q.policy)})
+}
+
+func (q *quota)exceeded(keys, bytes int64) bool {
+	return (q.maxKeys > 0 && keys > q.maxKeys) || (q.maxBytes > 0 && bytes > q.maxBytes)
+}
+
+// quotas caches the quotas of regions in a db, the key is the kpre of region
+type quotas struct {
+	m sync.Map
+}
+
+// quotaOf returns the quota of the region, returns nil if it has no quota,
+// the result is cached including no quota, so the quota set by others outside this db will not be found
+func (db *db)quotaOf(m *rMeta) (*quota, error) {
+	if v, ok := db.quotas.m.Load(string(m.kpre)); ok {
+		return v.(*quota), nil
+	}
+
+	var q *quota
+	if err := db.db.View(func(tx driver.TX) error {
+		raw, _, err := tx.Get(m.__qpre(), __q_cfg)
+		if err != nil || len(raw) != 17 {
+			return err
+		}
+		q = newQuota(db, *m, int64(binary.BigEndian.Uint64(raw)), int64(binary.BigEndian.Uint64(raw[8:])), QuotaPolicy(raw[16]))
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("read quota failed: %w", err)
+	}
+
+	v, _ := db.quotas.m.LoadOrStore(string(m.kpre), q)
+	return v.(*quota), nil
+}
+
+// quotaDB wraps driver.DB to maintain the usage of a region in every Update
+type quotaDB struct {
+	driver.DB
+	q *quota
+}
+
+func (d *quotaDB)Update(fn func(tx driver.TX) error) error {
+	d.q.mu.Lock()
+	defer d.q.mu.Unlock()
+
+	return d.DB.Update(func(tx driver.TX) error {
+		qt := &quotaTX{TX: tx, q: d.q, now: time.Now()}
+		if err := qt.loadUsage(); err != nil {
+			return err
+		}
+		if err := fn(qt); err != nil {
+			return err
+		}
+		if qt.dirty {
+			return qt.saveUsage()
+		}
+		return nil
+	})
+}
+
+// View reads the records without the usage, the keys deleted by Get must go through Update
+func (d *quotaDB)View(fn func(tx driver.TX) error) error {
+	return d.DB.View(fn)
+}
+
+func (d *quotaDB)DropPrefix(prefix []byte) error {
+	if !bytes.Equal(prefix, d.q.meta.kpre) {
+		return d.DB.DropPrefix(prefix)
+	}
+
+	d.q.mu.Lock()
+	defer d.q.mu.Unlock()
+
+	for _, pre := range [][]byte{prefix, d.q.tpre, d.q.opre, d.q.epre} {
+		if err := d.DB.DropPrefix(pre); err != nil {
+			return err
+		}
+	}
+	return d.DB.Update(func(tx driver.TX) error {
+		return tx.Del(d.q.qpre, __q_usage)
+	})
+}
+
+// quotaTX wraps driver.TX to maintain the usage of the records in region
+type quotaTX struct {
+	driver.TX
+	q     *quota
+	now   time.Time
+	keys  int64
+	bytes int64
+	dirty bool
+}
+
+func (t *quotaTX)loadUsage() error {
+	raw, _, err := t.TX.Get(t.q.qpre, __q_usage)
+	if err != nil {
+		return err
+	}
+	if len(raw) == 16 {
+		t.keys  = int64(binary.BigEndian.Uint64(raw))
+		t.bytes = int64(binary.BigEndian.Uint64(raw[8:]))
+	}
+	return nil
+}
+
+func (t *quotaTX)saveUsage() error {
+	return t.TX.Set(t.q.qpre, __q_usage, __catBytes(__u64(uint64(t.keys)), __u64(uint64(t.bytes))))
+}
+
+// track adds the tracking records of key
+func (t *quotaTX)track(key []byte, size int64, seq uint64, expiresAt uint64) error {
+	if err := t.TX.Set(t.q.tpre, key, __catBytes(__u64(seq), __u64(expiresAt), __u64(uint64(size)))); err != nil {
+		return err
+	}
+	if err := t.TX.Set(t.q.opre, __catBytes(__u64(seq), key), []byte{0}); err != nil {
+		return err
+	}
+	if expiresAt > 0 {
+		if err := t.TX.Set(t.q.epre, __catBytes(__u64(expiresAt), key), []byte{0}); err != nil {
+			return err
+		}
+	}
+
+	t.keys  += 1
+	t.bytes += size
+	t.dirty  = true
+	return nil
+}
+
+// untrack removes the tracking records of key if exist
+func (t *quotaTX)untrack(key []byte) error {
+	raw, _, err := t.TX.Get(t.q.tpre, key, true)
+	if err != nil || len(raw) != 24 {
+		return err
+	}
+
+	if err = t.TX.Del(t.q.opre, __catBytes(raw[:8], key)); err != nil {
+		return err
+	}
+	if binary.BigEndian.Uint64(raw[8:]) > 0 {
+		if err = t.TX.Del(t.q.epre, __catBytes(raw[8:16], key)); err != nil {
+			return err
+		}
+	}
+
+	t.keys  -= 1
+	t.bytes -= int64(binary.BigEndian.Uint64(raw[16:]))
+	t.dirty  = true
+	return nil
+}
+
+// __collect returns at most n keys from the index in order, the keys are trimmed the 8 bytes order field,
+// stop(order) returns true to stop collecting
+func (t *quotaTX)__collect(pre []byte, n int, stop func(order uint64) bool) (keys [][]byte, err error) {
+	err = t.TX.Iterate(pre, func(_ int, key []byte, _ []byte, _ uint64) error {
+		if len(key) < 8 {
+			return nil
+		}
+		if stop != nil && stop(binary.BigEndian.Uint64(key)) {
+			return errStopIter
+		}
+		keys = append(keys, append([]byte{}, key[8:]...))
+		if len(keys) >= n {
+			return errStopIter
+		}
+		return nil
+	})
+	if err == errStopIter {
+		err = nil
+	}
+	return
+}
+
+// __evict deletes the records and their tracking records, and the index entries if it is the quota of ItemRegion
+func (t *quotaTX)__evict(keys [][]byte) error {
+	for _, k := range keys {
+		if t.q.meta.item {
+			if err := t.q.meta.__unindex(t.TX, k); err != nil {
+				return err
+			}
+		}
+		if err := t.TX.Del(t.q.meta.kpre, k); err != nil {
+			return err
+		}
+		if err := t.untrack(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensure makes room for adding keys and size, returns *ErrQuotaExceeded if it can not
+func (t *quotaTX)ensure(keys int64, size int64) error {
+	if !t.q.exceeded(t.keys + keys, t.bytes + size) {
+		return nil
+	}
+
+	exceeded := func() error {
+		return &ErrQuotaExceeded{Region: t.q.meta.keys, MaxKeys: t.q.maxKeys, MaxBytes: t.q.maxBytes, Keys: t.keys + keys, Bytes: t.bytes + size}
+	}
+	if t.q.exceeded(keys, size) {
+		return exceeded()
+	}
+
+	// reclaim the expired keys first
+	now := uint64(t.now.UnixNano())
+	for t.q.exceeded(t.keys + keys, t.bytes + size) {
+		ks, err := t.__collect(t.q.epre, __q_evict_batch, func(exp uint64) bool { return exp > now })
+		if err != nil {
+			return err
+		}
+		if len(ks) == 0 {
+			break
+		}
+		if err = t.__evict(ks); err != nil {
+			return err
+		}
+	}
+
+	for t.q.exceeded(t.keys + keys, t.bytes + size) {
+		var ks [][]byte
+		var err error
+		switch t.q.policy {
+		case QUOTA_EVICT_OLDEST:
+			ks, err = t.__collect(t.q.opre, __q_evict_batch, nil)
+		case QUOTA_EVICT_NEAREST_EXPIRY:
+			if ks, err = t.__collect(t.q.epre, __q_evict_batch, nil); err == nil && len(ks) == 0 {
+				ks, err = t.__collect(t.q.opre, __q_evict_batch, nil)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(ks) == 0 {
+			return exceeded()
+		}
+
+		// evict one by one to keep as many keys as possible
+		for _, k := range ks {
+			if err = t.__evict([][]byte{k}); err != nil {
+				return err
+			}
+			if !t.q.exceeded(t.keys + keys, t.bytes + size) {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *quotaTX)Set(prefix []byte, key []byte, val []byte, ttl ...time.Duration) error {
+	if !bytes.Equal(prefix, t.q.meta.kpre) {
+		return t.TX.Set(prefix, key, val, ttl...)
+	}
+
+	if err := t.untrack(key); err != nil {
+		return err
+	}
+
+	size := int64(len(key) + len(val))
+	if err := t.ensure(1, size); err != nil {
+		return err
+	}
+	if err := t.TX.Set(prefix, key, val, ttl...); err != nil {
+		return err
+	}
+
+	expiresAt := uint64(0)
+	if len(ttl) > 0 && ttl[0] > 0 {
+		expiresAt = uint64(t.now.Add(ttl[0]).UnixNano())
+	}
+	return t.track(key, size, uint64(time.Now().UnixNano()), expiresAt)
+}
+
+func (t *quotaTX)Get(prefix []byte, key []byte, del ...bool) ([]byte, uint64, error) {
+	val, expiresAt, err := t.TX.Get(prefix, key, del...)
+	if err != nil || !(len(del) > 0 && del[0]) || !bytes.Equal(prefix, t.q.meta.kpre) {
+		return val, expiresAt, err
+	}
+
+	return val, expiresAt, t.untrack(key)
+}
+
+func (t *quotaTX)Del(prefix []byte, key []byte) error {
+	if err := t.TX.Del(prefix, key); err != nil {
+		return err
+	}
+	if !bytes.Equal(prefix, t.q.meta.kpre) {
+		return nil
+	}
+
+	return t.untrack(key)
+}
+
+// setQuota sets the quota of region, the usage will be rebuilt by scanning the records if the region has no quota before,
+// the quota will be removed if both maxKeys and maxBytes <= 0
+func (db *db)setQuota(m *rMeta, maxKeys, maxBytes int64, policy QuotaPolicy) error {
+	if policy < QUOTA_REJECT || policy > QUOTA_EVICT_NEAREST_EXPIRY {
+		return fmt.Errorf("invalid quota policy: %s", policy)
+	}
+	if maxKeys < 0 {
+		maxKeys = 0
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+
+	old, err := db.quotaOf(m)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		old.mu.Lock()
+		defer old.mu.Unlock()
+	}
+
+	// remove the quota
+	if maxKeys == 0 && maxBytes == 0 {
+		if old == nil {
+			return nil
+		}
+		for _, pre := range [][]byte{old.tpre, old.opre, old.epre} {
+			if err := db.db.DropPrefix(pre); err != nil {
+				return err
+			}
+		}
+		if err := db.db.Update(func(tx driver.TX) error {
+			if err := tx.Del(old.qpre, __q_usage); err != nil {
+				return err
+			}
+			return tx.Del(old.qpre, __q_cfg)
+		}); err != nil {
+			return err
+		}
+
+		db.quotas.m.Store(string(m.kpre), (*quota)(nil))
+		return nil
+	}
+
+	q := old
+	if q == nil {
+		q = newQuota(db, *m, maxKeys, maxBytes, policy)
+		if err := db.__rebuildUsage(q); err != nil {
+			return err
+		}
+	}
+
+	cfg := newQuota(db, *m, maxKeys, maxBytes, policy).marshal()
+	if err := db.db.Update(func(tx driver.TX) error {
+		return tx.Set(q.qpre, __q_cfg, cfg)
+	}); err != nil {
+		return err
+	}
+
+	// the writers are blocked by old.mu if it exists
+	q.maxKeys, q.maxBytes, q.policy = maxKeys, maxBytes, policy
+	db.quotas.m.Store(string(m.kpre), q)
+	return nil
+}
+
+// __rebuildUsage rebuilds the tracking records and usage of region by scanning its records,
+// the existing records are considered older than the ones written later in the key order
+func (db *db)__rebuildUsage(q *quota) error {
+	for _, pre := range [][]byte{q.tpre, q.opre, q.epre} {
+		if err := db.db.DropPrefix(pre); err != nil {
+			return err
+		}
+	}
+
+	type rec struct {
+		key       []byte
+		size      int64
+		expiresAt uint64
+	}
+	var recs []rec
+	if err := db.db.View(func(tx driver.TX) error {
+		return tx.Iterate(q.meta.kpre, func(_ int, key []byte, val []byte, expiresAt uint64) error {
+			recs = append(recs, rec{key: append([]byte{}, key...), size: int64(len(key) + len(val)), expiresAt: expiresAt * uint64(time.Second)})
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	var keys, size int64
+	i := 0
+	for {
+		if err := db.db.Update(func(tx driver.TX) error {
+			t := &quotaTX{TX: tx, q: q, keys: keys, bytes: size}
+			for cnt := 0; i < len(recs) && cnt < 1000; i, cnt = i + 1, cnt + 1 {
+				if err := t.track(recs[i].key, recs[i].size, uint64(i + 1), recs[i].expiresAt); err != nil {
+					return err
+				}
+			}
+			keys, size = t.keys, t.bytes
+			return t.saveUsage()
+		}); err != nil {
+			return err
+		}
+
+		if i >= len(recs) {
+			return nil
+		}
+	}
+}
+
+// usage returns the usage of region, it is read from the info records if the region has a quota,
+// or else it is counted by scanning the records
+func (db *db)usage(m *rMeta) (keys int64, size int64, err error) {
+	q, err := db.quotaOf(m)
+	if err != nil {
+		return
+	}
+	if q != nil {
+		err = db.db.View(func(tx driver.TX) error {
+			t := &quotaTX{TX: tx, q: q}
+			err := t.loadUsage()
+			keys, size = t.keys, t.bytes
+			return err
+		})
+		return
+	}
+
+	err = db.db.View(func(tx driver.TX) error {
+		return tx.Iterate(m.kpre, func(_ int, key []byte, val []byte, _ uint64) error {
+			keys += 1
+			size += int64(len(key) + len(val))
+			return nil
+		})
+	})
+	return
+}
@@ -0,0 +1,34 @@
+package ecache
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+// the sentinel errors of ecache, the errors returned are wrapped with more details and stack traces(see eerr),
+// check them by errors.Is, like:
+//   if errors.Is(err, ecache.ErrNotFound) { ... }
+var (
+	ErrNotFound       = driver.ErrNotFound        // the key, cache or index can not be found
+	ErrClosed         = driver.ErrClosed          // the DBCache is closed
+	ErrInvalidDsn     = driver.ErrInvalidDsn      // the dsn or the params in it are invalid
+	ErrDriverNotFound = driver.ErrDriverNotFound  // the driver in dsn is not registered
+	ErrTypeMismatch   = errors.New("type mismatch")
+)
+
+// TypeMismatchError is returned when a Val or a cache is used as another type, it matches ErrTypeMismatch by errors.Is,
+// and can be got by errors.As to find out the types
+type TypeMismatchError struct {
+	Want string
+	Got  string
+}
+
+func (e *TypeMismatchError)Error() string {
+	return fmt.Sprintf("invalid type %s, want %s", e.Got, e.Want)
+}
+
+func (e *TypeMismatchError)Is(target error) bool {
+	return target == ErrTypeMismatch
+}
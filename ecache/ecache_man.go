@@ -2,8 +2,11 @@ package ecache
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 
+	"github.com/ziyht/eden_go/eerr"
 	"github.com/ziyht/eden_go/elog"
 )
 
@@ -13,6 +16,10 @@ var (
   dbCacheCfgs    = make(map[string]*Cfg)
   dbCachesMu     = &sync.RWMutex{}
 	dbCacheRootKey = "ecache"
+	memCaches       = make(map[string]any)
+	memCacheCfgs    = make(map[string]*MemCfg)
+	memCachesMu     = &sync.RWMutex{}
+	memCacheRootKey = "ecache_mem"
 	log            = elog.Log(elog.Opt().Filename("ecache"))
 )
 
@@ -42,6 +49,190 @@ func initFromFile(path string) error {
 
 		dbCacheCfgs[name] = cfg
 	}
+
+	return initMemCfgsFromFile(path)
+}
+
+func initMemCfgsFromFile(path string) error {
+	memCachesMu.Lock()
+	defer memCachesMu.Unlock()
+
+	cfgs, err := memCfgsFromFile(path, memCacheRootKey)
+	if err != nil {
+		return err
+	}
+
+	for name, cfg := range cfgs.Cfgs {
+		fdcfg   := memCacheCfgs[name]
+		fdcache := memCaches[name]
+
+		if fdcache != nil {
+			if !fdcfg.equal(cfg) {
+				return fmt.Errorf("the old memcache's cfg mismatch the new one set in file %s in %s.%s: %+v != %+v", path, memCacheRootKey, name, *cfg, *fdcfg)
+			}
+			continue
+		}
+
+		if fdcfg != nil && !fdcfg.equal(cfg) {
+			log.Warnf("the old memcache cfg from file '%s' overwriten by new cfg from file '%s' for key '%s'", fdcfg.cfgFile, cfg.cfgFile, name)
+		}
+
+		memCacheCfgs[name] = cfg
+	}
+	return nil
+}
+
+// reloadFromFile reloads the cfgs of dbcaches and memcaches from file and applies the changes to the caches which declared in it:
+//   1. the caches newly declared will be opened
+//   2. the caches removed from file will be closed after the in-flight operations on them finished
+//   3. the caches with changed DSN will be reopened in place, so the handles returned before keep working
+//   4. the memcaches are reloaded by reloadMemFromFile
+func reloadFromFile(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	cfgs, err := cfgsFromFile(path, dbCacheRootKey)
+	if err != nil {
+		return err
+	}
+
+	type reopenTask struct {
+		name string
+		c    *DBCache
+		dsn  string
+	}
+
+	var (
+		toClose  = map[string]*DBCache{}
+		toReopen []reopenTask
+		errs     []string
+	)
+
+	dbCachesMu.Lock()
+	for name, cfg := range dbCacheCfgs {
+		if cfg.cfgFile != path {
+			continue
+		}
+		if _, ok := cfgs.Cfgs[name]; ok {
+			continue
+		}
+
+		delete(dbCacheCfgs, name)
+		if c := dbCaches[name]; c != nil {
+			delete(dbCaches, name)
+			toClose[name] = c
+		}
+	}
+
+	for name, cfg := range cfgs.Cfgs {
+		fdcfg := dbCacheCfgs[name]
+		c     := dbCaches[name]
+		dbCacheCfgs[name] = cfg
+
+		if c != nil {
+			if fdcfg.Dsn != cfg.Dsn {
+				toReopen = append(toReopen, reopenTask{name: name, c: c, dsn: cfg.Dsn})
+			}
+			continue
+		}
+
+		if fdcfg != nil {
+			continue
+		}
+
+		c, err := NewDBCache(DBCacheOpts{Dsn: cfg.Dsn})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("NewDBCache for '%s' failed: %s, dsn is: %s", name, err, cfg.Dsn))
+			continue
+		}
+		dbCaches[name] = c
+		log.Infof("dbcache '%s' opened with DSN '%s' from file '%s'", name, cfg.Dsn, path)
+	}
+	dbCachesMu.Unlock()
+
+	for name, c := range toClose {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("close dbcache '%s' failed: %s", name, err))
+			continue
+		}
+		log.Infof("dbcache '%s' closed since it was removed from file '%s'", name, path)
+	}
+
+	for _, t := range toReopen {
+		if err := t.c.db.reopen(t.dsn); err != nil {
+			errs = append(errs, fmt.Sprintf("reopen dbcache '%s' with DSN '%s' failed: %s", t.name, t.dsn, err))
+			continue
+		}
+		log.Infof("dbcache '%s' reopened with DSN '%s' from file '%s'", t.name, t.dsn, path)
+	}
+
+	if err := reloadMemFromFile(path); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("reload from file '%s' failed: %s", path, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// memCacheReloader is implemented by *MemCache[K, V] of any types stored in memCaches
+type memCacheReloader interface {
+	reload(cfg *MemCfg)
+	Close()
+}
+
+// reloadMemFromFile reloads the cfgs of memcaches from file and applies the changes to the caches which declared in it:
+//   1. the caches removed from file will be closed
+//   2. the caches with changed cfg will be updated in place, except CountersNum which can not be changed after created
+func reloadMemFromFile(path string) error {
+	cfgs, err := memCfgsFromFile(path, memCacheRootKey)
+	if err != nil {
+		return err
+	}
+
+	var toClose = map[string]memCacheReloader{}
+
+	memCachesMu.Lock()
+	for name, cfg := range memCacheCfgs {
+		if cfg.cfgFile != path {
+			continue
+		}
+		if _, ok := cfgs.Cfgs[name]; ok {
+			continue
+		}
+
+		delete(memCacheCfgs, name)
+		if c := memCaches[name]; c != nil {
+			delete(memCaches, name)
+			toClose[name] = c.(memCacheReloader)
+		}
+	}
+
+	for name, cfg := range cfgs.Cfgs {
+		fdcfg := memCacheCfgs[name]
+		memCacheCfgs[name] = cfg
+
+		c := memCaches[name]
+		if c == nil || fdcfg.equal(cfg) {
+			continue
+		}
+		if fdcfg.CountersNum != cfg.CountersNum {
+			log.Warnf("CountersNum of memcache '%s' can not be changed after created, the new value %d from file '%s' is ignored", name, cfg.CountersNum, path)
+		}
+		c.(memCacheReloader).reload(cfg)
+		log.Infof("memcache '%s' reloaded with cfg %+v from file '%s'", name, *cfg, path)
+	}
+	memCachesMu.Unlock()
+
+	for name, c := range toClose {
+		c.Close()
+		log.Infof("memcache '%s' closed since it was removed from file '%s'", name, path)
+	}
+
 	return nil
 }
 
@@ -63,7 +254,7 @@ func getDBCache(name string)(*DBCache, error){
 
 	cfg := dbCacheCfgs[name]
 	if cfg == nil {
-		return nil, fmt.Errorf("DBCache %s not found", name)
+		return nil, eerr.Errorf("DBCache %s %w", name, ErrNotFound)
 	}
 
 	c := dbCaches[name]
@@ -71,11 +262,37 @@ func getDBCache(name string)(*DBCache, error){
 		return c, nil
 	}
 
-	c, err := NewDBCache(DBCacheOpts{Dsn: dfCfg().Dsn} )
+	c, err := NewDBCache(DBCacheOpts{Dsn: cfg.Dsn} )
 	if err != nil {
-		return nil, fmt.Errorf("NewDBCache for '%s' failed: %s, dsn is: %s", name, err, cfg.Dsn)
+		return nil, fmt.Errorf("NewDBCache for '%s' failed: %w, dsn is: %s", name, err, cfg.Dsn)
 	}
 
 	dbCaches[name] = c
 	return c, nil
+}
+
+func getMemCache[K Key, V any](name string)(*MemCache[K, V], error){
+	memCachesMu.Lock()
+	defer memCachesMu.Unlock()
+
+	cfg := memCacheCfgs[name]
+	if cfg == nil {
+		return nil, eerr.Errorf("MemCache %s %w", name, ErrNotFound)
+	}
+
+	if fd := memCaches[name]; fd != nil {
+		c, ok := fd.(*MemCache[K, V])
+		if !ok {
+			return nil, eerr.Errorf("MemCache %s already created with another type %T: %w", name, fd, ErrTypeMismatch)
+		}
+		return c, nil
+	}
+
+	c := newMemCache[K, V](memCacheOptsFromCfg[V](cfg))
+	if c == nil {
+		return nil, fmt.Errorf("newMemCache for '%s' failed, cfg is: %+v", name, *cfg)
+	}
+
+	memCaches[name] = c
+	return c, nil
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package ecache
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// CfgWatcher watches a config file and reloads the dbcaches declared in it when the file changes
+type CfgWatcher struct {
+	path string
+	w    *fsnotify.Watcher
+	quit chan struct{}
+	done chan struct{}
+}
+
+// the changes in this duration will be merged into one reloading
+const cfgWatchDebounce = time.Millisecond * 100
+
+func watchConfigFile(path string) (*CfgWatcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = reloadFromFile(path); err != nil {
+		return nil, err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create watcher failed: %s", err)
+	}
+
+	// watching the dir instead of the file, for the editors may replace the file by renaming
+	if err = w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("watch dir of '%s' failed: %s", path, err)
+	}
+
+	cw := &CfgWatcher{
+		path: path,
+		w   : w,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go cw.loop()
+
+	return cw, nil
+}
+
+func (cw *CfgWatcher) loop() {
+	defer close(cw.done)
+
+	debounce := time.NewTimer(cfgWatchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case ev, ok := <-cw.w.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) != cw.path {
+				continue
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			debounce.Reset(cfgWatchDebounce)
+
+		case <-debounce.C:
+			if err := reloadFromFile(cw.path); err != nil {
+				log.Errorf("%s", err)
+			}
+
+		case err, ok := <-cw.w.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("watching '%s' failed: %s", cw.path, err)
+
+		case <-cw.quit:
+			return
+		}
+	}
+}
+
+// Path returns the absolute path of the watching file
+func (cw *CfgWatcher) Path() string {
+	return cw.path
+}
+
+// Close stops watching, the caches opened will not be closed
+func (cw *CfgWatcher) Close() error {
+	select {
+	case <-cw.quit:
+		return nil
+	default:
+	}
+
+	close(cw.quit)
+	err := cw.w.Close()
+	<-cw.done
+	return err
+}
@@ -2,6 +2,7 @@ package ecache
 
 import (
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -15,13 +16,38 @@ type Metrics = ristretto.Metrics
 
 type MemCache[K Key, V any] struct {
   c                *ristretto.Cache[K, V]
-	reRentSkipThresh time.Duration      // default = MaxTTL * 0.8
-	reRentTTL        time.Duration      // default = MaxTTL
+	ttl              atomic.Pointer[memCacheTTL]
+	mu               sync.RWMutex       // protects opts, which can be changed by reload
 	opts             MemCacheOpts[V]
 	add              atomic.Int32
 	Metrics 				 *Metrics
 }
 
+// memCacheTTL is the ttl settings used in Set and Get, it is replaced as a whole when reloaded
+type memCacheTTL struct {
+	dfTTL            time.Duration
+	maxTTL           time.Duration
+	autoReRent       bool
+	reRentSkipThresh time.Duration      // default = MaxTTL * 0.8
+	reRentTTL        time.Duration      // default = MaxTTL
+}
+
+func newMemCacheTTL(dfTTL, maxTTL time.Duration, autoReRent bool) *memCacheTTL {
+	t := &memCacheTTL{dfTTL: dfTTL, maxTTL: maxTTL, autoReRent: autoReRent}
+	if maxTTL > 0 {
+		if t.dfTTL > maxTTL {
+			t.dfTTL = maxTTL
+		}
+		t.reRentSkipThresh = time.Duration(float64(maxTTL) * 0.8)
+		t.reRentTTL = maxTTL
+	}
+	if t.dfTTL > 0 {
+		t.reRentSkipThresh = time.Duration(float64(t.dfTTL) * 0.8)
+		t.reRentTTL = t.dfTTL
+	}
+	return t
+}
+
 type MemCacheOpts[T any] struct {
 	MaxCost            int64             // default:   10M, each item has a cost of memory, this value defines the max cost of current cache instance
 	DfTTL              time.Duration     // default:     0, the default TTL of items when not passed ttl in params in Set functions, 0 means the item will never expired by TTL policy, it will be limited by MaxTTL.
@@ -82,18 +108,7 @@ func newMemCache[K Key, V any](opts MemCacheOpts[V]) *MemCache[K, V] {
 	c := &MemCache[K, V]{
 		opts : opts,
 	}
-
-	if opts.MaxTTL > 0 {
-		if opts.DfTTL > opts.MaxTTL {
-			opts.DfTTL = opts.MaxTTL
-		}
-		c.reRentSkipThresh = time.Duration(float64(opts.MaxTTL) * 0.8)
-		c.reRentTTL = opts.MaxTTL
-	}
-	if opts.DfTTL > 0 {
-		c.reRentSkipThresh = time.Duration(float64(opts.DfTTL) * 0.8)
-		c.reRentTTL = opts.DfTTL
-	}
+	c.ttl.Store(newMemCacheTTL(opts.DfTTL, opts.MaxTTL, opts.AutoReRent))
 
 	config := &ristretto.Config[K, V]{
 		NumCounters       : opts.CountersNum,
@@ -123,7 +138,8 @@ func (c *MemCache[K, V])Wait() {
 }
 
 func (c *MemCache[K, V])__validate_ttl(ttl ...time.Duration) time.Duration {
-	ttl_to_set := c.opts.DfTTL
+	t := c.ttl.Load()
+	ttl_to_set := t.dfTTL
 	if len(ttl) > 0 {
 		ttl_to_set = ttl[0]
 
@@ -133,8 +149,8 @@ func (c *MemCache[K, V])__validate_ttl(ttl ...time.Duration) time.Duration {
 	}
 
 	// limited to max_ttl
-	if c.opts.MaxTTL > 0 && (c.opts.MaxTTL < ttl_to_set || ttl_to_set == 0) {
-		ttl_to_set = c.opts.MaxTTL
+	if t.maxTTL > 0 && (t.maxTTL < ttl_to_set || ttl_to_set == 0) {
+		ttl_to_set = t.maxTTL
 	}
 
 	return ttl_to_set
@@ -154,13 +170,13 @@ func (c *MemCache[K, V])__set_and_wait_if_need(key K, val V, cost int64, ttl tim
 
 // __rerent_item_if_need will rerent the iterm by checking internal policy. 
 func (c *MemCache[K, V])__rerent_item_if_need(key K, val V) {
-	if c.opts.AutoReRent && c.reRentTTL > 0 {
+	if t := c.ttl.Load(); t.autoReRent && t.reRentTTL > 0 {
 		leftTTL, ok := c.c.GetTTL(key)
-		if !ok || leftTTL >= c.reRentSkipThresh{
+		if !ok || leftTTL >= t.reRentSkipThresh{
 			return
 		}
 
-		c.__set_and_wait_if_need(key, val, 0, c.reRentTTL)
+		c.__set_and_wait_if_need(key, val, 0, t.reRentTTL)
 	}
 }
 
@@ -265,9 +281,24 @@ func (c *MemCache[K, V])Close() {
 }
 
 func (c *MemCache[K, V])Opt() MemCacheOpts[V] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.opts
 }
 
+// reload applies the cfg reloaded from file, CountersNum can not be changed after the cache created
+func (c *MemCache[K, V])reload(cfg *MemCfg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cfg.MaxCost > 0 && cfg.MaxCost != c.opts.MaxCost {
+		c.opts.MaxCost = cfg.MaxCost
+		c.c.UpdateMaxCost(cfg.MaxCost)
+	}
+	c.opts.DfTTL, c.opts.MaxTTL, c.opts.AutoReRent = cfg.DfTTL, cfg.MaxTTL, cfg.AutoReRent
+	c.ttl.Store(newMemCacheTTL(cfg.DfTTL, cfg.MaxTTL, cfg.AutoReRent))
+}
+
 func (c *MemCache[K, V])SetMaxCost(maxCost int64){
 	c.c.UpdateMaxCost(maxCost)
 }
\ No newline at end of file
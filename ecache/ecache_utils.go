@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ziyht/eden_go/eerr"
 	"github.com/ziyht/eden_go/eutils/ptr"
 )
 
@@ -12,7 +13,7 @@ func toBytesKey(key any)([]byte, error){
 	case string: return ptr.StringToBytes(k), nil
 	case []byte: return k, nil
 	}
-	return nil, fmt.Errorf("invalid key type, only support string and []byte")
+	return nil, eerr.PackSkip(1, &TypeMismatchError{Want: "string or []byte", Got: fmt.Sprintf("%T", key)})
 }
 
 func stringsToBytesArr(ks []string)([][]byte) {
@@ -30,7 +31,7 @@ func toBytesArr(key any)([][]byte, error){
 	case []string: return stringsToBytesArr(k), nil
 	case [][]byte: return k, nil
 	}
-	return nil, fmt.Errorf("only support string, []string, []byte and [][]byte")
+	return nil, eerr.PackSkip(1, &TypeMismatchError{Want: "string, []string, []byte or [][]byte", Got: fmt.Sprintf("%T", key)})
 }
 
 func toBytesKeyVal(key any, val any, ttl ...time.Duration) ([]byte, []byte, error) {
@@ -38,12 +39,12 @@ func toBytesKeyVal(key any, val any, ttl ...time.Duration) ([]byte, []byte, erro
 	switch k := key.(type) {
 		case string: key_ = ptr.StringToBytes(k)
 		case []byte: key_ = k
-		default: return nil, nil, fmt.Errorf("invalid key type, only support string and []byte")
+		default: return nil, nil, eerr.PackSkip(1, &TypeMismatchError{Want: "string or []byte", Got: fmt.Sprintf("%T", key)})
 	}
 	
 	switch v := val.(type) {
 		case string: return key_, ptr.StringToBytes(v), nil
 		case []byte: return key_, v, nil
 	}
-	return nil, nil, fmt.Errorf("invalid val type, only support string and []byte")
+	return nil, nil, eerr.PackSkip(1, &TypeMismatchError{Want: "string or []byte", Got: fmt.Sprintf("%T", val)})
 }
@@ -6,7 +6,7 @@ import (
 	"time"
 	"unsafe"
 
-	"github.com/cockroachdb/errors"
+	"github.com/ziyht/eden_go/eerr"
 	"github.com/ziyht/eden_go/eutils/ptr"
 )
 
@@ -69,9 +69,14 @@ func recycleVal(v *Val) {
 type Val struct {
   meta   [4]byte
   d      []byte
+  err    error     // the error set by setError, it is matched by errors.Is in __err
 }
 
 func (d *Val)unmarshal(b []byte){
+  if b == nil {
+    d.setError(ErrNotFound)
+    return
+  }
   if len(b) < 4 {
     d.setErr("invalid input data, must be at least 4 bytes")
     return
@@ -144,7 +149,7 @@ func (r *Val)AppendInt32(v int32)error{
     r.__appendUint32(uint32(v))
     return nil
   }
-  return fmt.Errorf("invalid operation, you can not append a %v value to a Raw with type '%s'", v, r.__typeStr())
+  return eerr.Errorf("%w: you can not append a %v value to a Raw with type '%s'", ErrTypeMismatch, v, r.__typeStr())
 }
 
 func (r *Val)AppendInt64(v int64)error{
@@ -156,7 +161,7 @@ func (r *Val)AppendInt64(v int64)error{
     r.__appendUint64(uint64(v))
     return nil
   }
-  return fmt.Errorf("invalid operation, you can not append a %v value to a Raw with type '%s'", v, r.__typeStr())
+  return eerr.Errorf("%w: you can not append a %v value to a Raw with type '%s'", ErrTypeMismatch, v, r.__typeStr())
 }
 
 func (r *Val)String()string{
@@ -196,7 +201,11 @@ func (d *Val)setDuration(du time.Duration){
 }
 
 func (d *Val)setErr(err string){
-  d.__reset(VT_ERR); d.__appendBytes(ptr.StringToBytes(err))
+  d.__reset(VT_ERR); d.__appendBytes(ptr.StringToBytes(err)); d.err = nil
+}
+
+func (d *Val)setError(err error){
+  d.setErr(err.Error()); d.err = err
 }
 
 func (d *Val)Bool()(bool)  { if  d.__isType(BOOL) { return d.__Uint8 () == 1  }; return false }
@@ -214,7 +223,7 @@ func (d *Val)Str()(string) { if  d.__isType(BYTES ) { return ptr.BytesToString(d
 func (d *Val)Bytes()([]byte){ if d.__isType(BYTES ) { return d.d }; return nil }
 func (d *Val)Time()(out time.Time){ if d.__isType(TIME) { out.UnmarshalBinary(d.d) }; return}
 func (d *Val)Duration()(time.Duration){ if d.__isType(DURATION) { return time.Duration(d.__Uint64()) }; return 0}
-func (d *Val)Error()error{ if d.__isType(VT_ERR) { return errors.New(ptr.BytesToString(d.d)) }; return nil }
+func (d *Val)Error()error{ if d.__isType(VT_ERR) { return d.__err() }; return nil }
 
 func (d *Val)GetBool()(bool,  error){ if e := d.__checkType(BOOL ); e != nil {return false, e}; return d.__Uint8 () == 1, nil }
 func (d *Val)GetI8() (int8,  error){ if e := d.__checkType(I8 ); e != nil {return 0, e}; return int8 (d.__Uint8 ()), nil }
@@ -252,18 +261,37 @@ func (d *Val)__reset(t ValType){
   d.__clear()
 }
 
+// __checkType returns a *TypeMismatchError if d is not type t, or the error stored in d if it is an error Val
 func (d *Val)__checkType(t ValType) error {
   if !d.__isType(t){
-    return fmt.Errorf("invalid type %s", d.__typeStr())
+    if d.__isType(VT_ERR) {
+      return d.__err()
+    }
+    return eerr.PackSkip(1, &TypeMismatchError{Want: t.String(), Got: d.__typeStr()})
   }
   return nil
 }
 
+// __err returns the error stored in an error Val, a Val unmarshaled from a missing key returns ErrNotFound
+func (d *Val)__err() error {
+  if d.err != nil {
+    return eerr.PackSkip(2, d.err)
+  }
+  return eerr.PackSkip(2, fmt.Errorf("%s", ptr.BytesToString(d.d)))
+}
+
 func (d *Val)__typeStr() string {
-  if d.Type() >= VT_MAX{
+  return d.Type().String()
+}
+
+func (t ValType)String() string {
+  if t == VT_ERR {
+    return "error"
+  }
+  if t >= VT_MAX{
     return "(TypeOverload)"
   }
-  return type_strs[d.Type()]
+  return type_strs[t]
 }
 
 func (d *Val)__appendUint64(v uint64) {
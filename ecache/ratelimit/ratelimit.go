@@ -0,0 +1,216 @@
+/*
+  ratelimit provides token-bucket, sliding-window and GCRA limiters keyed by arbitrary strings,
+  the states of limiters are kept in a Store, which can be:
+    MemStore   : based on ecache.MemCache, for the limits in process
+    RegionStore: based on ecache.Region, for the limits persisted across restarts
+
+  a Store should not be shared between limiters, use different MemCaches or SubRegions for them.
+
+  usage:
+    l := ratelimit.NewTokenBucket(ratelimit.NewMemStore(nil), ratelimit.PerSecond(10), 20)
+    if ok, _ := l.Allow("user1"); !ok {
+      // rejected
+    }
+*/
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrExceedsLimit = errors.New("ratelimit: the requested n exceeds the limit")
+)
+
+// Rate - N events are permitted in every Per
+type Rate struct {
+	N   int64
+	Per time.Duration
+}
+
+func PerSecond(n int64) Rate { return Rate{N: n, Per: time.Second} }
+func PerMinute(n int64) Rate { return Rate{N: n, Per: time.Minute} }
+func PerHour  (n int64) Rate { return Rate{N: n, Per: time.Hour  } }
+
+// Every - one event is permitted in every d
+func Every(d time.Duration) Rate { return Rate{N: 1, Per: d} }
+
+// interval returns the duration between two events
+func (r Rate)interval() float64 {
+	return float64(r.Per) / float64(r.N)
+}
+
+func (r Rate)String() string {
+	return fmt.Sprintf("%d/%s", r.N, r.Per)
+}
+
+// Result - the status of a key after a request
+type Result struct {
+	Allowed    bool            // the request is allowed or not
+	Limit      int64           // the max quota of the key
+	Remaining  int64           // the remaining quota after this request
+	RetryAfter time.Duration   // the duration to wait before the request can be allowed, 0 if allowed
+	ResetAfter time.Duration   // the duration until the quota is fully restored
+}
+
+// Reservation - the result of Reserve
+type Reservation struct {
+	Result
+	OK    bool             // reserved or not, if not the quota is not consumed and RetryAfter is the hint to retry
+	Delay time.Duration    // the duration to wait before acting, valid only when OK
+}
+
+// algorithm defines a limiting algorithm, the state passed in may be nil or invalid(from other algorithms),
+// it should be considered as a fresh one in this case
+type algorithm interface {
+	// take tries to take n at now, the quota will only be consumed when the delay <= maxDelay,
+	// the newState returned is nil if nothing changed
+	take(state []byte, now time.Time, n int64, maxDelay time.Duration) (newState []byte, ttl time.Duration, r Reservation)
+
+	// peek returns the status at now without consuming
+	peek(state []byte, now time.Time) Result
+
+	limit() int64
+}
+
+// Limiter limits the events for every key
+type Limiter struct {
+	alg   algorithm
+	store Store
+	now   func() time.Time
+}
+
+func newLimiter(store Store, alg algorithm) *Limiter {
+	if store == nil {
+		store = NewMemStore(nil)
+	}
+	return &Limiter{alg: alg, store: store, now: time.Now}
+}
+
+// __stateTTL returns the ttl to store the state, the state can be dropped after d since it equals a fresh one,
+// it is rounded up to seconds since some drivers only support TTLs in seconds
+func __stateTTL(d time.Duration) time.Duration {
+	if d < 0 {
+		d = 0
+	}
+	return (d + time.Second * 2).Truncate(time.Second)
+}
+
+func __ceilDuration(ns float64) time.Duration {
+	if ns <= 0 {
+		return 0
+	}
+	if ns >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil(ns))
+}
+
+func (l *Limiter)reserveN(key string, n int64, maxDelay time.Duration) (r Reservation, err error) {
+	if n > l.alg.limit() {
+		return r, ErrExceedsLimit
+	}
+
+	err = l.store.Update(key, func(state []byte) ([]byte, time.Duration) {
+		var newState []byte
+		var ttl time.Duration
+		newState, ttl, r = l.alg.take(state, l.now(), n, maxDelay)
+		return newState, ttl
+	})
+
+	return
+}
+
+// Allow reports whether one event is allowed for key now
+func (l *Limiter)Allow(key string) (bool, error) {
+	r, err := l.AllowN(key, 1)
+	return r.Allowed, err
+}
+
+// AllowN reports whether n events are allowed for key now, the quota is consumed only when allowed
+func (l *Limiter)AllowN(key string, n int64) (Result, error) {
+	r, err := l.reserveN(key, n, 0)
+	return r.Result, err
+}
+
+// Reserve reserves one event for key, see ReserveN
+func (l *Limiter)Reserve(key string) (Reservation, error) {
+	return l.ReserveN(key, 1)
+}
+
+// ReserveN reserves n events for key, the quota is consumed when reserved and the caller should wait Delay before acting,
+// the sliding-window limiter can only reserve in the current and next window, OK will be false if it can not be reserved
+func (l *Limiter)ReserveN(key string, n int64) (Reservation, error) {
+	return l.reserveN(key, n, time.Duration(math.MaxInt64))
+}
+
+// Wait blocks until one event is allowed for key, see WaitN
+func (l *Limiter)Wait(ctx context.Context, key string) error {
+	return l.WaitN(ctx, key, 1)
+}
+
+// WaitN blocks until n events are allowed for key or ctx is done,
+// it returns an error at once if n can not be reserved before the deadline of ctx,
+// the quota reserved will not be returned if ctx is canceled during waiting
+func (l *Limiter)WaitN(ctx context.Context, key string, n int64) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		maxDelay := time.Duration(math.MaxInt64)
+		if dl, ok := ctx.Deadline(); ok {
+			maxDelay = time.Until(dl)
+		}
+
+		r, err := l.reserveN(key, n, maxDelay)
+		if err != nil {
+			return err
+		}
+
+		wait := r.Delay
+		if !r.OK {
+			if r.RetryAfter > maxDelay {
+				return fmt.Errorf("ratelimit: wait for %d of '%s' would exceed the context deadline", n, key)
+			}
+			wait = r.RetryAfter
+		}
+
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done(): t.Stop(); return ctx.Err()
+			case <-t.C:
+			}
+		}
+
+		if r.OK {
+			return nil
+		}
+	}
+}
+
+// Status returns the current status of key without consuming the quota
+func (l *Limiter)Status(key string) (Result, error) {
+	state, err := l.store.Get(key)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return l.alg.peek(state, l.now()), nil
+}
+
+// Remaining returns the remaining quota of key
+func (l *Limiter)Remaining(key string) (int64, error) {
+	r, err := l.Status(key)
+	return r.Remaining, err
+}
+
+// Reset clears the state of key, the quota will be fully restored
+func (l *Limiter)Reset(key string) error {
+	return l.store.Del(key)
+}
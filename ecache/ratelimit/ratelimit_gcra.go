@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// gcra - the generic cell rate algorithm, it tracks the theoretical arrival time(TAT) of the next event,
+// an event is allowed when it is not earlier than TAT - burst * interval
+//
+// state: tat(unix nano)
+type gcra struct {
+	rate  Rate
+	burst int64
+}
+
+// NewGCRA creates a GCRA limiter, it permits events at rate with at most burst events at once,
+// burst will be set to 1 if it is <= 0
+func NewGCRA(store Store, rate Rate, burst int64) *Limiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return newLimiter(store, &gcra{rate: rate, burst: burst})
+}
+
+func (a *gcra)limit() int64 {
+	return a.burst
+}
+
+func (a *gcra)__tat(state []byte, now int64) int64 {
+	if len(state) != 8 {
+		return now
+	}
+
+	tat := int64(binary.BigEndian.Uint64(state))
+	if tat < now {
+		return now
+	}
+	return tat
+}
+
+func (a *gcra)__result(tat int64, now int64) Result {
+	tolerance := a.rate.interval() * float64(a.burst)
+	remaining := math.Floor((float64(now - tat) + tolerance) / a.rate.interval())
+
+	return Result{
+		Limit     : a.burst,
+		Remaining : int64(math.Max(0, math.Min(remaining, float64(a.burst)))),
+		ResetAfter: time.Duration(tat - now),
+	}
+}
+
+func (a *gcra)take(state []byte, now_ time.Time, n int64, maxDelay time.Duration) ([]byte, time.Duration, Reservation) {
+	now       := now_.UnixNano()
+	tat       := a.__tat(state, now)
+	tolerance := a.rate.interval() * float64(a.burst)
+	newTat    := tat + int64(math.Ceil(a.rate.interval() * float64(n)))
+	allowAt   := float64(newTat) - tolerance
+
+	delay := __ceilDuration(allowAt - float64(now))
+	if delay > maxDelay {
+		r := Reservation{Result: a.__result(tat, now)}
+		r.RetryAfter = delay
+		return nil, 0, r
+	}
+
+	r := Reservation{Result: a.__result(newTat, now), OK: true, Delay: delay}
+	r.Allowed = delay == 0
+
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, uint64(newTat))
+
+	return out, __stateTTL(r.ResetAfter), r
+}
+
+func (a *gcra)peek(state []byte, now time.Time) Result {
+	return a.__result(a.__tat(state, now.UnixNano()), now.UnixNano())
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// slidingWindow - the sliding-window counter, the count in the sliding window ending at now is estimated by
+// the counts of the previous and current fixed windows:
+//   count = prev * (1 - elapsed / window) + cur
+// the events reserved in the next window are counted in next
+//
+// state: start(unix nano of current window) | prev | cur | next
+type slidingWindow struct {
+	rate Rate
+}
+
+// NewSlidingWindow creates a sliding-window limiter, it permits at most rate.N events in any window of rate.Per
+func NewSlidingWindow(store Store, rate Rate) *Limiter {
+	return newLimiter(store, &slidingWindow{rate: rate})
+}
+
+type __swState struct {
+	start int64
+	prev  int64
+	cur   int64
+	next  int64
+}
+
+func (a *slidingWindow)limit() int64 {
+	return a.rate.N
+}
+
+// __load loads the state and moves the windows to the one now in
+func (a *slidingWindow)__load(state []byte, now int64) (s __swState) {
+	w := int64(a.rate.Per)
+	if len(state) != 32 {
+		s.start = now - now % w
+		return
+	}
+
+	s.start = int64(binary.BigEndian.Uint64(state))
+	s.prev  = int64(binary.BigEndian.Uint64(state[8:]))
+	s.cur   = int64(binary.BigEndian.Uint64(state[16:]))
+	s.next  = int64(binary.BigEndian.Uint64(state[24:]))
+
+	if now < s.start {
+		return
+	}
+	switch k := (now - s.start) / w; k {
+	case 0 :
+	case 1 : s.prev, s.cur, s.next = s.cur , s.next, 0
+	case 2 : s.prev, s.cur, s.next = s.next, 0     , 0
+	default: s.prev, s.cur, s.next = 0     , 0     , 0
+	}
+	s.start += (now - s.start) / w * w
+
+	return
+}
+
+func (s *__swState)marshal() []byte {
+	out := make([]byte, 32)
+	binary.BigEndian.PutUint64(out     , uint64(s.start))
+	binary.BigEndian.PutUint64(out[8:] , uint64(s.prev))
+	binary.BigEndian.PutUint64(out[16:], uint64(s.cur))
+	binary.BigEndian.PutUint64(out[24:], uint64(s.next))
+	return out
+}
+
+func (a *slidingWindow)__count(s *__swState, now int64) float64 {
+	w := float64(a.rate.Per)
+	elapsed := math.Max(0, float64(now - s.start))
+	return float64(s.prev) * math.Max(0, 1 - elapsed / w) + float64(s.cur)
+}
+
+func (a *slidingWindow)__result(s *__swState, now int64) Result {
+	w := int64(a.rate.Per)
+
+	r := Result{
+		Limit    : a.rate.N,
+		Remaining: int64(math.Max(0, math.Floor(float64(a.rate.N) - a.__count(s, now)))),
+	}
+	switch {
+	case s.next > 0: r.ResetAfter = time.Duration(s.start + 3 * w - now)
+	case s.cur  > 0: r.ResetAfter = time.Duration(s.start + 2 * w - now)
+	case s.prev > 0: r.ResetAfter = time.Duration(s.start +     w - now)
+	}
+
+	return r
+}
+
+// __earliest returns the earliest time in the window starting at start, at which n can be added to a window
+// whose count is prev * (1 - elapsed / window) + cur, returns -1 if impossible
+func (a *slidingWindow)__earliest(start, prev, cur, n int64) int64 {
+	left := float64(a.rate.N - cur - n)
+	if left < 0 {
+		return -1
+	}
+	if prev == 0 || left >= float64(prev) {
+		return start
+	}
+
+	return start + int64(math.Ceil(float64(a.rate.Per) * (1 - left / float64(prev))))
+}
+
+func (a *slidingWindow)take(state []byte, now_ time.Time, n int64, maxDelay time.Duration) ([]byte, time.Duration, Reservation) {
+	now := now_.UnixNano()
+	w   := int64(a.rate.Per)
+	s   := a.__load(state, now)
+
+	// try current window
+	at, inNext := a.__earliest(s.start, s.prev, s.cur, n), false
+	if at < 0 {
+		// try next window
+		at, inNext = a.__earliest(s.start + w, s.cur, s.next, n), true
+	}
+	if at >= 0 && at < now {
+		at = now
+	}
+
+	delay := time.Duration(at - now)
+	if at < 0 || delay > maxDelay {
+		r := Reservation{Result: a.__result(&s, now)}
+		if at < 0 {
+			// can not be reserved in the next window, retry after the next window begins
+			r.RetryAfter = time.Duration(s.start + w - now)
+		} else {
+			r.RetryAfter = delay
+		}
+		return nil, 0, r
+	}
+
+	if inNext {
+		s.next += n
+	} else {
+		s.cur  += n
+	}
+
+	r := Reservation{Result: a.__result(&s, now), OK: true, Delay: delay}
+	r.Allowed = delay == 0
+
+	return s.marshal(), __stateTTL(r.ResetAfter), r
+}
+
+func (a *slidingWindow)peek(state []byte, now time.Time) Result {
+	s := a.__load(state, now.UnixNano())
+	return a.__result(&s, now.UnixNano())
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver"
+)
+
+// Store keeps the states of a limiter
+type Store interface {
+	// Get returns the state of key, returns nil if not exist
+	Get(key string) ([]byte, error)
+
+	// Update loads the state of key, and stores the state returned by fn with ttl atomically,
+	// nothing will be stored if fn returns nil
+	Update(key string, fn func(state []byte) ([]byte, time.Duration)) error
+
+	Del(key string) error
+}
+
+const __mem_store_locks = 64
+
+// MemStore stores the states in a MemCache, the state of a key will be reset if it is evicted by the MemCache
+type MemStore struct {
+	c    *ecache.MemCache[string, []byte]
+	seed maphash.Seed
+	mus  [__mem_store_locks]sync.Mutex
+}
+
+// NewMemStore creates a MemStore on c, a new MemCache with default opts will be created if c is nil
+func NewMemStore(c *ecache.MemCache[string, []byte]) *MemStore {
+	if c == nil {
+		c = ecache.NewMemCache[string, []byte]()
+	}
+	return &MemStore{c: c, seed: maphash.MakeSeed()}
+}
+
+func (s *MemStore)__lock(key string) *sync.Mutex {
+	return &s.mus[maphash.String(s.seed, key) % __mem_store_locks]
+}
+
+func (s *MemStore)Get(key string) ([]byte, error) {
+	v, _ := s.c.Get(key)
+	return v, nil
+}
+
+func (s *MemStore)Update(key string, fn func(state []byte) ([]byte, time.Duration)) error {
+	mu := s.__lock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	v, _ := s.c.Get(key)
+	n, ttl := fn(v)
+	if n == nil {
+		return nil
+	}
+
+	if !s.c.SetSync(key, n, ttl) {
+		return fmt.Errorf("ratelimit: the state of '%s' is dropped by MemCache", key)
+	}
+	return nil
+}
+
+func (s *MemStore)Del(key string) error {
+	mu := s.__lock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	s.c.Del(key)
+	s.c.Wait()
+	return nil
+}
+
+// the max times to retry when the driver returns driver.ErrTxConflict
+const __region_store_retries = 16
+
+// RegionStore stores the states in a Region, the states will be persisted across restarts
+type RegionStore struct {
+	r *ecache.Region
+}
+
+func NewRegionStore(r *ecache.Region) *RegionStore {
+	return &RegionStore{r: r}
+}
+
+func (s *RegionStore)Get(key string) ([]byte, error) {
+	v, err := s.r.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return v.Bytes(), nil
+}
+
+func (s *RegionStore)Update(key string, fn func(state []byte) ([]byte, time.Duration)) (err error) {
+	for i := 0; i < __region_store_retries; i++ {
+		err = s.r.Update(key, func(old ecache.Val) (any, time.Duration, error) {
+			n, ttl := fn(old.Bytes())
+			if n == nil {
+				return nil, 0, errSkip
+			}
+			return n, ttl, nil
+		})
+
+		if errors.Is(err, errSkip) {
+			return nil
+		}
+		if !errors.Is(err, driver.ErrTxConflict) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *RegionStore)Del(key string) error {
+	return s.r.Del(key)
+}
+
+// errSkip is used to abort the transaction when nothing changed
+var errSkip = errors.New("skip")
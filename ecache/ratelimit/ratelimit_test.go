@@ -0,0 +1,224 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+)
+
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock)now() time.Time         { c.mu.Lock(); defer c.mu.Unlock(); return c.t }
+func (c *fakeClock)add(d time.Duration)    { c.mu.Lock(); defer c.mu.Unlock(); c.t = c.t.Add(d) }
+
+func withClock(l *Limiter) (*Limiter, *fakeClock) {
+	c := &fakeClock{t: time.Unix(1700000000, 0)}
+	l.now = c.now
+	return l, c
+}
+
+func newRegionStore(t *testing.T, name string) (*RegionStore, func()) {
+	dir := "test_data/" + name
+	os.RemoveAll(dir)
+
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "bbolt:" + dir + "/ecache.db"})
+	assert.Equal(t, nil, err)
+
+	return NewRegionStore(c.NewRegion(name)), func() { c.Close(); os.RemoveAll("test_data") }
+}
+
+func execForStores(t *testing.T, fn func(t *testing.T, s Store)) {
+	fn(t, NewMemStore(nil))
+
+	s, done := newRegionStore(t, t.Name())
+	defer done()
+	fn(t, s)
+}
+
+func TestTokenBucket(t *testing.T) {
+	execForStores(t, func(t *testing.T, s Store) {
+		l, clk := withClock(NewTokenBucket(s, PerSecond(10), 5))
+
+		for i := 0; i < 5; i++ {
+			r, err := l.AllowN("k", 1)
+			assert.Equal(t, nil, err)
+			assert.True(t, r.Allowed)
+			assert.Equal(t, int64(4 - i), r.Remaining)
+		}
+		r, err := l.AllowN("k", 1)
+		assert.Equal(t, nil, err)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, time.Millisecond * 100, r.RetryAfter)
+
+		// other keys are not affected
+		ok, _ := l.Allow("k2")
+		assert.True(t, ok)
+
+		clk.add(time.Millisecond * 250)
+		n, err := l.Remaining("k")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, int64(2), n)
+
+		// reserve the future tokens
+		rv, err := l.ReserveN("k", 4)
+		assert.Equal(t, nil, err)
+		assert.True(t, rv.OK)
+		assert.Equal(t, time.Millisecond * 150, rv.Delay)
+		assert.Equal(t, int64(0), rv.Remaining)
+
+		_, err = l.AllowN("k", 6)
+		assert.Equal(t, ErrExceedsLimit, err)
+
+		assert.Equal(t, nil, l.Reset("k"))
+		n, _ = l.Remaining("k")
+		assert.Equal(t, int64(5), n)
+	})
+}
+
+func TestGCRA(t *testing.T) {
+	execForStores(t, func(t *testing.T, s Store) {
+		l, clk := withClock(NewGCRA(s, PerSecond(10), 3))
+
+		for i := 0; i < 3; i++ {
+			r, err := l.AllowN("k", 1)
+			assert.Equal(t, nil, err)
+			assert.True(t, r.Allowed)
+			assert.Equal(t, int64(2 - i), r.Remaining)
+		}
+		r, _ := l.AllowN("k", 1)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, time.Millisecond * 100, r.RetryAfter)
+		assert.Equal(t, time.Millisecond * 300, r.ResetAfter)
+
+		clk.add(time.Millisecond * 100)
+		ok, _ := l.Allow("k")
+		assert.True(t, ok)
+		ok, _ = l.Allow("k")
+		assert.False(t, ok)
+
+		rv, err := l.Reserve("k")
+		assert.Equal(t, nil, err)
+		assert.True(t, rv.OK)
+		assert.Equal(t, time.Millisecond * 100, rv.Delay)
+
+		clk.add(time.Second)
+		n, _ := l.Remaining("k")
+		assert.Equal(t, int64(3), n)
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	execForStores(t, func(t *testing.T, s Store) {
+		l, clk := withClock(NewSlidingWindow(s, PerSecond(4)))
+
+		for i := 0; i < 4; i++ {
+			r, err := l.AllowN("k", 1)
+			assert.Equal(t, nil, err)
+			assert.True(t, r.Allowed)
+			assert.Equal(t, int64(3 - i), r.Remaining)
+		}
+		r, _ := l.AllowN("k", 1)
+		assert.False(t, r.Allowed)
+		assert.Equal(t, time.Millisecond * 1250, r.RetryAfter)
+
+		// in the next window, the previous count is weighted by 3/4
+		clk.add(time.Millisecond * 1250)
+		n, _ := l.Remaining("k")
+		assert.Equal(t, int64(1), n)
+		ok, _ := l.Allow("k")
+		assert.True(t, ok)
+		ok, _ = l.Allow("k")
+		assert.False(t, ok)
+
+		// the next event can be reserved when the weight of previous window goes down to 2/4
+		rv, err := l.Reserve("k")
+		assert.Equal(t, nil, err)
+		assert.True(t, rv.OK)
+		assert.Equal(t, time.Millisecond * 250, rv.Delay)
+
+		clk.add(time.Second * 3)
+		n, _ = l.Remaining("k")
+		assert.Equal(t, int64(4), n)
+	})
+}
+
+func TestWait(t *testing.T) {
+	execForStores(t, func(t *testing.T, s Store) {
+		for _, l := range []*Limiter{
+			NewTokenBucket(s, PerSecond(20), 1),
+			NewGCRA(s, PerSecond(20), 1),
+			NewSlidingWindow(s, Rate{N: 1, Per: time.Millisecond * 50}),
+		} {
+			assert.Equal(t, nil, l.Reset("w"))
+
+			start := time.Now()
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, nil, l.Wait(context.Background(), "w"))
+			}
+			assert.True(t, time.Since(start) >= time.Millisecond * 90)
+
+			// can not be satisfied before the deadline
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 10)
+			assert.NotEqual(t, nil, l.Wait(ctx, "w"))
+			cancel()
+
+			ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+			assert.Equal(t, context.Canceled, l.Wait(ctx, "w"))
+		}
+	})
+}
+
+func TestConcurrent(t *testing.T) {
+	execForStores(t, func(t *testing.T, s Store) {
+		l := NewTokenBucket(s, Every(time.Hour), 100)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					ok, err := l.Allow("c")
+					assert.Equal(t, nil, err)
+					if ok {
+						mu.Lock(); allowed += 1; mu.Unlock()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 100, allowed)
+	})
+}
+
+func TestRegionStorePersist(t *testing.T) {
+	defer os.RemoveAll("test_data")
+	dsn := "bbolt:test_data/persist/ecache.db"
+
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	l := NewGCRA(NewRegionStore(c.NewRegion("rl")), PerMinute(1), 2)
+	ok, _ := l.Allow("k")
+	assert.True(t, ok)
+	c.Close()
+
+	c, err = ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	l = NewGCRA(NewRegionStore(c.NewRegion("rl")), PerMinute(1), 2)
+	n, err := l.Remaining("k")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(1), n)
+}
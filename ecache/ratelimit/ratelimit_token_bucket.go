@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// tokenBucket - the bucket holds at most burst tokens and is refilled by rate,
+// every event takes one token from it
+//
+// state: tokens(float64) | last(unix nano)
+type tokenBucket struct {
+	rate  Rate
+	burst int64
+}
+
+// NewTokenBucket creates a token-bucket limiter, the bucket of every key holds at most burst tokens
+// and is refilled by rate, burst will be set to rate.N if it is <= 0
+func NewTokenBucket(store Store, rate Rate, burst int64) *Limiter {
+	if burst <= 0 {
+		burst = rate.N
+	}
+	return newLimiter(store, &tokenBucket{rate: rate, burst: burst})
+}
+
+func (a *tokenBucket)limit() int64 {
+	return a.burst
+}
+
+// __tokens returns the tokens at now
+func (a *tokenBucket)__tokens(state []byte, now time.Time) float64 {
+	if len(state) != 16 {
+		return float64(a.burst)
+	}
+
+	tokens  := math.Float64frombits(binary.BigEndian.Uint64(state))
+	last    := int64(binary.BigEndian.Uint64(state[8:]))
+	elapsed := now.UnixNano() - last
+	if elapsed > 0 {
+		tokens += float64(elapsed) / a.rate.interval()
+	}
+
+	return math.Min(tokens, float64(a.burst))
+}
+
+func (a *tokenBucket)__result(tokens float64) Result {
+	return Result{
+		Limit     : a.burst,
+		Remaining : int64(math.Max(0, math.Floor(tokens))),
+		ResetAfter: __ceilDuration((float64(a.burst) - tokens) * a.rate.interval()),
+	}
+}
+
+func (a *tokenBucket)take(state []byte, now time.Time, n int64, maxDelay time.Duration) ([]byte, time.Duration, Reservation) {
+	tokens := a.__tokens(state, now)
+
+	delay := time.Duration(0)
+	if lack := float64(n) - tokens; lack > 0 {
+		delay = __ceilDuration(lack * a.rate.interval())
+	}
+
+	if delay > maxDelay {
+		r := Reservation{Result: a.__result(tokens)}
+		r.RetryAfter = delay
+		return nil, 0, r
+	}
+
+	tokens -= float64(n)
+	r := Reservation{Result: a.__result(tokens), OK: true, Delay: delay}
+	r.Allowed = delay == 0
+
+	out := make([]byte, 16)
+	binary.BigEndian.PutUint64(out, math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(out[8:], uint64(now.UnixNano()))
+
+	return out, __stateTTL(r.ResetAfter), r
+}
+
+func (a *tokenBucket)peek(state []byte, now time.Time) Result {
+	return a.__result(a.__tokens(state, now))
+}
@@ -15,6 +15,8 @@ var nilVal []byte = nil
 func TestBasic(t *testing.T){
 	ExecBasicTestForDsn(t, "badger:test_data/badger")
 	ExecBasicTestForDsn(t, "nutsdb:test_data/nutsdb")
+	ExecBasicTestForDsn(t, "bbolt:test_data/bbolt/ecache.db")
+	ExecBasicTestForDsn(t, "sqlite:test_data/sqlite/ecache.sqlite")
 }
 
 func ExecBasicTestForDsn(t *testing.T, dsn string){
@@ -25,6 +27,7 @@ func ExecBasicTestForDsn(t *testing.T, dsn string){
 	ExecTestSets(t, dsn)
 	ExecTestIF_DoForKeys(t, dsn)
 	ExecTestIF_DoForAll(t, dsn)
+	ExecTestTTLIterate(t, dsn)
 
 	// ExecTestBucketBasic(t, dsn)
 	// ExecTestBucketIF_DoForKeys(t, dsn)
@@ -622,4 +625,26 @@ func ExecTestIF_DoForAll(t *testing.T, dsn string){
 
 // 	c.Truncate()
 // 	c.Close()
-// }
\ No newline at end of file
+// }
+func ExecTestTTLIterate(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn} )
+	assert.Equal(t, nil, err)
+
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.DfRegion()
+	assert.Nil(t, r.Set("key1", "value1"))
+	assert.Nil(t, r.Set("key2", "value2", time.Second))
+	assert.Nil(t, r.Set("key3", "value3", time.Hour))
+
+	keys, _, err := r.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(keys))
+
+	time.Sleep(time.Second * 2)
+	keys, vals, err := r.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("key1"), []byte("key3")}, keys)
+	assert.Equal(t, 2, len(vals))
+}
@@ -1,10 +1,13 @@
 package tests
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver"
 )
 
 func TestCfgBasic(t *testing.T) {
@@ -42,4 +45,206 @@ func TestOptsBasic(t *testing.T){
 	assert.NoError(t, e2)
 	assert.NotNil(t, c1)
 	assert.NotNil(t, c2)
-}
\ No newline at end of file
+
+	c3, e3 := ecache.NewDBCache(ecache.DBCacheOpts{Dir: "./test_data/cache7.db", Driver: ecache.BBOLT})
+	c4, e4 := ecache.NewDBCache(ecache.DBCacheOpts{Dir: "./test_data/cache8.sqlite", Driver: ecache.SQLITE})
+	assert.NoError(t, e3)
+	assert.NoError(t, e4)
+	assert.NotNil(t, c3)
+	assert.NotNil(t, c4)
+	assert.NoError(t, c3.Close())
+	assert.NoError(t, c4.Close())
+}
+func TestCfgMemCache(t *testing.T){
+	err := ecache.InitFromConfigFile("./cfgs/config3.yml")
+	assert.NoError(t, err)
+
+	m1, e1 := ecache.GetMemCache[string, string]("mem1")
+	assert.NoError(t, e1)
+	assert.NotNil(t, m1)
+	assert.Equal(t, int64(1024), m1.Opt().MaxCost)
+	assert.Equal(t, time.Second * 10, m1.Opt().DfTTL)
+	assert.Equal(t, time.Minute, m1.Opt().MaxTTL)
+	assert.Equal(t, true, m1.Opt().AutoReRent)
+	assert.Equal(t, int64(10240), m1.Opt().CountersNum)
+
+	m1_, e1_ := ecache.GetMemCache[string, string]("mem1")
+	assert.NoError(t, e1_)
+	assert.True(t, m1 == m1_)
+
+	_, e1_ = ecache.GetMemCache[string, int]("mem1")
+	assert.Error(t, e1_)
+
+	m2, e2 := ecache.GetMemCache[uint64, []byte]("mem2")
+	assert.NoError(t, e2)
+	assert.Equal(t, int64(2048), m2.Opt().MaxCost)
+	m2.SetSync(1, []byte("val"))
+	v, ok := m2.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("val"), v)
+
+	_, e3 := ecache.GetMemCache[string, string]("mem3")
+	assert.Error(t, e3)
+}
+
+func TestCfgWatch(t *testing.T){
+	path := "./test_data/watch/config.yml"
+	assert.NoError(t, os.MkdirAll("./test_data/watch", 0755))
+	defer os.RemoveAll("./test_data/watch")
+
+	write := func(content string){
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		time.Sleep(time.Millisecond * 500)
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte(`
+ecache:
+  watch1:
+    dsn: badger:./test_data/watch/w1
+`), 0644))
+
+	w, err := ecache.WatchConfigFile(path)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	c1, err := ecache.GetDBCache("watch1")
+	assert.NoError(t, err)
+	r1 := c1.DfRegion()
+	assert.NoError(t, r1.Set("key", "val1"))
+
+	// add a new cache
+	write(`
+ecache:
+  watch1:
+    dsn: badger:./test_data/watch/w1
+  watch2:
+    dsn: nutsdb:./test_data/watch/w2
+`)
+	c2, err := ecache.GetDBCache("watch2")
+	assert.NoError(t, err)
+	assert.NoError(t, c2.DfRegion().Set("key", "val2"))
+
+	// change the DSN, the handle returned before should keep working
+	write(`
+ecache:
+  watch1:
+    dsn: badger:./test_data/watch/w1_new
+  watch2:
+    dsn: nutsdb:./test_data/watch/w2
+`)
+	c1_, err := ecache.GetDBCache("watch1")
+	assert.NoError(t, err)
+	assert.True(t, c1 == c1_)
+	v, err := r1.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "", v.Str())
+	assert.NoError(t, r1.Set("key", "val1_new"))
+	v, _ = r1.Get("key")
+	assert.Equal(t, "val1_new", v.Str())
+
+	// remove a cache
+	write(`
+ecache:
+  watch2:
+    dsn: nutsdb:./test_data/watch/w2
+`)
+	_, err = ecache.GetDBCache("watch1")
+	assert.Error(t, err)
+	assert.Error(t, r1.Set("key", "val"))
+
+	v, err = c2.DfRegion().Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "val2", v.Str())
+	assert.NoError(t, c2.Close())
+}
+
+func TestCfgWatchMem(t *testing.T){
+	path := "./test_data/watch_mem/config.yml"
+	assert.NoError(t, os.MkdirAll("./test_data/watch_mem", 0755))
+	defer os.RemoveAll("./test_data/watch_mem")
+
+	write := func(content string){
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		time.Sleep(time.Millisecond * 500)
+	}
+
+	assert.NoError(t, os.WriteFile(path, []byte(`
+ecache_mem:
+  watch_m1:
+    max_cost    : 1024
+    df_ttl      : 10m
+    max_ttl     : 1h
+`), 0644))
+
+	w, err := ecache.WatchConfigFile(path)
+	assert.NoError(t, err)
+	defer w.Close()
+
+	m1, err := ecache.GetMemCache[string, string]("watch_m1")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute * 10, m1.Opt().DfTTL)
+
+	// change the cfg, the handle returned before should be updated in place
+	write(`
+ecache_mem:
+  watch_m1:
+    max_cost    : 2048
+    df_ttl      : 1s
+    max_ttl     : 2s
+    auto_rerent : true
+`)
+	m1_, err := ecache.GetMemCache[string, string]("watch_m1")
+	assert.NoError(t, err)
+	assert.True(t, m1 == m1_)
+	assert.Equal(t, int64(2048), m1.Opt().MaxCost)
+	assert.Equal(t, time.Second, m1.Opt().DfTTL)
+	assert.True(t, m1.Opt().AutoReRent)
+
+	m1.Set("key", "val", time.Hour)
+	m1.Wait()
+	ttl, ok := m1.GetTTL("key")
+	assert.True(t, ok)
+	assert.LessOrEqual(t, ttl, time.Second * 2)
+
+	// remove the cache
+	write(`
+ecache_mem:
+`)
+	_, err = ecache.GetMemCache[string, string]("watch_m1")
+	assert.Error(t, err)
+}
+
+func TestDsnParams(t *testing.T){
+	assert.NoError(t, driver.CheckDsn("badger:./test_data/params?sync=true&readonly=false&mem_table_size=16MB&block_cache=0&compression=zstd&value_threshold=1KB"))
+	assert.NoError(t, driver.CheckDsn("badger:./test_data/params?memory=true"))
+	assert.NoError(t, driver.CheckDsn("nutsdb:./test_data/params?sync=1&segment_size=8MB&rw_mode=fileio&entry_idx_mode=key"))
+	assert.NoError(t, driver.CheckDsn("nutsdb:./test_data/params?memory=true"))
+	assert.NoError(t, driver.CheckDsn("nutsdb:./test_data/params?in-memory=true"))
+
+	assert.ErrorContains(t, driver.CheckDsn("badger:./test_data/params?unknown=1"), "unknown param 'unknown'")
+	assert.ErrorContains(t, driver.CheckDsn("badger:./test_data/params?sync=yes"), "invalid value 'yes' of bool param 'sync'")
+	assert.ErrorContains(t, driver.CheckDsn("badger:./test_data/params?mem_table_size=big"), "invalid value 'big' of size param 'mem_table_size'")
+	assert.ErrorContains(t, driver.CheckDsn("badger:./test_data/params?compression=lz4"), "invalid value 'lz4' of enum param 'compression'")
+	assert.ErrorContains(t, driver.CheckDsn("badger:./test_data/params?sync=true&sync=false"), "should be set only once")
+	assert.ErrorContains(t, driver.CheckDsn("nutsdb:./test_data/params?readonly=true"), "unknown param 'readonly' for driver 'nutsdb'")
+
+	_, err := ecache.NewDBCache(ecache.DBCacheOpts{Driver: ecache.BADGER, Dir: "./test_data/params", Params: map[string][]string{"num_compactors": {"two"}}})
+	assert.Error(t, err)
+
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:./test_data/params?in_memory=true&mem_table_size=16MB&compression=none"})
+	assert.NoError(t, err)
+	r := c.DfRegion()
+	assert.NoError(t, r.Set("key", "val"))
+	v, err := r.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "val", v.Str())
+	assert.NoError(t, c.Close())
+	_, err = os.Stat("./test_data/params")
+	assert.True(t, os.IsNotExist(err))
+
+	c, err = ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "nutsdb:./test_data/params_nutsdb?sync=true&segment_size=8MB&rw_mode=fileio"})
+	assert.NoError(t, err)
+	assert.NoError(t, c.DfRegion().Set("key", "val"))
+	assert.NoError(t, c.Truncate())
+	assert.NoError(t, c.Close())
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/redistest"
+)
+
+func TestCtx(t *testing.T){
+	ExecTestCtxForDsn(t, "badger:test_data/badger_ctx")
+	ExecTestCtxForDsn(t, "nutsdb:test_data/nutsdb_ctx")
+	ExecTestCtxForDsn(t, "bbolt:test_data/bbolt_ctx/ecache.db")
+	ExecTestCtxForDsn(t, "sqlite:test_data/sqlite_ctx/ecache.sqlite")
+
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+	ExecTestCtxForDsn(t, "redis:" + s.Addr())
+}
+
+func ExecTestCtxForDsn(t *testing.T, dsn string){
+	ExecTestCtx_Region(t, dsn)
+	ExecTestCtx_Iterate(t, dsn)
+	ExecTestCtx_ItemRegion(t, dsn)
+}
+
+func ExecTestCtx_Region(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.NewRegion("ctx")
+	ctx := context.Background()
+	assert.Equal(t, nil, r.SetCtx(ctx, "k1", "v1"))
+	v, err := r.GetCtx(ctx, "k1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "v1", v.Str())
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.True(t, errors.Is(r.SetCtx(cancelled, "k2", "v2"), context.Canceled))
+	_, err = r.GetCtx(cancelled, "k1")
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(r.DelCtx(cancelled, "k1"), context.Canceled))
+	assert.True(t, errors.Is(r.TruncateCtx(cancelled), context.Canceled))
+	assert.True(t, errors.Is(c.TruncateCtx(cancelled), context.Canceled))
+
+	// nothing changed by the cancelled operations
+	v, err = r.Get("k1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "v1", v.Str())
+	v, _ = r.Get("k2")
+	assert.Equal(t, "", v.Str())
+}
+
+func ExecTestCtx_Iterate(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.NewRegion("ctx_iter")
+	var keys, vals []string
+	for i := 0; i < 2500; i++ {
+		keys = append(keys, fmt.Sprintf("k%04d", i))
+		vals = append(vals, fmt.Sprintf("v%04d", i))
+	}
+	assert.Equal(t, nil, r.SetsCtx(context.Background(), keys, vals))
+
+	// cancelled in the middle of iterating
+	ctx, cancel := context.WithCancel(context.Background())
+	cnt := 0
+	err = r.DoForAllCtx(ctx, func(idx int, key []byte, val ecache.Val) error {
+		cnt++
+		if cnt == 10 {
+			cancel()
+		}
+		return nil
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 10, cnt)
+
+	_, _, err = r.GetAllCtx(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = r.GetsCtx(ctx, keys[:3])
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// cancelled between the batches, the keys are set in batches of 1000
+	ctx, cancel = context.WithCancel(context.Background())
+	var objs []any
+	for i := 0; i < 2500; i++ {
+		objs = append(objs, i)
+	}
+	err = r.SetObjsCtx(ctx, objs, func(i int, o any) ([]byte, any, time.Duration) {
+		if i == 1500 {
+			cancel()
+		}
+		return []byte(fmt.Sprintf("o%04d", i)), "o", 0
+	})
+	assert.True(t, errors.Is(err, context.Canceled))
+	v, _ := r.Get("o0999")
+	assert.Equal(t, "o", v.Str())
+	v, _ = r.Get("o2000")
+	assert.Equal(t, "", v.Str())
+
+	// not cancelled
+	cnt = 0
+	err = r.DoForAllCtx(context.Background(), func(idx int, key []byte, val ecache.Val) error {
+		cnt++
+		return nil
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2500 + 1000, cnt)
+}
+
+func ExecTestCtx_ItemRegion(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := ecache.NewTypedItemRegion[*myItem](c.NewRegion(), "ctx_items")
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%d", i)
+		assert.Equal(t, nil, r.SetCtx(ctx, name, &myItem{Name: name}))
+	}
+	items, err := r.GetAllCtx(ctx, newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 10, len(items))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = r.GetAllCtx(cancelled, newMyItem2)
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = r.GetCtx(cancelled, "name1", newMyItem2)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(r.SetCtx(cancelled, "name1", &myItem{Name: "x"}), context.Canceled))
+	assert.True(t, errors.Is(r.DelCtx(cancelled, []byte("name1")), context.Canceled))
+
+	get, err := r.Get("name1", newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "name1", get.Name)
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/eerr"
+)
+
+func TestErrors_Dsn(t *testing.T){
+	_, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "unknown:test_data/unknown"})
+	assert.True(t, errors.Is(err, ecache.ErrDriverNotFound))
+	assert.True(t, eerr.HasStack(err))
+
+	_, err = ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger"})
+	assert.True(t, errors.Is(err, ecache.ErrInvalidDsn))
+
+	err = driver.CheckDsn("badger:./test_data/params?unknown=1")
+	assert.True(t, errors.Is(err, driver.ErrInvalidDsn))
+	assert.ErrorContains(t, err, "unknown param 'unknown'")
+
+	_, err = ecache.GetDBCache("not_declared")
+	assert.True(t, errors.Is(err, ecache.ErrNotFound))
+}
+
+func TestErrors_All(t *testing.T){
+	ExecTestErrorsForDsn(t, "badger:test_data/badger_errs")
+	ExecTestErrorsForDsn(t, "nutsdb:test_data/nutsdb_errs")
+	ExecTestErrorsForDsn(t, "bbolt:test_data/bbolt_errs/ecache.db")
+	ExecTestErrorsForDsn(t, "sqlite:test_data/sqlite_errs/ecache.sqlite")
+}
+
+func TestErrors_DriverTx(t *testing.T){
+	// del in View is rejected
+	db, err := driver.OpenDsn("bbolt:test_data/bbolt_tx/ecache.db")
+	assert.NoError(t, err)
+	assert.NoError(t, db.Update(func(tx driver.TX) error { return tx.Set(nil, []byte("key"), []byte("val")) }))
+	assert.Error(t, db.View(func(tx driver.TX) error { _, _, err := tx.Get(nil, []byte("key"), true); return err }))
+	assert.NoError(t, db.View(func(tx driver.TX) error {
+		val, _, err := tx.Get(nil, []byte("key"))
+		assert.Equal(t, "val", string(val))
+		return err
+	}))
+	assert.NoError(t, db.Close())
+
+	// the txn is rollbacked if fn panicked, or the next one will be blocked
+	db, err = driver.OpenDsn("sqlite:test_data/sqlite_tx/ecache.sqlite")
+	assert.NoError(t, err)
+	assert.Panics(t, func() {
+		db.Update(func(tx driver.TX) error { tx.Set(nil, []byte("key"), []byte("val")); panic("oops") })
+	})
+	assert.NoError(t, db.Update(func(tx driver.TX) error { return tx.Set(nil, []byte("key"), []byte("val2")) }))
+	assert.NoError(t, db.Close())
+}
+
+func TestErrors_DriverClosed(t *testing.T){
+	for _, dsn := range []string{"badger:test_data/badger_closed", "nutsdb:test_data/nutsdb_closed",
+		"bbolt:test_data/bbolt_closed/ecache.db", "sqlite:test_data/sqlite_closed/ecache.sqlite"} {
+		db, err := driver.OpenDsn(dsn)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Close())
+
+		err = db.Update(func(tx driver.TX) error { return tx.Set(nil, []byte("key"), []byte("val")) })
+		assert.True(t, errors.Is(err, driver.ErrClosed), "dsn: %s, err: %v", dsn, err)
+		err = db.View(func(tx driver.TX) error { _, _, err := tx.Get(nil, []byte("key")); return err })
+		assert.True(t, errors.Is(err, driver.ErrClosed), "dsn: %s, err: %v", dsn, err)
+	}
+}
+
+func ExecTestErrorsForDsn(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+
+	r := c.NewRegion("errs")
+	assert.Equal(t, nil, r.Set("k1", "v1"))
+
+	// not found
+	v, err := r.Get("not_exist")
+	assert.Equal(t, nil, err)
+	assert.True(t, errors.Is(v.Error(), ecache.ErrNotFound))
+	_, err = v.GetBytes()
+	assert.True(t, errors.Is(err, ecache.ErrNotFound))
+
+	// type mismatch
+	v, err = r.Get("k1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, v.Error())
+	_, err = v.GetI64()
+	assert.True(t, errors.Is(err, ecache.ErrTypeMismatch))
+	var te *ecache.TypeMismatchError
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, "int64", te.Want)
+	assert.Equal(t, "[]byte", te.Got)
+	assert.True(t, eerr.HasStack(err))
+
+	err = r.Set(123, "v")
+	assert.True(t, errors.Is(err, ecache.ErrTypeMismatch))
+	assert.True(t, errors.Is(r.Sets(123, []string{"v"}), ecache.ErrTypeMismatch))
+	assert.True(t, errors.Is(r.Sets([]string{"k"}, 123), ecache.ErrTypeMismatch))
+	assert.True(t, errors.Is(r.DoForKeys(123, func(int, []byte, ecache.Val) error { return nil }), ecache.ErrTypeMismatch))
+	_, err = r.Gets(123)
+	assert.True(t, errors.Is(err, ecache.ErrTypeMismatch))
+	err = r.Dels("k2", 123)
+	assert.True(t, errors.Is(err, ecache.ErrTypeMismatch))
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, "int", te.Got)
+
+	ir := ecache.MakeTypedItemRegion[*myItem](c.NewRegion("errs"))
+
+	_, err = ir.Gets([][]byte{[]byte("not_exist")}, newMyItem2)
+	assert.True(t, errors.Is(err, ecache.ErrNotFound))
+
+	_, err = ir.FindBy("no_index", []byte("v"), newMyItem2)
+	assert.True(t, errors.Is(err, ecache.ErrNotFound))
+
+	// closed
+	c.Truncate()
+	c.Close()
+	_, err = r.Get("k1")
+	assert.True(t, errors.Is(err, ecache.ErrClosed))
+	assert.True(t, errors.Is(r.Set("k1", "v1"), ecache.ErrClosed))
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+)
+
+func TestHook(t *testing.T){
+	ExecTestHookForDsn(t, "badger:test_data/badger_hook")
+	ExecTestHookForDsn(t, "nutsdb:test_data/nutsdb_hook")
+	ExecTestHookForDsn(t, "bbolt:test_data/bbolt_hook/ecache.db")
+	ExecTestHookForDsn(t, "sqlite:test_data/sqlite_hook/ecache.sqlite")
+
+	ExecTestHookIterateFromForDsn(t, "badger:test_data/badger_hook")
+	ExecTestHookIterateFromForDsn(t, "nutsdb:test_data/nutsdb_hook")
+	ExecTestHookIterateFromForDsn(t, "bbolt:test_data/bbolt_hook/ecache.db")
+	ExecTestHookIterateFromForDsn(t, "sqlite:test_data/sqlite_hook/ecache.sqlite")
+}
+
+func ExecTestHookForDsn(t *testing.T, dsn string){
+	var mu sync.Mutex
+	var events []ecache.OpEvent
+	record := func(e *ecache.OpEvent) {
+		mu.Lock()
+		events = append(events, *e)
+		mu.Unlock()
+	}
+	h := ecache.NewLatencyHistogram()
+
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn, Hooks: []ecache.Hook{record, h.Hook(), ecache.SlowOpLogger(time.Hour)}})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+
+	r := c.NewRegion("k1", "k2").SubRegion("s1")
+	assert.Equal(t, nil, r.Set("key", "val"))
+	_, err = r.Get("key")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, r.Sets([]string{"a", "b"}, []string{"1", "2"}))
+	assert.Equal(t, nil, r.DoForAll(func(idx int, key []byte, val ecache.Val) error { return nil }))
+	assert.Equal(t, nil, r.Del("key"))
+	assert.Equal(t, nil, c.DfRegion().Set("key", "val"))
+	assert.Equal(t, nil, c.Truncate())
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the first write of a region loads its quota from the info records, so there may be extra gets and views
+	var ops []ecache.OpEvent
+	for _, e := range events {
+		if (e.Op == ecache.OP_GET || e.Op == ecache.OP_VIEW) && e.Bytes == len("quota") {
+			continue
+		}
+		e.Duration = 0
+		ops = append(ops, e)
+	}
+	assert.Equal(t, []ecache.OpEvent{
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_SET    , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: ""            , Op: ecache.OP_UPDATE , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_GET    , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: ""            , Op: ecache.OP_VIEW   , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_SET    , Keys: 1, Bytes: 1 + 4 + 1},
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_SET    , Keys: 1, Bytes: 1 + 4 + 1},
+		{Region: ""            , Op: ecache.OP_UPDATE , Keys: 2, Bytes: 6 + 6},
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_ITERATE, Keys: 3, Bytes: 10 + 6 + 6},
+		{Region: ""            , Op: ecache.OP_VIEW   , Keys: 3, Bytes: 10 + 6 + 6},
+		{Region: "[k1,k2].[s1]", Op: ecache.OP_DEL    , Keys: 1, Bytes: 3},
+		{Region: ""            , Op: ecache.OP_UPDATE , Keys: 1, Bytes: 3},
+		{Region: "[]"          , Op: ecache.OP_SET    , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: ""            , Op: ecache.OP_UPDATE , Keys: 1, Bytes: 3 + 4 + 3},
+		{Region: ""            , Op: ecache.OP_TRUNCATE},
+	}, ops)
+
+	var cnt uint64
+	for _, s := range h.Snapshot() {
+		cnt += s.Count
+		var sum uint64
+		for _, b := range s.Buckets {
+			sum += b
+		}
+		assert.Equal(t, s.Count, sum)
+		assert.Equal(t, len(s.Bounds) + 1, len(s.Buckets))
+	}
+	assert.Equal(t, uint64(len(events)), cnt)
+}
+
+// the index scans of FindBy go through IterateFrom of the hooks rather than a full Iterate
+func ExecTestHookIterateFromForDsn(t *testing.T, dsn string){
+	var iterated []int
+	record := func(e *ecache.OpEvent) {
+		if e.Op == ecache.OP_ITERATE {
+			iterated = append(iterated, e.Keys)
+		}
+	}
+
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn, Hooks: []ecache.Hook{record}})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := newIndexedRegion(c)
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "n1", Tel: "111"}))
+	assert.Equal(t, nil, r.Set("k2", &myItem{Name: "n2", Tel: "222"}))
+	assert.Equal(t, nil, r.Set("k3", &myItem{Name: "n3", Tel: "333"}))
+
+	items, err := r.FindBy("tel", []byte("333"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"n3"}, itemNames(items))
+	assert.Equal(t, []int{1}, iterated)
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/redistest"
+)
+
+func TestItemRegionIndex(t *testing.T){
+	ExecTestItemRegionIndexDsn(t, "badger:test_data/badger_index")
+	ExecTestItemRegionIndexDsn(t, "nutsdb:test_data/nutsdb_index")
+	ExecTestItemRegionIndexDsn(t, "bbolt:test_data/bbolt_index/ecache.db")
+	ExecTestItemRegionIndexDsn(t, "sqlite:test_data/sqlite_index/ecache.sqlite")
+
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+	ExecTestItemRegionIndexDsn(t, "redis:" + s.Addr())
+}
+
+func ExecTestItemRegionIndexDsn(t *testing.T, dsn string){
+	ExecTestItemRegionIndex_Basic(t, dsn)
+	ExecTestItemRegionIndex_Rebuild(t, dsn)
+	ExecTestItemRegionIndex_GetDel(t, dsn)
+}
+
+func itemNames(items []*myItem) (out []string) {
+	for _, i := range items {
+		out = append(out, i.Name)
+	}
+	return
+}
+
+func newIndexedRegion(c *ecache.DBCache) *ecache.ItemRegion[*myItem] {
+	r := ecache.NewTypedItemRegion[*myItem](c.DfRegion(), "indexed")
+	r.AddIndex("tel", func(i *myItem) [][]byte { return [][]byte{[]byte(i.Tel)} })
+	return r
+}
+
+func ExecTestItemRegionIndex_Basic(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := newIndexedRegion(c)
+	assert.NotEqual(t, nil, r.AddIndex("tel", func(i *myItem) [][]byte { return nil }))
+	assert.NotEqual(t, nil, r.AddIndex("", func(i *myItem) [][]byte { return nil }))
+
+	// multi values, including the value contains 0
+	assert.Equal(t, nil, r.AddIndex("letters", func(i *myItem) [][]byte {
+		var out [][]byte
+		for _, c := range i.Name {
+			out = append(out, []byte{byte(c), 0})
+		}
+		return out
+	}))
+
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "ab", Tel: "111"}))
+	assert.Equal(t, nil, r.Set("k2", &myItem{Name: "bc", Tel: "222"}))
+	assert.Equal(t, nil, r.Set("k3", &myItem{Name: "cd", Tel: "111"}))
+	assert.Equal(t, nil, r.Set("k4", &myItem{Name: "de", Tel: "1110"}))
+
+	items, err := r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"ab", "cd"}, itemNames(items))
+
+	items, err = r.FindBy("letters", []byte{'b', 0}, newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"ab", "bc"}, itemNames(items))
+
+	items, err = r.RangeBy("tel", []byte("1110"), nil, newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"de", "bc"}, itemNames(items))
+
+	items, err = r.RangeBy("tel", nil, []byte("2"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"ab", "cd", "de"}, itemNames(items))
+
+	_, err = r.FindBy("unknown", []byte("111"), newMyItem2)
+	assert.NotEqual(t, nil, err)
+
+	// updating replaces the old entries
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "ab", Tel: "333"}))
+	items, _ = r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, []string{"cd"}, itemNames(items))
+	items, _ = r.FindBy("tel", []byte("333"), newMyItem2)
+	assert.Equal(t, []string{"ab"}, itemNames(items))
+
+	// deleting removes the entries
+	assert.Equal(t, nil, r.Del([]byte("k3")))
+	items, _ = r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, 0, len(items))
+	_, err = r.Get("k2", newMyItem2, true)
+	assert.Equal(t, nil, err)
+	items, _ = r.FindBy("letters", []byte{'c', 0}, newMyItem2)
+	assert.Equal(t, 0, len(items))
+
+	// the entries are persisted
+	items, _ = newIndexedRegion(c).FindBy("tel", []byte("1110"), newMyItem2)
+	assert.Equal(t, []string{"de"}, itemNames(items))
+}
+
+func ExecTestItemRegionIndex_Rebuild(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := ecache.NewTypedItemRegion[*myItem](c.DfRegion(), "indexed")
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "n1", Tel: "111"}))
+	assert.Equal(t, nil, r.Set("k2", &myItem{Name: "n2", Tel: "222"}))
+
+	r = newIndexedRegion(c)
+	items, _ := r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, 0, len(items))
+
+	assert.Equal(t, nil, r.RebuildIndexes(newMyItem2))
+	items, err = r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"n1"}, itemNames(items))
+	items, _ = r.RangeBy("tel", nil, nil, newMyItem2)
+	assert.Equal(t, []string{"n1", "n2"}, itemNames(items))
+}
+
+func ExecTestItemRegionIndex_GetDel(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := newIndexedRegion(c)
+	r.EnableMemCache(100, 0)
+
+	// only one of the concurrent callers gets the item, the others may fail with driver.ErrTxConflict
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "n1", Tel: "111"}))
+	var got, errs int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i, err := r.Get("k1", newMyItem2, true)
+			if err != nil && !errors.Is(err, driver.ErrTxConflict) {
+				atomic.AddInt32(&errs, 1)
+			} else if i != nil {
+				atomic.AddInt32(&got, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), errs)
+	assert.Equal(t, int32(1), got)
+	items, _ := r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, 0, len(items))
+
+	// the entries are removed with the keys by Gets
+	assert.Equal(t, nil, r.Set("k2", &myItem{Name: "n2", Tel: "222"}))
+	assert.Equal(t, nil, r.Set("k3", &myItem{Name: "n3", Tel: "222"}))
+	items, err = r.Gets([][]byte{[]byte("k2"), []byte("k3")}, newMyItem2, false, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"n2", "n3"}, itemNames(items))
+	items, _ = r.FindBy("tel", []byte("222"), newMyItem2)
+	assert.Equal(t, 0, len(items))
+	i, err := r.Get("k2", newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Nil(t, i)
+}
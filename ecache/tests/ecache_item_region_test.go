@@ -56,6 +56,8 @@ func (i *myItem)Equal(i2 *myItem) bool {
 func TestItemRegionAll(t *testing.T){
 	ExecTestItemRegionDsn(t, "badger:test_data/badger")
 	ExecTestItemRegionDsn(t, "nutsdb:test_data/nutsdb")
+	ExecTestItemRegionDsn(t, "bbolt:test_data/bbolt/ecache.db")
+	ExecTestItemRegionDsn(t, "sqlite:test_data/sqlite/ecache.sqlite")
 }
 
 func ExecTestItemRegionDsn(t *testing.T, dsn string){
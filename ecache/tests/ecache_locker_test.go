@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/redistest"
+)
+
+func TestLocker(t *testing.T){
+	ExecTestLockerForDsn(t, "badger:test_data/badger_lock")
+	ExecTestLockerForDsn(t, "nutsdb:test_data/nutsdb_lock")
+	ExecTestLockerForDsn(t, "bbolt:test_data/bbolt_lock/ecache.db")
+	ExecTestLockerForDsn(t, "sqlite:test_data/sqlite_lock/ecache.sqlite")
+
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+	ExecTestLockerForDsn(t, "redis:" + s.Addr())
+}
+
+func ExecTestLockerForDsn(t *testing.T, dsn string){
+	ExecTestLocker_Basic(t, dsn)
+	ExecTestLocker_Expire(t, dsn)
+	ExecTestLocker_Concurrent(t, dsn)
+}
+
+func ExecTestLocker_Basic(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	l := ecache.NewLocker(c.NewRegion("locks"))
+
+	l1, err := l.TryLock("job1", time.Second * 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(1), l1.Fence())
+	assert.Equal(t, "job1", l1.Name())
+	assert.NotEqual(t, "", l1.Token())
+
+	_, err = l.TryLock("job1", time.Second * 10)
+	assert.Equal(t, ecache.ErrLocked, err)
+
+	// other names are not affected
+	l2, err := l.TryLock("job2", time.Second * 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(1), l2.Fence())
+
+	// renew
+	before := l1.ExpiresAt()
+	assert.Equal(t, nil, l.Renew(l1, time.Second * 20))
+	assert.True(t, l1.ExpiresAt().After(before))
+	assert.Equal(t, uint64(1), l1.Fence())
+
+	// unlock and lock again, the fence increases
+	assert.Equal(t, nil, l.Unlock(l1))
+	assert.Equal(t, ecache.ErrLockNotHeld, l.Unlock(l1))
+	assert.Equal(t, ecache.ErrLockNotHeld, l.Renew(l1, time.Second))
+
+	l3, err := l.Lock(context.Background(), "job1", time.Second * 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(2), l3.Fence())
+	fence, err := l.Fence("job1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(2), fence)
+
+	// Lock returns when ctx done
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 200)
+	defer cancel()
+	_, err = l.Lock(ctx, "job1", time.Second)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the region records are not affected by locks
+	keys, _, err := c.NewRegion("locks").GetAll()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(keys))
+}
+
+func ExecTestLocker_Expire(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	l := ecache.NewLocker(c.DfRegion())
+
+	l1, err := l.TryLock("job", time.Millisecond * 100)
+	assert.Equal(t, nil, err)
+
+	start := time.Now()
+	l2, err := l.Lock(context.Background(), "job", time.Second * 10)
+	assert.Equal(t, nil, err)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.True(t, l1.Expired())
+	assert.Equal(t, l1.Fence() + 1, l2.Fence())
+
+	// the expired owner can not renew or release it
+	assert.Equal(t, ecache.ErrLockNotHeld, l.Renew(l1, time.Second))
+	assert.Equal(t, ecache.ErrLockNotHeld, l.Unlock(l1))
+	assert.Equal(t, nil, l.Unlock(l2))
+}
+
+func ExecTestLocker_Concurrent(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	l := ecache.NewLocker(c.DfRegion())
+	l.SetPollInterval(time.Millisecond)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	holding, maxHolding, fences := 0, 0, map[uint64]bool{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				lease, err := l.Lock(context.Background(), "counter", time.Second * 10)
+				if !assert.Equal(t, nil, err) {
+					return
+				}
+
+				mu.Lock()
+				holding += 1
+				if holding > maxHolding {
+					maxHolding = holding
+				}
+				fences[lease.Fence()] = true
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				holding -= 1
+				mu.Unlock()
+				assert.Equal(t, nil, l.Unlock(lease))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxHolding)
+	assert.Equal(t, 40, len(fences))
+}
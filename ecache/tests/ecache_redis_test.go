@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/redistest"
+)
+
+func TestRedis(t *testing.T){
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+
+	ExecBasicTestForDsn(t, "redis:" + s.Addr())
+	ExecRegionTestForDsn(t, "redis:" + s.Addr() + "?namespace=region")
+	ExecTestItemRegionDsn(t, "redis:" + s.Addr() + "?namespace=item&db=1")
+}
+
+func TestRedisNamespace(t *testing.T){
+	s, err := redistest.NewServer("pass")
+	assert.Equal(t, nil, err)
+	defer s.Close()
+
+	_, err = ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "redis:" + s.Addr()})
+	assert.NotEqual(t, nil, err)
+
+	c1, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "redis:" + s.Addr() + "?password=pass&namespace=ns1"})
+	assert.Equal(t, nil, err)
+	defer c1.Close()
+	c2, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "redis:" + s.Addr() + "?password=pass&namespace=ns2"})
+	assert.Equal(t, nil, err)
+	defer c2.Close()
+
+	r1, r2 := c1.DfRegion(), c2.DfRegion()
+	assert.Equal(t, nil, r1.Set("key", "val1"))
+	assert.Equal(t, nil, r2.Set("key", "val2"))
+	v1, _ := r1.Get("key")
+	v2, _ := r2.Get("key")
+	assert.Equal(t, "val1", v1.Str())
+	assert.Equal(t, "val2", v2.Str())
+
+	assert.Equal(t, nil, c1.Truncate())
+	v1, _ = r1.Get("key")
+	v2, _ = r2.Get("key")
+	assert.Equal(t, nilVal, v1.Bytes())
+	assert.Equal(t, "val2", v2.Str())
+}
+
+func TestRedisTxConflict(t *testing.T){
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+
+	db, err := driver.OpenDsn("redis:" + s.Addr())
+	assert.Equal(t, nil, err)
+	defer db.Close()
+
+	assert.Equal(t, nil, db.Update(func(tx driver.TX) error {
+		return tx.Set(nil, []byte("k"), []byte("v1"))
+	}))
+
+	err = db.Update(func(tx driver.TX) error {
+		v, _, err := tx.Get(nil, []byte("k"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "v1", string(v))
+
+		// modified by others before committing
+		assert.Equal(t, nil, db.Update(func(tx driver.TX) error {
+			return tx.Set(nil, []byte("k"), []byte("v2"))
+		}))
+
+		return tx.Set(nil, []byte("k"), []byte("v3"))
+	})
+	assert.Equal(t, redis.ErrTxConflict, err)
+
+	assert.Equal(t, nil, db.View(func(tx driver.TX) error {
+		v, _, err := tx.Get(nil, []byte("k"))
+		assert.Equal(t, "v2", string(v))
+		return err
+	}))
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+	"github.com/ziyht/eden_go/ecache/driver/drivers/redis/redistest"
+)
+
+func TestRegionQuota(t *testing.T){
+	ExecRegionQuotaTestForDsn(t, "badger:test_data/badger_quota")
+	ExecRegionQuotaTestForDsn(t, "nutsdb:test_data/nutsdb_quota")
+	ExecRegionQuotaTestForDsn(t, "bbolt:test_data/bbolt_quota/ecache.db")
+	ExecRegionQuotaTestForDsn(t, "sqlite:test_data/sqlite_quota/ecache.sqlite")
+
+	s, err := redistest.NewServer()
+	assert.Equal(t, nil, err)
+	defer s.Close()
+	ExecRegionQuotaTestForDsn(t, "redis:" + s.Addr())
+}
+
+func ExecRegionQuotaTestForDsn(t *testing.T, dsn string){
+	ExecTestRegionQuota_Reject(t, dsn)
+	ExecTestRegionQuota_EvictOldest(t, dsn)
+	ExecTestRegionQuota_EvictNearestExpiry(t, dsn)
+	ExecTestRegionQuota_Rebuild(t, dsn)
+	ExecTestRegionQuota_ItemRegion(t, dsn)
+	ExecTestRegionQuota_ItemRegionIndexed(t, dsn)
+}
+
+func assertQuotaExceeded(t *testing.T, err error) {
+	var qe *ecache.ErrQuotaExceeded
+	assert.True(t, errors.As(err, &qe), "err: %v", err)
+}
+
+func ExecTestRegionQuota_Reject(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.NewRegion("quota")
+	assert.Equal(t, nil, r.SetQuota(3, 0, ecache.QUOTA_REJECT))
+	maxKeys, maxBytes, policy, ok := r.Quota()
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), maxKeys)
+	assert.Equal(t, int64(0), maxBytes)
+	assert.Equal(t, ecache.QUOTA_REJECT, policy)
+
+	assert.Equal(t, nil, r.Set("k1", "v1"))
+	assert.Equal(t, nil, r.Sets([]string{"k2", "k3"}, []string{"v2", "v3"}))
+	assertQuotaExceeded(t, r.Set("k4", "v4"))
+	assertQuotaExceeded(t, r.Sets([]string{"k4", "k5"}, "v"))
+
+	// overwriting is not counted as a new key
+	assert.Equal(t, nil, r.Set("k1", "v11"))
+	keys, bytes, err := r.Usage()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), keys)
+	assert.True(t, bytes > 0)
+
+	// other handles and sub regions
+	assertQuotaExceeded(t, c.NewRegion("quota").Set("k4", "v4"))
+	assert.Equal(t, nil, r.SubRegion("sub").Set("k4", "v4"))
+
+	assert.Equal(t, nil, r.Del("k1"))
+	_, err = r.Get("k2", true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, r.Set("k4", "v4"))
+	assert.Equal(t, nil, r.Set("k5", "v5"))
+	keys, _, _ = r.Usage()
+	assert.Equal(t, int64(3), keys)
+
+	// the expired keys are reclaimed
+	assert.Equal(t, nil, r.Dels("k3", "k4"))
+	assert.Equal(t, nil, r.Set("k6", "v6", time.Second))
+	assert.Equal(t, nil, r.Set("k7", "v7"))
+	time.Sleep(time.Millisecond * 2100)
+	assert.Equal(t, nil, r.Set("k8", "v8"))
+	keys, _, _ = r.Usage()
+	assert.Equal(t, int64(3), keys)
+
+	// bytes
+	assert.Equal(t, nil, r.SetQuota(0, 64, ecache.QUOTA_REJECT))
+	assertQuotaExceeded(t, r.Set("big", make([]byte, 64)))
+
+	// truncate resets the usage
+	assert.Equal(t, nil, r.Truncate())
+	keys, bytes, _ = r.Usage()
+	assert.Equal(t, int64(0), keys)
+	assert.Equal(t, int64(0), bytes)
+	assert.Equal(t, nil, r.Set("k1", make([]byte, 32)))
+
+	// remove the quota
+	assert.Equal(t, nil, r.SetQuota(0, 0, ecache.QUOTA_REJECT))
+	_, _, _, ok = r.Quota()
+	assert.False(t, ok)
+	assert.Equal(t, nil, r.Set("big", make([]byte, 64)))
+}
+
+func ExecTestRegionQuota_EvictOldest(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.NewRegion("quota")
+	assert.Equal(t, nil, r.SetQuota(3, 0, ecache.QUOTA_EVICT_OLDEST))
+
+	for _, k := range []string{"k5", "k4", "k3", "k2", "k1"} {
+		assert.Equal(t, nil, r.Set(k, "v"))
+	}
+
+	keys, _, err := r.GetAll()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, [][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}, keys)
+
+	// overwriting refreshes the order
+	assert.Equal(t, nil, r.Set("k3", "v"))
+	assert.Equal(t, nil, r.Set("k6", "v"))
+	keys, _, _ = r.GetAll()
+	assert.Equal(t, [][]byte{[]byte("k1"), []byte("k3"), []byte("k6")}, keys)
+}
+
+func ExecTestRegionQuota_EvictNearestExpiry(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := c.NewRegion("quota")
+	assert.Equal(t, nil, r.SetQuota(3, 0, ecache.QUOTA_EVICT_NEAREST_EXPIRY))
+
+	assert.Equal(t, nil, r.Set("a", "v"))
+	assert.Equal(t, nil, r.Set("b", "v", time.Hour))
+	assert.Equal(t, nil, r.Set("c", "v", time.Minute * 10))
+
+	assert.Equal(t, nil, r.Set("d", "v"))
+	keys, _, _ := r.GetAll()
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("d")}, keys)
+
+	assert.Equal(t, nil, r.Set("e", "v"))
+	keys, _, _ = r.GetAll()
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("d"), []byte("e")}, keys)
+
+	// no keys with ttl, evict the oldest
+	assert.Equal(t, nil, r.Set("f", "v"))
+	keys, _, _ = r.GetAll()
+	assert.Equal(t, [][]byte{[]byte("d"), []byte("e"), []byte("f")}, keys)
+}
+
+func ExecTestRegionQuota_Rebuild(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+
+	r := c.NewRegion("quota")
+	assert.Equal(t, nil, r.Sets([]string{"k1", "k2", "k3", "k4"}, "v"))
+	assert.Equal(t, nil, r.SetQuota(5, 0, ecache.QUOTA_REJECT))
+	keys, bytes, err := r.Usage()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(4), keys)
+	assert.Equal(t, int64(4 * (2 + 5)), bytes)
+	c.Close()
+
+	// the quota and usage are persisted
+	c, err = ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r = c.NewRegion("quota")
+	_, _, _, ok := r.Quota()
+	assert.True(t, ok)
+	assert.Equal(t, nil, r.Set("k5", "v"))
+	assertQuotaExceeded(t, r.Set("k6", "v"))
+}
+
+func ExecTestRegionQuota_ItemRegion(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r  := c.NewRegion("quota_item")
+	ir := c.NewItemRegion("quota_item")
+	assert.Equal(t, nil, ir.SetQuota(2, 0, ecache.QUOTA_REJECT))
+
+	// the quota is separated from the Region with the same keys
+	_, _, _, ok := r.Quota()
+	assert.False(t, ok)
+	assert.Equal(t, nil, r.Sets([]string{"k1", "k2", "k3"}, "v"))
+
+	assert.Equal(t, nil, ir.Set("k1", &myItem{Name: "n1"}))
+	assert.Equal(t, nil, ir.Set("k2", &myItem{Name: "n2"}))
+	assertQuotaExceeded(t, ir.Set("k3", &myItem{Name: "n3"}))
+	keys, _, err := ir.Usage()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(2), keys)
+
+	assert.Equal(t, nil, ir.Del([]byte("k1")))
+	assert.Equal(t, nil, ir.Set("k3", &myItem{Name: "n3"}))
+
+	// the quota set by another handle is found
+	assertQuotaExceeded(t, c.NewItemRegion("quota_item").Set("k4", &myItem{Name: "n4"}))
+
+	assert.Equal(t, nil, ir.SetQuota(0, 0, ecache.QUOTA_REJECT))
+	assert.Equal(t, nil, ir.Set("k4", &myItem{Name: "n4"}))
+}
+
+func ExecTestRegionQuota_ItemRegionIndexed(t *testing.T, dsn string){
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: dsn})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	defer c.Truncate()
+
+	r := ecache.NewTypedItemRegion[*myItem](c.DfRegion(), "quota_indexed")
+	assert.Equal(t, nil, r.AddIndex("tel", func(i *myItem) [][]byte { return [][]byte{[]byte(i.Tel)} }))
+	assert.Equal(t, nil, r.SetQuota(2, 0, ecache.QUOTA_EVICT_OLDEST))
+
+	assert.Equal(t, nil, r.Set("k1", &myItem{Name: "n1", Tel: "111"}))
+	assert.Equal(t, nil, r.Set("k2", &myItem{Name: "n2", Tel: "222"}))
+	assert.Equal(t, nil, r.Set("k3", &myItem{Name: "n3", Tel: "111"}))   // evicts k1
+
+	items, err := r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"n3"}, itemNames(items))
+
+	// the entries of evicted keys are removed, so the keys set again by a handle without indexes are not found by them
+	plain := ecache.NewTypedItemRegion[*myItem](c.DfRegion(), "quota_indexed")
+	assert.Equal(t, nil, plain.Set("k1", &myItem{Name: "n1x", Tel: "999"}))   // evicts k2
+
+	items, err = r.FindBy("tel", []byte("111"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"n3"}, itemNames(items))
+	items, err = r.FindBy("tel", []byte("222"), newMyItem2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(items))
+}
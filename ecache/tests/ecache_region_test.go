@@ -12,6 +12,8 @@ import (
 func TestRegion(t *testing.T){
 	ExecRegionTestForDsn(t, "badger:test_data/badger2")
 	ExecRegionTestForDsn(t, "nutsdb:test_data/nutsdb")
+	ExecRegionTestForDsn(t, "bbolt:test_data/bbolt2/ecache.db")
+	ExecRegionTestForDsn(t, "sqlite:test_data/sqlite2/ecache.sqlite")
 }
 
 func ExecRegionTestForDsn(t *testing.T, dsn string){
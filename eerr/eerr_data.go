@@ -29,6 +29,11 @@ func (e *errorData) Unpack() error {
 	return e.err
 }
 
+// Unwrap returns the original error, so errors.Is and errors.As can see through the stack trace
+func (e *errorData) Unwrap() error {
+	return e.err
+}
+
 // a hash value caculated by file, line number and error string
 // for this is a not frequently using function, now re caculated each time by call
 func (e *errorData) Id() uint64 {
@@ -0,0 +1,258 @@
+// Package admin provides an http.Handler to view and control the jobs and groups of an etimer.Timer
+//
+//	GET    /jobs                  list all the jobs, filtered by ?group= and ?status= if set
+//	GET    /jobs/{name}           get a job
+//	POST   /jobs/{name}/trigger   run the job now, the steps of workflow can not be triggered
+//	POST   /jobs/{name}/pause     pause the job
+//	POST   /jobs/{name}/resume    resume the job
+//	POST   /jobs/{name}/close     close the job
+//	DELETE /jobs/{name}           close and remove the job
+//	GET    /groups                list all the groups
+//	POST   /groups/{name}?max=N   create a group or update its max running jobs
+//	POST   /pause                 pause the timer
+//	POST   /resume                resume the timer
+//
+// mount it with http.StripPrefix if needed, like:
+//
+//	http.Handle("/etimer/", http.StripPrefix("/etimer", admin.NewHandler(t)))
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ziyht/eden_go/etimer"
+)
+
+// JobInfo is the json view of a job
+type JobInfo struct {
+	Name        string        `json:"name"`
+	Group       string        `json:"group,omitempty"`
+	Status      string        `json:"status"`
+	Pattern     string        `json:"pattern,omitempty"`
+	Interval    string        `json:"interval,omitempty"`
+	Singleton   bool          `json:"singleton"`
+	Times       int64         `json:"times,omitempty"`
+	LeftTimes   int64         `json:"left_times,omitempty"`
+	Runnings    uint64        `json:"runnings"`
+	Successs    uint64        `json:"successs"`
+	Failures    uint64        `json:"failures"`
+	Retries     uint64        `json:"retries"`
+	Timeouts    uint64        `json:"timeouts"`
+	Skips       uint64        `json:"skips"`
+	NextStart   time.Time     `json:"next_start"`
+	LastStart   time.Time     `json:"last_start"`
+	LastEnd     time.Time     `json:"last_end"`
+	LastCost    string        `json:"last_cost"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastFailure time.Time     `json:"last_failure"`
+	LastError   string        `json:"last_error,omitempty"`
+	Errors      []ErrInfo     `json:"errors,omitempty"`
+}
+
+// ErrInfo is the json view of an error recorded in JobState
+type ErrInfo struct {
+	Error string    `json:"error"`
+	File  string    `json:"file,omitempty"`
+	Line  int       `json:"line,omitempty"`
+	Func  string    `json:"func,omitempty"`
+	Count uint64    `json:"count"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// GroupInfo is the json view of a group
+type GroupInfo struct {
+	Name    string `json:"name"`
+	Running int    `json:"running"`
+	Pending int    `json:"pending"`
+	Max     int    `json:"max"`
+}
+
+type handler struct {
+	t   *etimer.Timer
+	mux *http.ServeMux
+}
+
+// NewHandler returns an http.Handler to view and control the jobs and groups of t
+func NewHandler(t *etimer.Timer) http.Handler {
+	h := &handler{t: t, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("GET /jobs"                , h.listJobs)
+	h.mux.HandleFunc("GET /jobs/{name}"         , h.getJob)
+	h.mux.HandleFunc("POST /jobs/{name}/{op}"   , h.opJob)
+	h.mux.HandleFunc("DELETE /jobs/{name}"      , h.removeJob)
+	h.mux.HandleFunc("GET /groups"              , h.listGroups)
+	h.mux.HandleFunc("POST /groups/{name}"      , h.setGroup)
+	h.mux.HandleFunc("POST /{op}"               , h.opTimer)
+
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) listJobs(w http.ResponseWriter, r *http.Request) {
+	group, hasGroup := r.URL.Query()["group"]
+	status := r.URL.Query().Get("status")
+
+	jobs := h.t.Jobs(func(j *etimer.Job) bool {
+		if hasGroup && j.State().Group() != group[0] {
+			return false
+		}
+		if status != "" && !strings.EqualFold(j.State().StatusStr(), status) {
+			return false
+		}
+		return true
+	})
+
+	out := make([]*JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, jobInfo(j))
+	}
+	writeJson(w, http.StatusOK, out)
+}
+
+func (h *handler) getJob(w http.ResponseWriter, r *http.Request) {
+	j := h.__job(w, r)
+	if j == nil {
+		return
+	}
+	writeJson(w, http.StatusOK, jobInfo(j))
+}
+
+func (h *handler) opJob(w http.ResponseWriter, r *http.Request) {
+	j := h.__job(w, r)
+	if j == nil {
+		return
+	}
+
+	switch r.PathValue("op") {
+	case "trigger":
+		if err := j.TriggerNow(); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+	case "pause" : j.Stop()
+	case "resume": j.Start()
+	case "close" : j.Close()
+	default:
+		writeError(w, http.StatusNotFound, "unknown op '" + r.PathValue("op") + "'")
+		return
+	}
+
+	writeJson(w, http.StatusOK, jobInfo(j))
+}
+
+func (h *handler) removeJob(w http.ResponseWriter, r *http.Request) {
+	if !h.t.RemoveJob(r.PathValue("name")) {
+		writeError(w, http.StatusNotFound, "job '" + r.PathValue("name") + "' not exist")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) listGroups(w http.ResponseWriter, r *http.Request) {
+	names := h.t.Groups()
+	out   := make([]GroupInfo, 0, len(names))
+	for _, name := range names {
+		if gs, ok := h.t.GroupStats(name); ok {
+			out = append(out, groupInfo(gs))
+		}
+	}
+	writeJson(w, http.StatusOK, out)
+}
+
+func (h *handler) setGroup(w http.ResponseWriter, r *http.Request) {
+	max, err := strconv.Atoi(r.FormValue("max"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid max: " + err.Error())
+		return
+	}
+
+	name := r.PathValue("name")
+	h.t.SetGroup(name, max)
+
+	gs, _ := h.t.GroupStats(name)
+	writeJson(w, http.StatusOK, groupInfo(gs))
+}
+
+func (h *handler) opTimer(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("op") {
+	case "pause" : h.t.Pause()
+	case "resume": h.t.Resume()
+	default:
+		writeError(w, http.StatusNotFound, "unknown op '" + r.PathValue("op") + "'")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// __job returns the job by the name in path, it writes 404 and returns nil if not exist
+func (h *handler) __job(w http.ResponseWriter, r *http.Request) *etimer.Job {
+	j := h.t.Job(r.PathValue("name"))
+	if j == nil {
+		writeError(w, http.StatusNotFound, "job '" + r.PathValue("name") + "' not exist")
+	}
+	return j
+}
+
+// jobInfo returns the view of job, the times are snapshotted together since the job may be running
+func jobInfo(j *etimer.Job) *JobInfo {
+	js := j.State()
+	ts := js.SnapshotTimes()
+	ji := &JobInfo{
+		Name       : js.Name(),
+		Group      : js.Group(),
+		Status     : js.StatusStr(),
+		Pattern    : js.Pattern(),
+		Singleton  : js.IsSingleton(),
+		Times      : js.Times(),
+		LeftTimes  : js.LeftTimes(),
+		Runnings   : js.Runnings(),
+		Successs   : js.Successs(),
+		Failures   : js.Failures(),
+		Retries    : js.Retries(),
+		Timeouts   : js.Timeouts(),
+		Skips      : js.Skips(),
+		NextStart  : ts.NextStart,
+		LastStart  : ts.LastStart,
+		LastEnd    : ts.LastEnd,
+		LastCost   : ts.LastCost.String(),
+		LastSuccess: ts.LastSuccess,
+		LastFailure: ts.LastFailure,
+	}
+	if ji.Pattern == "" {
+		ji.Interval = js.Interval().String()
+	}
+	if err := ts.LastError; err != nil {
+		ji.LastError = err.Error()
+	}
+	for _, e := range js.Errors() {
+		ei := ErrInfo{File: e.File(), Line: e.Line(), Func: e.Func(), Count: e.Count(), First: e.First(), Last: e.Last()}
+		if err := e.Err(); err != nil {
+			ei.Error = err.Error()
+		}
+		ji.Errors = append(ji.Errors, ei)
+	}
+
+	return ji
+}
+
+func groupInfo(gs etimer.GroupStats) GroupInfo {
+	return GroupInfo{Name: gs.Name, Running: gs.Running, Pending: gs.Pending, Max: gs.Max}
+}
+
+func writeJson(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJson(w, code, map[string]string{"error": msg})
+}
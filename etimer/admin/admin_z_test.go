@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/etimer"
+)
+
+func do(t *testing.T, h http.Handler, method, path string, out any) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	if out != nil {
+		assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+	}
+	return w.Code
+}
+
+func TestHandler(t *testing.T) {
+	tm := etimer.NewTimer(etimer.TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 2)
+
+	var cnt atomic.Int32
+	j := etimer.NewJob(&etimer.JobOpts{Name: "j1", Interval: time.Hour, CB: func(j *etimer.Job) error {
+		cnt.Add(1)
+		j.Errorf("failed %d", cnt.Load())
+		return nil
+	}})
+	assert.Equal(t, nil, tm.AddJob(j, "g1"))
+	assert.Equal(t, nil, tm.AddJob(etimer.NewJob(&etimer.JobOpts{Name: "j2", Pattern: "@hourly", CB: func(j *etimer.Job) error { return nil }})))
+
+	h := NewHandler(tm)
+
+	// list
+	var jobs []*JobInfo
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs", &jobs))
+	assert.Equal(t, 2, len(jobs))
+	assert.Equal(t, "j1", jobs[0].Name)
+	assert.Equal(t, "g1", jobs[0].Group)
+	assert.Equal(t, "1h0m0s", jobs[0].Interval)
+	assert.Equal(t, "@hourly", jobs[1].Pattern)
+
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs?group=g1", &jobs))
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs?group=", &jobs))
+	assert.Equal(t, "j2", jobs[0].Name)
+
+	// trigger
+	var ji JobInfo
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/jobs/j1/trigger", &ji))
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, int32(1), cnt.Load())
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs/j1", &ji))
+	assert.Equal(t, uint64(1), ji.Runnings)
+	assert.Equal(t, 1, len(ji.Errors))
+	assert.Equal(t, "failed 1", ji.Errors[0].Error)
+	assert.True(t, strings.HasSuffix(ji.Errors[0].File, "admin_z_test.go"))
+	assert.Greater(t, ji.Errors[0].Line, 0)
+
+	// pause, resume and close
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/jobs/j1/pause", &ji))
+	assert.Equal(t, "Stopped", ji.Status)
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/jobs/j1/resume", &ji))
+	assert.Equal(t, "Waiting", ji.Status)
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/jobs/j2/close", &ji))
+	assert.Equal(t, "Closed", ji.Status)
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs?status=closed", &jobs))
+	assert.Equal(t, 1, len(jobs))
+
+	// errors
+	assert.Equal(t, http.StatusNotFound, do(t, h, "GET", "/jobs/not_exist", nil))
+	assert.Equal(t, http.StatusNotFound, do(t, h, "POST", "/jobs/j1/unknown", nil))
+	assert.Equal(t, http.StatusConflict, do(t, h, "POST", "/jobs/j2/trigger", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, do(t, h, "GET", "/jobs/j1/trigger", nil))
+
+	// groups
+	var groups []GroupInfo
+	assert.Equal(t, http.StatusOK, do(t, h, "GET", "/groups", &groups))
+	assert.Equal(t, []GroupInfo{{Name: "g1", Max: 2}}, groups)
+	var gi GroupInfo
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/groups/g1?max=5", &gi))
+	assert.Equal(t, 5, gi.Max)
+	assert.Equal(t, http.StatusOK, do(t, h, "POST", "/groups/g2?max=1", &gi))
+	assert.Equal(t, GroupInfo{Name: "g2", Max: 1}, gi)
+	assert.Equal(t, http.StatusBadRequest, do(t, h, "POST", "/groups/g2?max=x", nil))
+
+	// timer
+	assert.Equal(t, http.StatusNoContent, do(t, h, "POST", "/pause", nil))
+	assert.Equal(t, etimer.StatusStopped, tm.Status())
+	assert.Equal(t, http.StatusNoContent, do(t, h, "POST", "/resume", nil))
+	assert.Equal(t, etimer.StatusRunning, tm.Status())
+
+	// remove
+	assert.Equal(t, http.StatusNoContent, do(t, h, "DELETE", "/jobs/j2", nil))
+	assert.Equal(t, http.StatusNotFound, do(t, h, "DELETE", "/jobs/j2", nil))
+}
+
+func TestHandlerRunning(t *testing.T) {
+	tm := etimer.NewTimer(etimer.TimerOptions{Interval: time.Millisecond})
+	h  := NewHandler(tm)
+
+	// the steps are only run by the workflow
+	var cnt atomic.Int32
+	wf := etimer.NewWorkflow(etimer.WorkflowOpts{Name: "wf", Interval: time.Hour})
+	wf.AddStep(etimer.NewJob(&etimer.JobOpts{Name: "step", CB: func(j *etimer.Job) error { cnt.Add(1); return nil }}))
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+	assert.Equal(t, http.StatusConflict, do(t, h, "POST", "/jobs/step/trigger", nil))
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, int32(0), cnt.Load())
+
+	// run with -race, the jobs are viewed while running
+	assert.Equal(t, nil, tm.AddJob(etimer.NewJob(&etimer.JobOpts{Name: "busy", Interval: time.Millisecond, CB: func(j *etimer.Job) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	}})))
+	var ji JobInfo
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, http.StatusOK, do(t, h, "GET", "/jobs/busy", &ji))
+		time.Sleep(time.Millisecond)
+	}
+	assert.Greater(t, ji.Runnings, uint64(0))
+}
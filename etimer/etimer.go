@@ -52,4 +52,14 @@ func AddInterval(ctx context.Context, interval time.Duration, cb JobFunc, single
 
 func AddCron(ctx context.Context, pattern string, cb JobFunc, singleton ...bool) (*Job, error) {
 	return defaultTimer.AddCron(ctx, pattern, cb, singleton...)
-}
\ No newline at end of file
+}
+
+// After runs fn in its own goroutine after d by the default timer, see Timer.After
+func After(ctx context.Context, d time.Duration, fn func()) *AfterTask {
+	return defaultTimer.After(ctx, d, fn)
+}
+
+// At runs fn in its own goroutine at tm by the default timer, see Timer.At
+func At(ctx context.Context, tm time.Time, fn func()) *AfterTask {
+	return defaultTimer.At(ctx, tm, fn)
+}
@@ -0,0 +1,106 @@
+package etimer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AfterTask is a one-shot task created by Timer.After or Timer.At, it is much lighter than Job: no name, no JobState,
+// no group, and the fn runs in its own goroutine like time.AfterFunc.
+// It is cheap to create, Stop and Reset, use SCHEDULER_WHEEL for a large number of them, like the idle timeouts of connections
+type AfterTask struct {
+	timer   *Timer
+	ctx     context.Context
+	fn      func()
+
+	mu      sync.Mutex
+	ticks   int64           // the ticks to run
+	pending bool
+	unwatch func() bool     // stops watching the ctx
+}
+
+// After runs fn in its own goroutine after d, it will not run if ctx is done before that
+func (t *Timer) After(ctx context.Context, d time.Duration, fn func()) *AfterTask {
+	task := &AfterTask{timer: t, ctx: ctx, fn: fn}
+	task.Reset(d)
+	return task
+}
+
+// At runs fn in its own goroutine at tm, it will not run if ctx is done before that
+func (t *Timer) At(ctx context.Context, tm time.Time, fn func()) *AfterTask {
+	return t.After(ctx, time.Until(tm), fn)
+}
+
+// Stop prevents the task from running, it returns false if the task already run or stopped
+func (task *AfterTask) Stop() bool {
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	return task.__stop()
+}
+
+// Reset reschedules the task to run after d, whether it has run or been stopped or not,
+// it returns true if the task was pending, the task will not be scheduled if the timer is shutdown or ctx is done
+func (task *AfterTask) Reset(d time.Duration) bool {
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	t   := task.timer
+	was := task.__stop()
+	if t.Status() == StatusClosed || (task.ctx != nil && task.ctx.Err() != nil) {
+		return was
+	}
+
+	ticks := int64((d + t.options.Interval - 1) / t.options.Interval)
+	if ticks <= 0 {
+		ticks = 1
+	}
+	task.ticks   = t.__ticks() + ticks
+	task.pending = true
+	if task.ctx != nil && task.ctx.Done() != nil {
+		task.unwatch = context.AfterFunc(task.ctx, func() { task.Stop() })
+	}
+
+	t.queue.Push(task, task.ticks)
+	t.__wakeup()
+	return was
+}
+
+// Pending returns true if the task is waiting to run
+func (task *AfterTask) Pending() bool {
+	task.mu.Lock()
+	defer task.mu.Unlock()
+
+	return task.pending
+}
+
+func (task *AfterTask) __stop() bool {
+	was := task.pending
+	task.pending = false
+	if task.unwatch != nil {
+		task.unwatch()
+		task.unwatch = nil
+	}
+	if was {
+		task.timer.queue.Remove(task)
+	}
+	return was
+}
+
+// __run runs the task popped from queue in curTimerTicks, it is ignored if the task has been stopped or reset to later
+func (task *AfterTask) __run(curTimerTicks int64) {
+	task.mu.Lock()
+	if !task.pending || task.ticks > curTimerTicks {
+		task.mu.Unlock()
+		return
+	}
+	task.pending = false
+	if task.unwatch != nil {
+		task.unwatch()
+		task.unwatch = nil
+	}
+	task.mu.Unlock()
+
+	go task.fn()
+}
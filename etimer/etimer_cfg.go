@@ -0,0 +1,195 @@
+package etimer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ziyht/eden_go/ecfg"
+)
+
+const cfgRootKey = "etimer"
+
+// JobCfg is the config of a job declared in config file, see LoadJobsFromFile
+type JobCfg struct {
+	Func      string         `mapstructure:"func"`
+	Pattern   string         `mapstructure:"pattern"`
+	Location  string         `mapstructure:"location"`
+	Interval  time.Duration  `mapstructure:"interval"`
+	Jitter    time.Duration  `mapstructure:"jitter"`
+	Spread    bool           `mapstructure:"spread"`
+	Group     string         `mapstructure:"group"`
+	Times     int64          `mapstructure:"times"`
+	Singleton *bool          `mapstructure:"singleton"`   // true in default, like AddInterval and AddCron
+	Args      map[string]any `mapstructure:"args"`
+}
+
+// Cfg is the config of a timer declared in config file
+type Cfg struct {
+	Groups map[string]int     `mapstructure:"groups"`
+	Jobs   map[string]*JobCfg `mapstructure:"jobs"`
+}
+
+func cfgFromFile(path string) (*Cfg, error) {
+	cfg := &Cfg{}
+	if err := ecfg.ParsingFromCfgFile(path, cfgRootKey, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s from path %s: %s", cfgRootKey, path, err)
+	}
+	return cfg, nil
+}
+
+// LoadJobsFromFile loads the groups and jobs declared in config file to the timer, support multi file types like yaml, yml, json, toml...
+//
+// the format should like follows:
+// etimer:
+//   groups:
+//     g1: 3                       # the max running jobs of group
+//   jobs:
+//     job1:
+//       func     : cleanup        # the name of JobFunc registered by RegisterFunc
+//       pattern  : "0 * * * *"    # cron pattern, has high priority than interval, see JobOpts for the format
+//       location : Asia/Shanghai  # the location of pattern, local in default
+//       interval : 10s
+//       jitter   : 2s             # delays each run by a random duration in [0, jitter)
+//       spread   : true           # delays the runs by a stable offset hashed from the job name
+//       group    : g1
+//       times    : 10
+//       singleton: true
+//       args     :
+//         dir: /tmp
+//
+// the funcs must be registered before loading, the jobs already exist will be skipped
+func (t *Timer) LoadJobsFromFile(path string) error {
+	cfg, err := cfgFromFile(path)
+	if err != nil {
+		return err
+	}
+
+	for name, max := range cfg.Groups {
+		t.SetGroup(name, max)
+	}
+
+	var errs []string
+	for name, jc := range cfg.Jobs {
+		t.mu.RLock()
+		exist := t.jobs[name] != nil
+		t.mu.RUnlock()
+		if exist {
+			continue
+		}
+		if jc.Pattern == "" && jc.Interval <= 0 {
+			errs = append(errs, fmt.Sprintf("job '%s' should have a pattern or an interval > 0", name))
+			continue
+		}
+
+		loc, err := loadLocation(jc.Location)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("job '%s' has invalid location: %s", name, err))
+			continue
+		}
+
+		j := createJob(JobOpts{
+			Name       : name,
+			Pattern    : jc.Pattern,
+			Location   : loc,
+			Interval   : jc.Interval,
+			Jitter     : jc.Jitter,
+			Spread     : jc.Spread,
+			Func       : jc.Func,
+			Args       : jc.Args,
+			IsSingleton: jc.Singleton == nil || *jc.Singleton,
+			Times      : jc.Times,
+			status     : StatusWaiting,
+		})
+
+		var group []string
+		if jc.Group != "" {
+			group = append(group, jc.Group)
+		}
+		if err := t.AddJob(j, group...); err != nil {
+			errs = append(errs, fmt.Sprintf("add job '%s' failed: %s", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("load jobs from file '%s' failed: %s", path, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// RestoreJobs rehydrates the jobs persisted in TimerOptions.Store, the progresses of them will be restored too,
+// only the jobs with a registered Func can be restored, the jobs already exist will be skipped.
+// it is called by NewTimer when the Store is set, call it again after registering the Funcs later
+func (t *Timer) RestoreJobs() error {
+	if t.options.Store == nil {
+		return fmt.Errorf("the Store of timer is not set")
+	}
+	return t.__restoreJobs(false)
+}
+
+// __restoreJobs restores the jobs in store, the ones without a registered Func are skipped if skipNoFunc,
+// they will be restored by name when they are added by AddJob with the CB
+func (t *Timer) __restoreJobs(skipNoFunc bool) error {
+	recs, err := t.options.Store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, rec := range recs {
+		t.mu.RLock()
+		exist := t.jobs[rec.Name] != nil
+		t.mu.RUnlock()
+		if exist || (skipNoFunc && getFunc(rec.Func) == nil) {
+			continue
+		}
+
+		loc, err := loadLocation(rec.Location)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("restore job '%s' failed: %s", rec.Name, err))
+			continue
+		}
+
+		j := createJob(JobOpts{
+			Name       : rec.Name,
+			Pattern    : rec.Pattern,
+			Location   : loc,
+			Interval   : rec.Interval,
+			Func       : rec.Func,
+			Args       : rec.Args,
+			IsSingleton: rec.IsSingleton,
+			Times      : rec.Times,
+			Jitter     : rec.Jitter,
+			Spread     : rec.Spread,
+			StartAt    : rec.StartAt,
+			EndAt      : rec.EndAt,
+			status     : StatusWaiting,
+		})
+
+		var group []string
+		if rec.Group != "" {
+			group = append(group, rec.Group)
+		}
+		if err := t.AddJob(j, group...); err != nil {
+			errs = append(errs, fmt.Sprintf("restore job '%s' failed: %s", rec.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// loadLocation returns nil for an empty name
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// LoadJobsFromFile loads the groups and jobs declared in config file to the default timer, see Timer.LoadJobsFromFile
+func LoadJobsFromFile(path string) error {
+	return defaultTimer.LoadJobsFromFile(path)
+}
@@ -0,0 +1,39 @@
+package etimer
+
+import (
+	"fmt"
+	"sync"
+)
+
+var funcs sync.Map   // name -> JobFunc
+
+// RegisterFunc registers a JobFunc by name, so the jobs can refer to it by JobOpts.Func,
+// it is needed for the jobs loaded from config files or restored from JobStore, since the callbacks can not be serialized
+func RegisterFunc(name string, fn JobFunc) error {
+	if name == "" {
+		return fmt.Errorf("the name of func should not be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("the func '%s' to register is nil", name)
+	}
+
+	if _, loaded := funcs.LoadOrStore(name, fn); loaded {
+		return fmt.Errorf("func '%s' already registered", name)
+	}
+	return nil
+}
+
+// UnregisterFunc removes the JobFunc registered by name, the jobs created already will not be affected
+func UnregisterFunc(name string) {
+	funcs.Delete(name)
+}
+
+func getFunc(name string) JobFunc {
+	if name == "" {
+		return nil
+	}
+	if fn, ok := funcs.Load(name); ok {
+		return fn.(JobFunc)
+	}
+	return nil
+}
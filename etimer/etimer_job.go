@@ -2,6 +2,8 @@ package etimer
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ziyht/eden_go/eerr"
@@ -10,7 +12,24 @@ import (
 type Job struct {
 	name        string
 	cb          JobFunc
+	fn          string
+	args        map[string]any
+	loc         *time.Location
+	retry       *RetryPolicy
+	timeout     time.Duration
+	overlap     OverlapPolicy
+	deps        []string
+	trigger     <-chan struct{}
+	sopts       scheduleOpts
+	after       func(err error)      // called after each run with the final error, for the steps of workflow
 	ctx         context.Context
+	jctx        context.Context      // derived from ctx, cancelled when the job is closed or the timer shutdown
+	jcancel     context.CancelFunc
+
+	runMu       sync.Mutex
+	runCtx      context.Context      // the ctx of current run, derived from jctx
+	runCancel   context.CancelFunc
+	retrying    *pendingRetry        // the run waiting for its next attempt, guarded by runMu
 
 	sched       schedule
 	js          JobState
@@ -18,6 +37,15 @@ type Job struct {
 	timer       *Timer
 }
 
+// the format of Pattern:
+//   1. the standard 5 fields: minute hour dom month dow, like "30 2 * * *"
+//   2. an optional seconds field at the beginning: second minute hour dom month dow, like "*/10 * * * * *"
+//   3. the descriptors: @yearly, @monthly, @weekly, @daily, @hourly and @every <duration>, like "@every 1m30s"
+//   4. a CRON_TZ= or TZ= prefix to set the location, like "CRON_TZ=Asia/Shanghai 0 8 * * *"
+//   5. multi patterns can be joined by ';', the job runs when any of them reached
+//
+// for DST, the times not exist are skipped, and the times appear twice run twice
+
 // JobFunc is the timing called job function in timer.
 type JobFunc = func(job *Job) error
 
@@ -25,10 +53,22 @@ type JobOpts struct {
 	Name        string             // the name of the job, if not set, it will be replaced with internal generated uuid
 	Ctx         context.Context    // context, to pass in needed parameters for the job
 	Interval    time.Duration      // interval for job to run
-	Pattern     string             // cron pattern to run job, has high priority than Interval
+	Pattern     string             // cron pattern to run job, has high priority than Interval, see below for the format
+	Location    *time.Location     // the location of Pattern, time.Local in default, it is overwritten by the CRON_TZ= prefix in Pattern
 	CB          JobFunc            // callback function of job
+	Func        string             // the name of a registered JobFunc, it is used when CB is not set, see RegisterFunc
+	Args        map[string]any     // the args of job, they can be got by Job.Args() in the callback
 	IsSingleton bool               // set singleton
 	Times       int64              // set limit running times
+	Retry       *RetryPolicy       // retry the failed runs, no retry if not set
+	Timeout     time.Duration      // the ctx of each run will be cancelled after Timeout, no timeout if <= 0
+	Overlap     OverlapPolicy      // what to do when the job is scheduled while its previous run is still running
+	DependsOn   []string           // the names of the steps in the same Workflow which must succeed before this job runs
+	Trigger     <-chan struct{}    // the job runs once for each receiving from Trigger, Interval and Pattern can be empty if it is set
+	Jitter      time.Duration      // delays each run by a random duration in [0, Jitter) rounded up to the ticks of timer, it should be less than the interval
+	Spread      bool               // delays the runs by a stable offset within the interval hashed from Name, the runs of Interval are aligned to the wall clock
+	StartAt     time.Time          // the job does not run before StartAt if set
+	EndAt       time.Time          // the job does not run after EndAt if set, and it is stopped after that
 	status      int32
 }
 
@@ -42,6 +82,79 @@ func (j *Job) RecordError(err error) {
 	j.js.recordError(eerr.PackSkip(1, err))
 }
 
+// TriggerNow submits a run of job immediately, even if the job is stopped, it respects the singleton and group limits,
+// and is counted in Times like the scheduled runs. The steps of workflow can not be triggered, they are only run by the workflow
+func (j *Job) TriggerNow() error {
+	if err := j.__checkTrigger(); err != nil {
+		return err
+	}
+
+	j.timer.submitJob(j)
+	return nil
+}
+
+// TriggerAt submits a run of job at t like TriggerNow, it will be cancelled if the job is closed before t
+func (j *Job) TriggerAt(t time.Time) error {
+	if err := j.__checkTrigger(); err != nil {
+		return err
+	}
+
+	go func() {
+		tm := time.NewTimer(time.Until(t))
+		defer tm.Stop()
+
+		select {
+		case <-tm.C:
+			j.TriggerNow()
+		case <-j.jctx.Done():
+		}
+	}()
+	return nil
+}
+
+func (j *Job) __checkTrigger() error {
+	t := j.timer
+	if t == nil {
+		return fmt.Errorf("job '%s' is not added to a timer", j.name)
+	}
+	if t.Status() == StatusClosed {
+		return fmt.Errorf("timer is shutdown")
+	}
+	if j.js.Status() == StatusClosed {
+		return fmt.Errorf("job '%s' is closed", j.name)
+	}
+	if j.IsStep() {
+		return fmt.Errorf("job '%s' is a step of workflow, trigger the workflow instead", j.name)
+	}
+	if j.js.Times() > 0 && j.js.LeftTimes() <= 0 {
+		return fmt.Errorf("job '%s' has run %d times", j.name, j.js.Times())
+	}
+	return nil
+}
+
+// __watchTrigger runs the job for each receiving from JobOpts.Trigger, the receivings are ignored if the job is stopped
+func (j *Job) __watchTrigger() {
+	if j.trigger == nil {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case _, ok := <-j.trigger:
+				if !ok {
+					return
+				}
+				if j.js.Status() != StatusStopped {
+					j.TriggerNow()
+				}
+			case <-j.jctx.Done():
+				return
+			}
+		}
+	}()
+}
+
 // Start starts the job.
 func (j *Job) Start() {
 	j.js.setStatus(StatusWaiting)
@@ -52,8 +165,10 @@ func (j *Job) Stop() {
 	j.js.setStatus(StatusStopped)
 }
 
+// Close closes the job, the ctx of job and its running run will be cancelled
 func (j *Job) Close(){
 	j.js.setStatus(StatusClosed)
+	j.__cancel()
 }
 
 func (j *Job) IsSingleton() bool {
@@ -64,10 +179,50 @@ func (j *Job) SetSingleton(enable bool) {
 	j.js.setSingleton(enable)
 }
 
+// Ctx returns the ctx of the current run in JobFunc, it is cancelled when the run timeout, the job closed or the timer shutdown,
+// the values in JobOpts.Ctx can be got from it
 func (j *Job) Ctx() context.Context {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	if j.runCtx != nil {
+		return j.runCtx
+	}
+	if j.jctx != nil {
+		return j.jctx
+	}
 	return j.ctx
 }
 
+func (j *Job) Name() string {
+	return j.name
+}
+
+// IsStep returns true if the job is a step of workflow, which is only run by the workflow
+func (j *Job) IsStep() bool {
+	return j.after != nil
+}
+
+// NextRuns returns the next n start times of job, it returns nil if the job is not added to a timer
+func (j *Job) NextRuns(n int) []time.Time {
+	if j.sched == nil || n <= 0 {
+		return nil
+	}
+
+	from := time.Now()
+	var out []time.Time
+	if ns := j.js.NextStart(); ns.After(from) {
+		out  = append(out, ns)
+		from = ns
+	}
+	return append(out, nextRuns(j.sched, from, n - len(out))...)
+}
+
+// Args returns the args set in JobOpts.Args, it should not be modified
+func (j *Job) Args() map[string]any {
+	return j.args
+}
+
 func (j *Job) SetTimes(times int64) {
 	j.js.setTimes(times)
 }
@@ -0,0 +1,106 @@
+package etimer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// OverlapPolicy decides what to do when a job is scheduled while its previous run is still running
+type OverlapPolicy int
+
+const (
+	OVERLAP_ALLOW   OverlapPolicy = iota   // queue the new run, singleton jobs keep one pending run, the others keep all, and they run one by one
+	OVERLAP_SKIP                           // skip the new run
+	OVERLAP_RESTART                        // cancel the ctx of the running one, and run again after it returns
+)
+
+// ErrJobTimeout is the error of a run which exceeds JobOpts.Timeout
+var ErrJobTimeout = errors.New("job timeout")
+
+// __initCtx creates the ctx of job which is cancelled when the job is closed or the parent is done
+func (j *Job) __initCtx(parent context.Context) {
+	base := j.ctx
+	if base == nil {
+		base = context.Background()
+	}
+
+	j.jctx, j.jcancel = context.WithCancel(base)
+	if parent != nil {
+		stop := context.AfterFunc(parent, j.jcancel)
+		context.AfterFunc(j.jctx, func() { stop() })
+	}
+}
+
+// __cancel cancels the ctx of job and its runs
+func (j *Job) __cancel() {
+	j.js.mu.Lock()
+	cancel := j.jcancel
+	j.js.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// __done returns the done channel of job ctx
+func (j *Job) __done() <-chan struct{} {
+	if j.jctx != nil {
+		return j.jctx.Done()
+	}
+	if j.ctx != nil {
+		return j.ctx.Done()
+	}
+	return nil
+}
+
+// __call calls the JobFunc once with a derived ctx, which is cancelled after JobOpts.Timeout
+func (j *Job) __call() (err error) {
+	parent := j.jctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if j.timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, j.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	j.runMu.Lock()
+	j.runCtx, j.runCancel = ctx, cancel
+	j.runMu.Unlock()
+
+	defer func() {
+		j.runMu.Lock()
+		if j.runCtx == ctx {
+			j.runCtx, j.runCancel = nil, nil
+		}
+		j.runMu.Unlock()
+		cancel()
+	}()
+
+	err = j.cb(j)
+
+	if j.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		j.js.addTimeout()
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w after %s", ErrJobTimeout, j.timeout)
+		}
+	}
+
+	return
+}
+
+// __cancelRun cancels the ctx of the current run
+func (j *Job) __cancelRun() {
+	j.runMu.Lock()
+	cancel := j.runCancel
+	j.runMu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
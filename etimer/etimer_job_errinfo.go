@@ -24,6 +24,7 @@ func (e *errInfo) Count() uint64    { return e.cnt }
 func (e *errInfo) File()  string    { return e.frame.File() }
 func (e *errInfo) Func()  string    { return e.frame.Func() }
 func (e *errInfo) Line()  int       { return e.frame.Line() }
+func (e *errInfo) Err()   error     { return e.err }
 
 type errInfos struct {
 	mu    sync.Mutex
@@ -70,6 +71,15 @@ func (es *errInfos)setError(err error, t time.Time) {
 	return 
 }
 
+// Errs returns the copies of errInfos, they are updated under lock while the job is running
 func (es *errInfos)Errs() []*errInfo {
-	return es.errsl
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	out := make([]*errInfo, 0, len(es.errsl))
+	for _, e := range es.errsl {
+		c := *e
+		out = append(out, &c)
+	}
+	return out
 }
\ No newline at end of file
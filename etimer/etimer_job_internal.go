@@ -1,6 +1,8 @@
 package etimer
 
 import (
+	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -13,12 +15,21 @@ func createJob(in JobOpts) (*Job) {
 	j := &Job{
 		name:        in.Name,
 		cb:          in.CB,
+		fn:          in.Func,
+		args:        in.Args,
+		loc:         in.Location,
+		retry:       in.Retry,
+		timeout:     in.Timeout,
+		overlap:     in.Overlap,
+		deps:        in.DependsOn,
+		trigger:     in.Trigger,
 		ctx:         in.Ctx,
 	}
 
 	if j.name == "" {
 		j.name = uuid.NewV1().String()
 	}
+	j.sopts = newScheduleOpts(j.name, in.Jitter, in.Spread, in.StartAt, in.EndAt)
 
 	j.js.runner = dfRunner
 	j.js.errs   = newErrInfos()
@@ -46,13 +57,40 @@ func createJob(in JobOpts) (*Job) {
 }
 
 func (j *Job) exec_once() {
-	if j.js.checkLimit(-1){
+	if j.after == nil {
+		j.__exec_once()
 		return
 	}
-	
-	start := time.Now()
-	
-	j.js.addRunning(start)
+
+	err := ErrJobSkipped
+	defer func() {
+		if e := recover(); e != nil {
+			j.after(fmt.Errorf("job panic: %v", e))
+			panic(e)
+		}
+		if err != errRetryLater {
+			j.after(err)
+		}
+	}()
+	err = j.__exec_once()
+}
+
+// __exec_once runs the job once and returns the final error of the run, ErrJobSkipped if the run is skipped,
+// errRetryLater if the next attempt of the run is scheduled
+func (j *Job) __exec_once() (err error) {
+	start, attempt, err := j.__takeRetry()
+	if attempt == 0 {
+		if j.timer != nil && j.timer.Status() == StatusClosed {
+			return ErrJobSkipped
+		}
+		if j.js.checkLimit(-1){
+			return ErrJobSkipped
+		}
+
+		start, attempt = time.Now(), 1
+
+		j.js.addRunning(start)
+	}
 
 	defer func() {
 		end   := time.Now()
@@ -70,6 +108,7 @@ func (j *Job) exec_once() {
 			} else {
 				j.Close()
 				j.js.addRunningOver(start, end, nil)
+				j.__persist()
 				return
 			}
 		}
@@ -78,6 +117,89 @@ func (j *Job) exec_once() {
 		}
 	}()
 
-	err   := j.cb(j)
+	// the retry of a stopped job is finished with the error of last attempt
+	if attempt == 1 || !j.__stopped() {
+		err = j.__call()
+	}
+	if j.retry.retryable(attempt, err) && j.__retryLater(start, attempt, err) {
+		return errRetryLater
+	}
 	j.js.addRunningOver(start, time.Now(), err)
-}
\ No newline at end of file
+	j.__persist()
+
+	return err
+}
+
+// __stopped returns true if the job is closed, or its ctx is done
+func (j *Job) __stopped() bool {
+	if j.js.Status() == StatusClosed || (j.timer != nil && j.timer.Status() == StatusClosed) {
+		return true
+	}
+	select {
+	case <-j.__done():
+		return true
+	default:
+		return false
+	}
+}
+
+// __retryLater schedules the next attempt of the run through the timer queue, so the worker and the slots of singleton
+// and group are released during the backoff. It returns false if the job is stopped or another run is waiting to retry
+func (j *Job) __retryLater(start time.Time, attempt int, err error) bool {
+	if j.timer == nil || j.jctx == nil || j.__stopped() {
+		return false
+	}
+
+	j.runMu.Lock()
+	if j.retrying != nil {
+		j.runMu.Unlock()
+		return false
+	}
+	pr := &pendingRetry{attempt: attempt + 1, start: start, err: err}
+	j.retrying = pr
+	j.runMu.Unlock()
+
+	j.js.addRetry(attempt, err)
+
+	// the run is finished if the job is closed or the timer shutdown during the backoff
+	abort := context.AfterFunc(j.jctx, j.__finishRetry)
+	j.timer.After(nil, j.retry.backoff(attempt), func() {
+		if abort() {
+			j.runMu.Lock()
+			pr.ready = true
+			j.runMu.Unlock()
+			j.js.runner.submitN(j, 1)
+		}
+	})
+	return true
+}
+
+// __takeRetry takes the run whose backoff is over, attempt is 0 if no one
+func (j *Job) __takeRetry() (start time.Time, attempt int, err error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	if pr := j.retrying; pr != nil && pr.ready {
+		j.retrying = nil
+		return pr.start, pr.attempt, pr.err
+	}
+	return
+}
+
+// __finishRetry finishes the run waiting to retry with the error of its last attempt
+func (j *Job) __finishRetry() {
+	j.runMu.Lock()
+	pr := j.retrying
+	j.retrying = nil
+	j.runMu.Unlock()
+
+	if pr == nil {
+		return
+	}
+
+	j.js.addRunningOver(pr.start, time.Now(), pr.err)
+	j.__persist()
+	if j.after != nil {
+		j.after(pr.err)
+	}
+}
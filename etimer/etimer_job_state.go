@@ -25,13 +25,19 @@ type JobState struct {
 	runnings    uint64         // 运行的次数
 	failures    uint64         // 失败次数
 	successs    uint64         // 成功次数
+	retries     uint64         // 重试次数
+	timeouts    uint64         // 超时次数
+	skips       uint64         // 跳过次数, 如 Workflow 中上游失败时
+	attempt     int32          // 当前运行的尝试次数, 从 1 开始
 
+	tmu         sync.RWMutex   // 保护下面的时间和错误字段, 它们在运行中被修改, 同时被 record 和 admin 读取
 	nextStart   time.Time      // 下一次开始时间
 	lastStart   time.Time      // 上一次开始时间
 	lastEnd     time.Time      // 上一次结束时间
 	lastCost    time.Duration  // 上一次运行耗时
 	lastSuccess time.Time      // 上一次成功的时间
 	lastFailure time.Time      // 上一次失败的时间
+	lastSkip    time.Time      // 上一次跳过的时间
 	lastError   error          // 上一次失败的错误信息
 
 	errs        *errInfos
@@ -83,7 +89,28 @@ func (js *JobState)addPendRun(p, r int32) (int32, int32) {
 }
 func (js *JobState)addRunning(start time.Time) {
 	atomic.AddUint64(&js.runnings, 1)
+	atomic.StoreInt32(&js.attempt, 1)
+	js.tmu.Lock()
 	js.lastStart = start
+	js.tmu.Unlock()
+}
+
+func (js *JobState)addSkip() {
+	atomic.AddUint64(&js.skips, 1)
+	js.tmu.Lock()
+	js.lastSkip = time.Now()
+	js.tmu.Unlock()
+}
+
+func (js *JobState)addTimeout() {
+	atomic.AddUint64(&js.timeouts, 1)
+}
+
+// addRetry records the failed attempt which will be retried
+func (js *JobState)addRetry(attempt int, err error) {
+	atomic.AddUint64(&js.retries, 1)
+	atomic.StoreInt32(&js.attempt, int32(attempt + 1))
+	js.recordError(err)
 }
 
 func (js *JobState)checkLimit(cnt int)bool{
@@ -106,6 +133,9 @@ func (js *JobState)checkLimit(cnt int)bool{
 }
 
 func (js *JobState)addRunningOver(start, end time.Time, err error) {
+	js.tmu.Lock()
+	defer js.tmu.Unlock()
+
 	js.lastStart = start
 	js.lastEnd   = end
 	js.lastCost  = end.Sub(start)
@@ -116,6 +146,7 @@ func (js *JobState)addRunningOver(start, end time.Time, err error) {
 	} else {
 		atomic.AddUint64(&js.failures, 1)
 		js.lastFailure = end
+		js.lastError   = err
 	}
 }
 
@@ -126,31 +157,79 @@ func (js *JobState)recordError(err error, t ...time.Time) {
 		js.errs.setError(err, time.Now())
 	}
 
+	js.tmu.Lock()
 	js.lastError = err
+	js.tmu.Unlock()
+}
+
+func (js *JobState)setNextStart(t time.Time) {
+	js.tmu.Lock()
+	js.nextStart = t
+	js.tmu.Unlock()
+}
+
+// JobTimes is a snapshot of the time and error fields of JobState, they are taken together under lock,
+// so they are consistent with each other while the job is running
+type JobTimes struct {
+	NextStart   time.Time
+	LastStart   time.Time
+	LastEnd     time.Time
+	LastCost    time.Duration
+	LastSuccess time.Time
+	LastFailure time.Time
+	LastSkip    time.Time
+	LastError   error
+}
+
+// SnapshotTimes returns a consistent snapshot of the time and error fields
+func (js *JobState)SnapshotTimes() JobTimes {
+	js.tmu.RLock()
+	defer js.tmu.RUnlock()
+
+	return JobTimes{
+		NextStart  : js.nextStart,
+		LastStart  : js.lastStart,
+		LastEnd    : js.lastEnd,
+		LastCost   : js.lastCost,
+		LastSuccess: js.lastSuccess,
+		LastFailure: js.lastFailure,
+		LastSkip   : js.lastSkip,
+		LastError  : js.lastError,
+	}
 }
 
 
 func (js *JobState)Name()      string { return js.name }
+func (js *JobState)Group()     string { if js.runner == nil || js.runner.name_ == dfRunnerName { return "" }; return js.runner.name_ }
 func (js *JobState)Interval()  time.Duration { return js.interval }
+func (js *JobState)Pattern()   string { return js.pattern }
 func (js *JobState)Status()    int    { return int(atomic.LoadInt32(&js.status)) }
 func (js *JobState)StatusStr() string { return statusString(atomic.LoadInt32(&js.status)) }
 func (js *JobState)Runnings()  uint64 { return atomic.LoadUint64(&js.runnings) }
 func (js *JobState)Failures()  uint64 { return atomic.LoadUint64(&js.failures) }
 func (js *JobState)Successs()  uint64 { return atomic.LoadUint64(&js.successs) }
+func (js *JobState)Retries()   uint64 { return atomic.LoadUint64(&js.retries) }
+func (js *JobState)Timeouts()  uint64 { return atomic.LoadUint64(&js.timeouts) }
+func (js *JobState)Skips()     uint64 { return atomic.LoadUint64(&js.skips) }
+func (js *JobState)Attempt()   int    { return int(atomic.LoadInt32(&js.attempt)) }  // return the attempt of current or last run, starts from 1
 
 func (js *JobState)Times()       int64 { return atomic.LoadInt64(&js.times) }  // return limit times of job
 func (js *JobState)LeftTimes()   int64 { return atomic.LoadInt64(&js.leftTimes) }
 func (js *JobState)IsSingleton() bool  { return atomic.LoadInt32(&js.singleton) > 0 }
 
-func (js *JobState)NextStart() time.Time { return js.nextStart }
+func (js *JobState)NextStart() time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.nextStart }
 
-func (js *JobState)LastStart() time.Time { return js.lastStart }
-func (js *JobState)LastEnd()   time.Time { return js.lastEnd }
-func (js *JobState)LastCost()  time.Duration { return js.lastCost }
+func (js *JobState)LastStart() time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastStart }
+func (js *JobState)LastEnd()   time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastEnd }
+func (js *JobState)LastCost()  time.Duration { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastCost }
 
-func (js *JobState)LastSuccess()  time.Time { return js.lastSuccess }
-func (js *JobState)LastFailure()  time.Time { return js.lastFailure }
-func (js *JobState)LastError(clear ...bool) error { err := js.lastError; if len(clear) > 0 && clear[0] { js.lastError = nil }; return err}
+func (js *JobState)LastSuccess()  time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastSuccess }
+func (js *JobState)LastFailure()  time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastFailure }
+func (js *JobState)LastSkip()     time.Time { js.tmu.RLock(); defer js.tmu.RUnlock(); return js.lastSkip }
+func (js *JobState)LastError(clear ...bool) error {
+	js.tmu.Lock(); defer js.tmu.Unlock()
+	err := js.lastError; if len(clear) > 0 && clear[0] { js.lastError = nil }; return err
+}
 
 func (js *JobState)Errors() []*errInfo { return js.errs.Errs() }
 
@@ -175,19 +254,24 @@ func (js *JobState)Format(s fmt.State, verb rune) {
 const jsTimeFormat = "2006-01-02T15:04:05.999999"
 
 func (js *JobState)formatLevel1() string {
+	ts := js.SnapshotTimes()
 	return fmt.Sprintf("lastStart: %s, lastEnd: %-s, next: %-s, runnings: %d(%d|%d)", 
-		js.lastStart.Format(jsTimeFormat),
-		js.lastEnd.Format(jsTimeFormat),
-		js.nextStart.Format(jsTimeFormat),
-    js.runnings, js.successs, js.failures)
+		ts.LastStart.Format(jsTimeFormat),
+		ts.LastEnd.Format(jsTimeFormat),
+		ts.NextStart.Format(jsTimeFormat),
+    js.Runnings(), js.Successs(), js.Failures())
 }
 
 func (js *JobState)formatLevel2() string {
+	ts := js.SnapshotTimes()
 	return fmt.Sprintf(`name       : %s
 status     : %s
 runnings   : %d
 failures   : %d
 successs   : %d
+retries    : %d
+timeouts   : %d
+skips      : %d
 nextStart  : %s
 lastStart  : %s
 lastEnd    : %s
@@ -197,15 +281,18 @@ lastFailure: %s
 lastError  : %s
 `, 
 		js.name,
-		statusString(js.status),
-		js.runnings,
-		js.failures,
-		js.successs,
-		js.nextStart.Format(jsTimeFormat),
-		js.lastStart.Format(jsTimeFormat),
-		js.lastEnd.Format(jsTimeFormat),
-		js.lastCost,
-		js.lastSuccess.Format(jsTimeFormat),
-		js.lastFailure.Format(jsTimeFormat),
-    js.lastError)
+		statusString(atomic.LoadInt32(&js.status)),
+		js.Runnings(),
+		js.Failures(),
+		js.Successs(),
+		js.Retries(),
+		js.Timeouts(),
+		js.Skips(),
+		ts.NextStart.Format(jsTimeFormat),
+		ts.LastStart.Format(jsTimeFormat),
+		ts.LastEnd.Format(jsTimeFormat),
+		ts.LastCost,
+		ts.LastSuccess.Format(jsTimeFormat),
+		ts.LastFailure.Format(jsTimeFormat),
+    ts.LastError)
 }
\ No newline at end of file
@@ -16,6 +16,7 @@ type priorityQueueItem struct {
 // priorityQueueHeap is a heap manager, of which the underlying `array` is a array implementing a heap structure.
 type priorityQueueHeap struct {
 	array []priorityQueueItem
+	index map[interface{}]int   // the index of values in array, for Remove and Push of the values in heap
 }
 
 // priorityQueue is an abstract data type similar to a regular queue or stack data structure in which
@@ -32,7 +33,7 @@ type priorityQueue struct {
 // newPriorityQueue creates and returns a priority queue.
 func newPriorityQueue() *priorityQueue {
 	queue := &priorityQueue{
-		heap:         &priorityQueueHeap{array: make([]priorityQueueItem, 0)},
+		heap:         &priorityQueueHeap{array: make([]priorityQueueItem, 0), index: map[interface{}]int{}},
 		nextPriority: math.MaxInt64,
 	}
 	heap.Init(queue.heap)
@@ -44,12 +45,18 @@ func (q *priorityQueue) NextPriority() int64 {
 	return  atomic.LoadInt64(&q.nextPriority)
 }
 
-// Push pushes a value to the queue.
+// Push pushes a value to the queue, the priority is updated if the value is already in queue.
 // The `priority` specifies the priority of the value.
 // The lesser the `priority` value the higher priority of the `value`.
 func (q *priorityQueue) Push(value interface{}, priority int64) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	if i, ok := q.heap.index[value]; ok {
+		q.heap.array[i].priority = priority
+		heap.Fix(q.heap, i)
+		q.__updateNextPriority()
+		return
+	}
 	heap.Push(q.heap, priorityQueueItem{
 		value:    value,
 		priority: priority,
@@ -87,6 +94,47 @@ func (q *priorityQueue) Fetch() interface{} {
 	return nil
 }
 
+// PopReached retrieves, removes and returns the most high priority value if its priority <= `priority`, or nil.
+func (q *priorityQueue) PopReached(priority int64) interface{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	v := heap.Fetch(q.heap)
+	if v == nil || v.(priorityQueueItem).priority > priority {
+		return nil
+	}
+	heap.Pop(q.heap)
+	q.__updateNextPriority()
+	return v.(priorityQueueItem).value
+}
+
+// Remove removes the value from the queue in O(log n), it returns false if the value not in queue.
+func (q *priorityQueue) Remove(value interface{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i, ok := q.heap.index[value]
+	if !ok {
+		return false
+	}
+	heap.Remove(q.heap, i)
+	q.__updateNextPriority()
+	return true
+}
+
+// Len returns the count of values in queue.
+func (q *priorityQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.heap.array)
+}
+
+func (q *priorityQueue) __updateNextPriority() {
+	var nextPriority int64 = math.MaxInt64
+	if len(q.heap.array) > 0 {
+		nextPriority = q.heap.array[0].priority
+	}
+	atomic.StoreInt64(&q.nextPriority, nextPriority)
+}
+
 // Len is used to implement the interface of sort.Interface.
 func (h *priorityQueueHeap) Len() int {
 	return len(h.array)
@@ -104,11 +152,15 @@ func (h *priorityQueueHeap) Swap(i, j int) {
 		return
 	}
 	h.array[i], h.array[j] = h.array[j], h.array[i]
+	h.index[h.array[i].value] = i
+	h.index[h.array[j].value] = j
 }
 
 // Push pushes an item to the heap.
 func (h *priorityQueueHeap) Push(x interface{}) {
-	h.array = append(h.array, x.(priorityQueueItem))
+	item := x.(priorityQueueItem)
+	h.index[item.value] = len(h.array)
+	h.array = append(h.array, item)
 }
 
 // Pop retrieves, removes and returns the most high priority item from the heap.
@@ -119,6 +171,7 @@ func (h *priorityQueueHeap) Pop() interface{} {
 	}
 	item := h.array[length-1]
 	h.array = h.array[0 : length-1]
+	delete(h.index, item.value)
 	return item
 }
 
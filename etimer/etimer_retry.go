@@ -0,0 +1,76 @@
+package etimer
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ziyht/eden_go/erand"
+)
+
+const (
+	dfRetryBackoff    = time.Second
+	dfRetryMultiplier = 2
+)
+
+// RetryPolicy retries a run of job when its JobFunc returns an error, the job returns to its normal schedule
+// after a success or all the attempts failed, the retries of a run are not counted in JobOpts.Times.
+// The retries are scheduled by the timer, the singleton and group slots are released during the backoff
+type RetryPolicy struct {
+	MaxAttempts int                   // the max attempts of a run, including the first one, no retry if <= 1
+	Backoff     time.Duration         // the delay before the first retry, 1s in default
+	MaxBackoff  time.Duration         // the max delay of retries, no limit if <= 0
+	Multiplier  float64               // the delay grows by Multiplier after each retry, 2 in default, set 1 for a constant delay
+	Jitter      float64               // randomizes each delay in [delay*(1-Jitter), delay*(1+Jitter)], should be in [0, 1]
+	Retryable   func(err error) bool  // decides if an error is retryable, all the errors are retryable if not set
+}
+
+// retryable returns true if another attempt should be made after the attempt failed with err
+func (p *RetryPolicy) retryable(attempt int, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// backoff returns the delay after the attempt failed, attempt starts from 1
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = dfRetryBackoff
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = dfRetryMultiplier
+	}
+
+	d := float64(delay)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if p.MaxBackoff > 0 && d >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if jitter := p.Jitter; jitter > 0 {
+		if jitter > 1 {
+			jitter = 1
+		}
+		d += d * jitter * (erand.Float64() * 2 - 1)
+	}
+
+	return time.Duration(d)
+}
+
+// errRetryLater is returned by Job.__exec_once when the next attempt of the run is scheduled
+var errRetryLater = errors.New("retry later")
+
+// pendingRetry is a run of job waiting for its next attempt in the timer queue
+type pendingRetry struct {
+	attempt int         // the next attempt
+	start   time.Time   // the start of the run
+	err     error       // the error of last attempt
+	ready   bool        // the backoff is over and the run is submitted to the runner
+}
@@ -3,6 +3,7 @@ package etimer
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/emirpasic/gods/lists/singlylinkedlist"
@@ -19,9 +20,13 @@ type runner struct {
 	tasksl *singlylinkedlist.List
 
 	mu     sync.Mutex
+	quit   sync.Once
+	busy   atomic.Int32     // the count of running jobs, the running workers of pool contain the idle ones
 }
 
-var dfRunner = newRunner("(INNER_UNLIMITED)", -1)
+const dfRunnerName = "(INNER_UNLIMITED)"
+
+var dfRunner = newRunner(dfRunnerName, -1)
 
 func newRunner(name string, max int) *runner {
 	pool, _ := ants.NewPool(max)
@@ -77,7 +82,18 @@ func (r *runner) submit(job *Job){
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if job.IsSingleton(){
+	switch job.overlap {
+	case OVERLAP_SKIP:
+		if p, r_ := job.js.getPendRun(); p > 0 || r_ > 0 {
+			return
+		}
+	case OVERLAP_RESTART:
+		if _, r_ := job.js.getPendRun(); r_ > 0 {
+			job.__cancelRun()
+		}
+	}
+
+	if job.IsSingleton() || job.overlap == OVERLAP_RESTART {
 		p, r_ := job.js.getPendRun()
 		if p == 0 {
 			job.js.addPendRun(1, 0)
@@ -93,6 +109,23 @@ func (r *runner) submit(job *Job){
 	r.notifyWakeup()
 }
 
+// submitN submits job to run n times one by one, even if it is a singleton job
+func (r *runner) submitN(job *Job, n int) {
+	if n <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.js.addPendRun(int32(n), 0)
+	if _, r_ := job.js.getPendRun(); r_ == 0 {
+		r._put(job)
+	}
+
+	r.notifyWakeup()
+}
+
 func (r *runner) notifyWakeup() {
   select {
     case r.sigs <- 1:
@@ -101,16 +134,43 @@ func (r *runner) notifyWakeup() {
   }
 }
 
-// func (r *runner) notifyQuit() {
-// 	r.sigs <- 0
-// }
+func (r *runner) notifyQuit() {
+	r.quit.Do(func() { r.sigs <- 0 })
+}
+
+// release quits the loop and releases the pool of runner, the running tasks will not be interrupted
+func (r *runner) release() {
+	r.notifyQuit()
+	r.rtp.Release()
+}
+
+// dropPending drops the pending runs of job, the dropped runs of a step are reported to its workflow as skipped
+func (r *runner) dropPending(job *Job) {
+	r.mu.Lock()
+	p, _ := job.js.getPendRun()
+	if p > 0 {
+		job.js.addPendRun(-p, 0)
+	}
+	r.mu.Unlock()
 
+	if job.after != nil {
+		for ; p > 0; p-- {
+			job.after(ErrJobSkipped)
+		}
+	}
+}
+
+// running returns the count of running jobs
 func (r *runner) running() int {
-	return r.rtp.Running()
+	return int(r.busy.Load())
 }
 
+// pending returns the count of jobs waiting for a free worker
 func (r *runner) pending() int {
-	return r.rtp.Waiting()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.tasksl.Size() + r.rtp.Waiting()
 }
 
 func (r *runner) max() int {
@@ -149,29 +209,39 @@ func (r *runner)loop() {
 			goto quit
 		}
 
-		if r.rtp.Free() == 0 {
-			continue
+		for r._runNext() {
 		}
+	}
 
-		if r.tasksl.Size() == 0 {
-			continue
-		}
+quit:
+}
 
-		r.mu.Lock()
-		job := r._removeFirst()
+// _runNext submits the first job in tasks to pool, it returns false if no more job can be submitted now
+func (r *runner)_runNext() bool {
+	// checks by busy, the workers of pool are released a little later than the jobs done
+	if max := r.rtp.Cap(); max > 0 && int(r.busy.Load()) >= max {
+		return false
+	}
 
-		p_, r_ := job.js.getPendRun()
-		if p_ == 0 || r_ == 1 {
-			r.mu.Unlock()
-			continue
-		}
+	r.mu.Lock()
+	job := r._removeFirst()
+	if job == nil {
+		r.mu.Unlock()
+		return false
+	}
 
-		job.js.addPendRun(-1, 1)
+	p_, r_ := job.js.getPendRun()
+	if p_ == 0 || r_ == 1 {
 		r.mu.Unlock()
+		return true
+	}
 
-		err := r.rtp.Submit(func (){
-			job.exec_once()
+	job.js.addPendRun(-1, 1)
+	r.mu.Unlock()
 
+	r.busy.Add(1)
+	err := r.rtp.Submit(func (){
+		defer func() {
 			r.mu.Lock()
 			p_, _ := job.js.addPendRun(0, -1)
 			if p_ > 0 {
@@ -179,21 +249,26 @@ func (r *runner)loop() {
 			}
 			r.mu.Unlock()
 
+			r.busy.Add(-1)
 			r.notifyWakeup()
-		})
+		}()
 
-		if err != nil {
-			job.js.errs.setError(err, time.Now())
+		job.exec_once()
+	})
 
-			r.mu.Lock()
-			p_, _ := job.js.addPendRun(0, -1)
-			if p_ > 0 {
-				r._put(job)
-			}
-			r.mu.Unlock()
+	if err != nil {
+		r.busy.Add(-1)
+		job.js.errs.setError(err, time.Now())
+
+		r.mu.Lock()
+		p_, _ := job.js.addPendRun(0, -1)
+		if p_ > 0 {
+			r._put(job)
 		}
+		r.mu.Unlock()
+
+		return false
 	}
 
-quit:
+	return true
 }
-
@@ -1,8 +1,11 @@
 package etimer
 
 import (
+	"hash/fnv"
 	"sync/atomic"
 	"time"
+
+	"github.com/ziyht/eden_go/erand"
 )
 
 type schedule interface {
@@ -12,14 +15,110 @@ type schedule interface {
 	commitNextStart(nextStart time.Time)
 
 	doCheckTicksAndTime(curTimerTicks int64, curTime time.Time)bool
+
+	next(t time.Time) time.Time   // returns the next start time after t
+}
+
+// nextRuns returns the next n start times of s after from
+func nextRuns(s schedule, from time.Time, n int) (out []time.Time) {
+	for next := s.next(from); len(out) < n && !next.IsZero(); next = s.next(next) {
+		out = append(out, next)
+	}
+	return
 }
 
 type scheduleBasic struct {
 	timer       *Timer
 	ticks       int64           // The job runs every tick.
 	nextTicks_  int64           // Next run ticks of the job.
+	jitter_     int64           // The jitter ticks added to nextTicks_.
 	
 	js          *JobState
+	opts        scheduleOpts
+}
+
+// scheduleOpts controls the rate of runs, see JobOpts.Jitter, Spread, StartAt and EndAt
+type scheduleOpts struct {
+	jitter      time.Duration
+	spread      float64         // the stable offset in [0, 1) of the interval hashed from the job name, < 0 if not set
+	startAt     time.Time
+	endAt       time.Time
+}
+
+func newScheduleOpts(name string, jitter time.Duration, spread bool, startAt, endAt time.Time) scheduleOpts {
+	o := scheduleOpts{jitter: jitter, spread: -1, startAt: startAt, endAt: endAt}
+	if spread {
+		h := fnv.New64a()
+		h.Write([]byte(name))
+		o.spread = float64(h.Sum64() >> 11) / (1 << 53)
+	}
+	return o
+}
+
+func (o *scheduleOpts) isSet() bool {
+	return o.jitter > 0 || o.spread >= 0 || !o.startAt.IsZero() || !o.endAt.IsZero()
+}
+
+// randJitter returns a random duration in [0, jitter)
+func (o *scheduleOpts) randJitter() time.Duration {
+	if o.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(erand.Int63n(int64(o.jitter)))
+}
+
+// window returns the next start in window, t is moved to StartAt if it is earlier, and zero returned if t is after EndAt
+func (o *scheduleOpts) window(next func(t time.Time) time.Time, t time.Time) time.Time {
+	if !o.startAt.IsZero() && t.Before(o.startAt) {
+		t = o.startAt.Add(-time.Nanosecond)
+	}
+	if t = next(t); !o.endAt.IsZero() && t.After(o.endAt) {
+		return time.Time{}
+	}
+	return t
+}
+
+// checkWindow checks if now is in [StartAt, EndAt], the job is stopped after EndAt like running out of Times
+func (o *scheduleOpts) checkWindow(js *JobState, now time.Time) bool {
+	if !o.startAt.IsZero() && now.Before(o.startAt) {
+		return false
+	}
+	if !o.endAt.IsZero() && now.After(o.endAt) {
+		js.setStatusCas(StatusWaiting, StatusStopped)
+		js.setStatusCas(StatusRunning, StatusStopped)
+		return false
+	}
+	return true
+}
+
+// toTicks returns the ticks of d, rounded up and at least 1
+func (s *scheduleBasic) toTicks(d time.Duration) int64 {
+	ticks := int64((d + s.timer.options.Interval - 1) / s.timer.options.Interval)
+	if ticks <= 0 {
+		ticks = 1
+	}
+	return ticks
+}
+
+// jitterTicks returns a random jitter in ticks, it is rounded up so that a Jitter less than the interval of timer works
+func (s *scheduleBasic) jitterTicks() int64 {
+	return int64((s.opts.randJitter() + s.timer.options.Interval - 1) / s.timer.options.Interval)
+}
+
+// init sets the first run of s by opts
+func (s *scheduleBasic) init(curTimerTicks int64, curTime time.Time) {
+	if !s.opts.isSet() {
+		return
+	}
+
+	next := s.next(curTime)
+	if next.IsZero() {
+		next = curTime.Add(s.timer.options.Interval * time.Duration(s.ticks))
+	}
+	s.jitter_ = s.jitterTicks()
+	next = next.Add(s.timer.options.Interval * time.Duration(s.jitter_))
+	s.commitNextTicks(curTimerTicks + s.toTicks(next.Sub(curTime)))
+	s.commitNextStart(next)
 }
 
 func (s *scheduleBasic) nextTicks() int64 {
@@ -35,12 +134,34 @@ func (s *scheduleBasic) CheckLimits() bool {
 	return leftRunTimes >= 0
 }
 
+func (s *scheduleBasic) next(t time.Time) time.Time {
+	return s.opts.window(s.__next, t)
+}
+
+// __next returns the next start after t, it is aligned to the stable offset of the interval in spread mode
+func (s *scheduleBasic) __next(t time.Time) time.Time {
+	interval := s.timer.options.Interval * time.Duration(s.ticks)
+	if s.opts.spread < 0 {
+		if !s.opts.startAt.IsZero() && t.Before(s.opts.startAt) {
+			return s.opts.startAt
+		}
+		return t.Add(interval)
+	}
+
+	offset := int64(s.opts.spread * float64(interval))
+	phase  := (t.UnixNano() - offset) % int64(interval)
+	if phase < 0 {
+		phase += int64(interval)
+	}
+	return t.Add(interval - time.Duration(phase))
+}
+
 func (s *scheduleBasic) commitNextTicks(nextTicks int64) {
 	atomic.StoreInt64(&s.nextTicks_, nextTicks)
 }
 
 func (s *scheduleBasic) commitNextStart(nextStart time.Time) {
-	s.js.nextStart = nextStart
+	s.js.setNextStart(nextStart)
 }
 
 // only returns value when reach == true
@@ -50,17 +171,47 @@ func (s *scheduleBasic) calNextTicksAndStart(curTimerTicks int64, curTime time.T
 		return
 	}
 
-	return true, curTimerTicks + s.ticks, curTime.Add(s.timer.options.Interval * time.Duration(s.ticks))
+	if !s.opts.isSet() {
+		return true, curTimerTicks + s.ticks, curTime.Add(s.timer.options.Interval * time.Duration(s.ticks))
+	}
+
+	// aligns to the offset in spread mode, the jitter of this run is excluded
+	if s.opts.spread >= 0 {
+		nextStart = s.__next(curTime.Add(-s.timer.options.Interval * time.Duration(s.jitter_)))
+		return true, curTimerTicks + s.toTicks(nextStart.Sub(curTime)), nextStart
+	}
+
+	// keeps the cadence without the jitter of this run
+	nextTicks = curTimerTicks - s.jitter_ + s.ticks
+	if nextTicks <= curTimerTicks {
+		nextTicks = curTimerTicks + 1
+	}
+
+	return true, nextTicks, curTime.Add(s.timer.options.Interval * time.Duration(nextTicks - curTimerTicks))
 }
 
 // doCheckTicksAndTime checks the if job can run in given timer ticks or time,
 // it returns true if the job need run else return false.
 func (s *scheduleBasic) doCheckTicksAndTime(curTimerTicks int64, curTime time.Time) bool {
+	if s.opts.isSet() && curTimerTicks >= s.nextTicks() {
+		// the ticks reached a little earlier than the time, check it again later
+		if nextStart := s.js.NextStart(); curTime.Before(nextStart) {
+			s.commitNextTicks(curTimerTicks + s.toTicks(nextStart.Sub(curTime)))
+			return false
+		}
+	}
+
 	reach, nt, ns := s.calNextTicksAndStart(curTimerTicks, curTime)
 
 	if reach {
+		if s.opts.jitter > 0 {
+			s.jitter_ = s.jitterTicks()
+			nt += s.jitter_
+			ns  = ns.Add(s.timer.options.Interval * time.Duration(s.jitter_))
+		}
 		s.commitNextTicks(nt)
 		s.commitNextStart(ns)
+		reach = s.opts.checkWindow(s.js, curTime)
 	}
 
 	return reach
@@ -9,35 +9,81 @@ import (
 type scheduleCron struct {
   scheduleBasic
 	specSched      cron.Schedule
+	nextStart_     time.Time       // next start of this pattern, js.nextStart is shared by all the patterns in a scheduleGroup
+	spread_        time.Duration   // the stable offset in spread mode, within the interval of the first two starts
 }
 
-// only returns value when reach == true
-func (s *scheduleCron) calNextTicksAndStart(curTimerTicks int64, curTime time.Time)(reach bool, nextTicks int64, nextStart time.Time){
-	// time check.
-	if curTime.Before(s.js.nextStart){
+// init sets the first run of s by opts, the job runs at the first check like before if no opts set
+func (s *scheduleCron) init(curTimerTicks int64, curTime time.Time) {
+	if !s.opts.isSet() {
 		return
 	}
 
-	nextStart = s.specSched.Next(curTime)
-	ticks := int64(nextStart.Sub(curTime) / s.timer.options.Interval)
-	if ticks <= 0 {
-		ticks = 1
-	} else if ticks > s.ticks {
+	if s.opts.spread >= 0 {
+		n1 := s.specSched.Next(curTime)
+		n2 := s.specSched.Next(n1)
+		s.spread_ = time.Duration(s.opts.spread * float64(n2.Sub(n1)))
+	}
+
+	s.nextStart_ = s.__nextStart(curTime)
+	s.commitNextTicks(curTimerTicks + s.__ticksTo(s.nextStart_, curTime))
+	s.commitNextStart(s.nextStart_)
+}
+
+func (s *scheduleCron) next(t time.Time) time.Time {
+	return s.opts.window(s.__next, t)
+}
+
+func (s *scheduleCron) __next(t time.Time) time.Time {
+	if s.spread_ == 0 {
+		return s.specSched.Next(t)
+	}
+	return s.specSched.Next(t.Add(-s.spread_)).Add(s.spread_)
+}
+
+// __nextStart returns the next start after t with a random jitter, it returns a time after EndAt to stop the job if no more runs
+func (s *scheduleCron) __nextStart(t time.Time) time.Time {
+	next := s.next(t)
+	if next.IsZero() && !s.opts.endAt.IsZero() {
+		next = s.opts.endAt.Add(time.Nanosecond)
+	}
+	return next.Add(s.opts.randJitter())
+}
+
+// __ticksTo returns the ticks from curTime to nextStart, at most the ticks of interval to check it again
+func (s *scheduleCron) __ticksTo(nextStart time.Time, curTime time.Time) int64 {
+	ticks := s.toTicks(nextStart.Sub(curTime))
+	if ticks > s.ticks {
 		ticks = s.ticks
 	}
+	return ticks
+}
 
-	return true, curTimerTicks + ticks, nextStart
+// only returns value when reach == true
+func (s *scheduleCron) calNextTicksAndStart(curTimerTicks int64, curTime time.Time)(reach bool, nextTicks int64, nextStart time.Time){
+	// time check.
+	if curTime.Before(s.nextStart_){
+		return
+	}
+
+	nextStart = s.__nextStart(curTime)
+	return true, curTimerTicks + s.__ticksTo(nextStart, curTime), nextStart
 }
 
 func (s *scheduleCron) doCheckTicksAndTime(curTimerTicks int64, curTime time.Time) bool {
 	reach, nt, ns := s.calNextTicksAndStart(curTimerTicks, curTime)
 
-	if reach {
-		s.commitNextTicks(nt)
-		s.commitNextStart(ns)
+	if !reach {
+		// the ticks reached earlier than the time, check it again later
+		s.commitNextTicks(curTimerTicks + s.__ticksTo(s.nextStart_, curTime))
+		return false
 	}
 
-	return reach
+	s.nextStart_ = ns
+	s.commitNextTicks(nt)
+	s.commitNextStart(ns)
+
+	return s.opts.checkWindow(s.js, curTime)
 }
 
 
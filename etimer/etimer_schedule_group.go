@@ -4,9 +4,6 @@ import (
 	"time"
 )
 
-
-var nilTime time.Time
-
 type scheduleGroup struct {
 	queue   *priorityQueue
 }
@@ -15,6 +12,19 @@ func (sg *scheduleGroup) nextTicks() int64{
 	return sg.queue.nextPriority
 }
 
+func (sg *scheduleGroup) next(t time.Time) (out time.Time) {
+	sg.queue.mu.Lock()
+	defer sg.queue.mu.Unlock()
+
+	for _, item := range sg.queue.heap.array {
+		n := item.value.(schedule).next(t)
+		if out.IsZero() || n.Before(out) {
+			out = n
+		}
+	}
+	return
+}
+
 func (sg *scheduleGroup) commitNextTicks(nextTicks int64) {
 }
 
@@ -29,7 +39,6 @@ func (sg *scheduleGroup) doCheckTicksAndTime(curTimerTicks int64, curTime time.T
 	var (
 		value     interface{}
 		reach     bool
-		nextStart time.Time
 		s         schedule
 	)
 
@@ -40,28 +49,40 @@ func (sg *scheduleGroup) doCheckTicksAndTime(curTimerTicks int64, curTime time.T
 		}
 
 		s = value.(schedule)
-		reach, nt, ns := s.calNextTicksAndStart(curTimerTicks, curTime)
-		if !reach {
+		if curTimerTicks < s.nextTicks() {
 			break
 		}
 
-		reach = true
-		if nextStart == nilTime {
-			nextStart = ns
-		} else if nextStart.After(ns) {
-			nextStart = ns
-		}
-
 		sg.queue.Pop()
-		s.commitNextTicks(nt)
+		if s.doCheckTicksAndTime(curTimerTicks, curTime) {
+			reach = true
+		}
 		sg.queue.Push(s, s.nextTicks())
 	}
 
 	if reach {
-		s.commitNextStart(nextStart)
+		sg.__commitNextStart()
 	}
 
 	return reach
 }
 
+// __commitNextStart commits the earliest next start of all the patterns to job state
+func (sg *scheduleGroup) __commitNextStart() {
+	sg.queue.mu.Lock()
+	defer sg.queue.mu.Unlock()
 
+	var first *scheduleCron
+	for _, item := range sg.queue.heap.array {
+		s, ok := item.value.(*scheduleCron)
+		if !ok || s.nextStart_.IsZero() {
+			continue
+		}
+		if first == nil || s.nextStart_.Before(first.nextStart_) {
+			first = s
+		}
+	}
+	if first != nil {
+		first.commitNextStart(first.nextStart_)
+	}
+}
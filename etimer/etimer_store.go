@@ -0,0 +1,165 @@
+package etimer
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MisfirePolicy decides what to do with the runs missed while the timer is not running
+type MisfirePolicy int
+
+const (
+	MISFIRE_RUN_ONCE MisfirePolicy = iota   // run the job once for all the missed runs
+	MISFIRE_RUN_ALL                         // run the job for each missed run one by one, at most maxMisfireRuns times
+	MISFIRE_SKIP                            // skip the missed runs, wait for the next schedule
+)
+
+const maxMisfireRuns = 1000
+
+// JobStore persists the definitions and progresses of jobs, so a Timer can restore them after restarting,
+// see TimerOptions.Store and ECacheStore
+type JobStore interface {
+	Save(rec *JobRecord) error
+	Load(name string) (*JobRecord, error)   // returns nil, nil if not found
+	LoadAll() ([]*JobRecord, error)
+	Delete(name string) error
+}
+
+// JobRecord is the persisted definition and progress of a job
+type JobRecord struct {
+	Name        string        `json:"name"`
+	Group       string        `json:"group,omitempty"`
+	Interval    time.Duration `json:"interval,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+	Location    string        `json:"location,omitempty"`
+	IsSingleton bool          `json:"singleton,omitempty"`
+	Times       int64         `json:"times,omitempty"`
+	Func        string        `json:"func,omitempty"`
+	Args        map[string]any `json:"args,omitempty"`
+	Jitter      time.Duration `json:"jitter,omitempty"`
+	Spread      bool          `json:"spread,omitempty"`
+	StartAt     time.Time     `json:"start_at"`
+	EndAt       time.Time     `json:"end_at"`
+
+	Status      int32         `json:"status"`
+	LeftTimes   int64         `json:"left_times,omitempty"`
+	Runnings    uint64        `json:"runnings,omitempty"`
+	Failures    uint64        `json:"failures,omitempty"`
+	Successs    uint64        `json:"successs,omitempty"`
+	Retries     uint64        `json:"retries,omitempty"`
+	Timeouts    uint64        `json:"timeouts,omitempty"`
+	Skips       uint64        `json:"skips,omitempty"`
+	NextStart   time.Time     `json:"next_start"`
+	LastStart   time.Time     `json:"last_start"`
+	LastEnd     time.Time     `json:"last_end"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastFailure time.Time     `json:"last_failure"`
+}
+
+// record returns the current definition and progress of job, the time fields are snapshotted under lock
+// since the job may be running
+func (j *Job) record() *JobRecord {
+	js := &j.js
+	ts := js.SnapshotTimes()
+	rec := &JobRecord{
+		Name       : j.name,
+		Interval   : js.interval,
+		Pattern    : js.pattern,
+		IsSingleton: js.IsSingleton(),
+		Times      : js.Times(),
+		Func       : j.fn,
+		Args       : j.args,
+		Jitter     : j.sopts.jitter,
+		Spread     : j.sopts.spread >= 0,
+		StartAt    : j.sopts.startAt,
+		EndAt      : j.sopts.endAt,
+		Status     : atomic.LoadInt32(&js.status),
+		LeftTimes  : js.LeftTimes(),
+		Runnings   : js.Runnings(),
+		Failures   : js.Failures(),
+		Successs   : js.Successs(),
+		Retries    : js.Retries(),
+		Timeouts   : js.Timeouts(),
+		Skips      : js.Skips(),
+		NextStart  : ts.NextStart,
+		LastStart  : ts.LastStart,
+		LastEnd    : ts.LastEnd,
+		LastSuccess: ts.LastSuccess,
+		LastFailure: ts.LastFailure,
+	}
+	if j.loc != nil {
+		rec.Location = j.loc.String()
+	}
+	rec.Group = js.Group()
+	if rec.NextStart.IsZero() && j.sched != nil {
+		rec.NextStart = j.sched.next(time.Now())
+	}
+
+	return rec
+}
+
+// restore restores the progress of job from rec
+func (js *JobState) restore(rec *JobRecord) {
+	atomic.StoreUint64(&js.runnings, rec.Runnings)
+	atomic.StoreUint64(&js.failures, rec.Failures)
+	atomic.StoreUint64(&js.successs, rec.Successs)
+	atomic.StoreUint64(&js.retries, rec.Retries)
+	atomic.StoreUint64(&js.timeouts, rec.Timeouts)
+	atomic.StoreUint64(&js.skips, rec.Skips)
+	if js.times > 0 && rec.Times == js.times {
+		atomic.StoreInt64(&js.leftTimes, rec.LeftTimes)
+		if rec.LeftTimes <= 0 {
+			js.setStatus(StatusStopped)
+		}
+	}
+	js.tmu.Lock()
+	js.lastStart   = rec.LastStart
+	js.lastEnd     = rec.LastEnd
+	js.lastSuccess = rec.LastSuccess
+	js.lastFailure = rec.LastFailure
+	js.tmu.Unlock()
+}
+
+// __restore restores the progress of j from the store of timer, and runs the missed runs by the misfire policy,
+// t.mu and j.js.mu must be locked
+func (t *Timer) __restore(j *Job) error {
+	rec, err := t.options.Store.Load(j.name)
+	if err != nil || rec == nil {
+		return err
+	}
+
+	j.js.restore(rec)
+
+	if t.options.Misfire == MISFIRE_SKIP || rec.NextStart.IsZero() || j.sched == nil || j.js.Status() != StatusWaiting {
+		return nil
+	}
+
+	now := time.Now()
+	missed := 0
+	for next := rec.NextStart; !next.After(now) && missed < maxMisfireRuns; next = j.sched.next(next) {
+		missed += 1
+		if t.options.Misfire == MISFIRE_RUN_ONCE {
+			break
+		}
+	}
+	if left := j.js.LeftTimes(); j.js.times > 0 && int64(missed) > left {
+		missed = int(left)
+	}
+	if missed > 0 {
+		j.js.runner.submitN(j, missed)
+	}
+
+	return nil
+}
+
+// __persist saves the definition and progress of j to the store of timer if set
+func (j *Job) __persist() {
+	t := j.timer
+	if t == nil || t.options.Store == nil {
+		return
+	}
+
+	if err := t.options.Store.Save(j.record()); err != nil {
+		j.js.recordError(err)
+	}
+}
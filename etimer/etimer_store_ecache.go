@@ -0,0 +1,60 @@
+package etimer
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ziyht/eden_go/ecache"
+)
+
+// ECacheStore is a JobStore which stores the jobs as json in an ecache Region, one key for each job
+type ECacheStore struct {
+	r *ecache.Region
+}
+
+func NewECacheStore(r *ecache.Region) *ECacheStore {
+	return &ECacheStore{r: r}
+}
+
+func (s *ECacheStore) Save(rec *JobRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.r.Set(rec.Name, data)
+}
+
+func (s *ECacheStore) Load(name string) (*JobRecord, error) {
+	v, err := s.r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = v.Error(); err != nil {
+		if errors.Is(err, ecache.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rec := &JobRecord{}
+	if err = json.Unmarshal(v.Bytes(), rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (s *ECacheStore) LoadAll() (recs []*JobRecord, err error) {
+	err = s.r.DoForAll(func(idx int, key []byte, val ecache.Val) error {
+		rec := &JobRecord{}
+		if err := json.Unmarshal(val.Bytes(), rec); err != nil {
+			return err
+		}
+		recs = append(recs, rec)
+		return nil
+	})
+	return
+}
+
+func (s *ECacheStore) Delete(name string) error {
+	return s.r.Del(name)
+}
@@ -3,7 +3,9 @@ package etimer
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/satori/go.uuid"
@@ -15,37 +17,68 @@ type internalPanic string
 
 // TimerOptions is the configuration object for Timer.
 type TimerOptions struct {
-	Interval time.Duration // Interval is the interval escaped of the timer.
+	Interval  time.Duration  // Interval is the interval escaped of the timer.
+	Store     JobStore       // Store persists the jobs and their states if set, the jobs with a registered Func are restored
+	                         // when the timer created, and the others will be restored by name when added
+	Misfire   MisfirePolicy  // Misfire decides what to do with the runs missed while the timer is not running, only for Store
+	Scheduler SchedulerType  // Scheduler decides the queue to schedule the jobs, SCHEDULER_HEAP by default
+	Groups    map[string]int // Groups are created with the max runnings before restoring the jobs in Store, see SetGroup,
+	                         // the grouped jobs can not be restored if their groups not exist, see Timer.RestoreErr
 }
 
 // Timer is the timer manager, which uses ticks to calculate the timing interval.
 type Timer struct {
 	mu      sync.RWMutex
 	jobs    map[string]*Job
-	queue   *priorityQueue // queue is a priority queue based on heap structure.
+	queue   jobQueue       // queue is a priority queue based on heap structure, or a timing wheel.
 	status  int32          // status is the current timer status.
 	ticks   int64          // ticks is the proceeded interval number by the timer.
 	options TimerOptions   // timer options is used for timer configuration.
 	groups  map[string]*runner
+	ctx     context.Context    // the parent of the ctx of jobs, cancelled when the timer shutdown
+	cancel  context.CancelFunc
+	clock   wheelClock         // counts the ticks by time, only for SCHEDULER_WHEEL
+	wake    chan struct{}      // wakes up the sleeping loop, only for SCHEDULER_WHEEL
+	restoreErr error           // the error of restoring the jobs in Store when created
 }
 
 func newTimer(options ...TimerOptions) *Timer {
 	t := &Timer{
 	  jobs  : map[string]*Job{},
-		queue :  newPriorityQueue(),
 		status: StatusRunning,
 		ticks :  0,
 		groups:  map[string]*runner{},
+		wake  :  make(chan struct{}, 1),
 	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
 	if len(options) > 0 {
 		t.options = options[0]
 	} else {
 		t.options = DefaultOptions()
 	}
-	go t.loop()
+	for name, max := range t.options.Groups {
+		t.groups[name] = newRunner(name, max)
+	}
+	t.queue = newJobQueue(t.options.Scheduler)
+	if t.options.Scheduler == SCHEDULER_WHEEL {
+		t.clock = wheelClock{interval: t.options.Interval, last: time.Now()}
+		t.loopWheel()
+	} else {
+		go t.loop()
+	}
+	if t.options.Store != nil {
+		// the jobs failed to restore are kept in store, they can be restored by RestoreJobs later
+		t.restoreErr = t.__restoreJobs(true)
+	}
 	return t
 }
 
+// RestoreErr returns the error of restoring the jobs in Store when the timer created, like the groups of jobs not exist,
+// set TimerOptions.Groups to create the groups before restoring
+func (t *Timer) RestoreErr() error {
+	return t.restoreErr
+}
+
 // SetGroup - set a running group in timer
 // 1. a group is a handle to limit the max runings jobs in the same time, 
 //    each job can be set to a seperate group
@@ -65,11 +98,26 @@ func (t *Timer) SetGroup(name string, max int) {
 }
 
 func (t *Timer) AddJob(j *Job, group ...string) error {
+	if len(j.deps) > 0 {
+		return fmt.Errorf("job '%s' has DependsOn, it should be added as a step of Workflow", j.name)
+	}
+
+	// only triggered by JobOpts.Trigger
+	scheduled := j.trigger == nil || j.js.pattern != "" || j.js.interval > 0
+	return t.__addJob(j, scheduled, group...)
+}
+
+// __addJob adds j to timer, j will be scheduled by the timer if scheduled is true, or it only can be run manually
+func (t *Timer) __addJob(j *Job, scheduled bool, group ...string) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	j.js.mu.Lock()
 	defer j.js.mu.Unlock()
 
+	if atomic.LoadInt32(&t.status) == StatusClosed {
+		return fmt.Errorf("timer is shutdown")
+	}
+
 	if j.timer != nil {
 		return fmt.Errorf("job '%s' already exists in another timer", j.name)
 	}
@@ -79,6 +127,12 @@ func (t *Timer) AddJob(j *Job, group ...string) error {
 		return fmt.Errorf("job '%s' already exists", j.name)
 	}
 
+	if j.cb == nil {
+		if j.cb = getFunc(j.fn); j.cb == nil {
+			return fmt.Errorf("job '%s' has no CB, and the Func '%s' is not registered", j.name, j.fn)
+		}
+	}
+
 	if len(group) > 0 {
 		g := t.groups[group[0]]
 		if g == nil {
@@ -89,14 +143,29 @@ func (t *Timer) AddJob(j *Job, group ...string) error {
 		j.js.runner = dfRunner
 	}
 
-	err := t.parsingScheduleForJob(j)
-	if err != nil {
-		return fmt.Errorf("parsing schedule failed: %s", err)
+	if scheduled {
+		if err := t.parsingScheduleForJob(j); err != nil {
+			return fmt.Errorf("parsing schedule failed: %s", err)
+		}
 	}
 
 	j.timer = t
+	j.__initCtx(t.ctx)
+	j.__watchTrigger()
+	if t.options.Store != nil {
+		if err := t.__restore(j); err != nil {
+			j.timer = nil
+			j.jcancel()
+			return fmt.Errorf("restore job '%s' failed: %s", j.name, err)
+		}
+		j.__persist()
+	}
+
 	t.jobs[j.name] = j
-	t.queue.Push(j, j.sched.nextTicks())
+	if scheduled {
+		t.queue.Push(j, j.sched.nextTicks())
+		t.__wakeup()
+	}
 
 	return nil
 }
@@ -129,10 +198,177 @@ func (t *Timer) AddCron(ctx context.Context, pattern string, cb JobFunc, singlet
 	return j, err
 }
 
-func  (t *Timer) AllJobStates()[]*JobState{
+// Status returns the status of timer, StatusRunning, StatusStopped(paused) or StatusClosed(shutdown)
+func (t *Timer) Status() int {
+	return int(atomic.LoadInt32(&t.status))
+}
+
+// Pause pauses the scheduling of timer, the running jobs are not affected
+func (t *Timer) Pause() {
+	atomic.CompareAndSwapInt32(&t.status, StatusRunning, StatusStopped)
+	t.__wakeup()
+}
+
+// Resume resumes the scheduling of a paused timer
+func (t *Timer) Resume() {
+	atomic.CompareAndSwapInt32(&t.status, StatusStopped, StatusRunning)
+	t.__wakeup()
+}
+
+// Shutdown stops the scheduling of timer, drops the pending runs, cancels the ctx of jobs and waits for the running jobs
+// to return, then releases the groups of timer.
+// It returns ctx.Err() if ctx is done before all the running jobs returned, the timer can not be used after shutdown
+func (t *Timer) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&t.status, StatusClosed)
+	t.cancel()
+	t.__wakeup()
+
+	t.mu.RLock()
+	jobs := make([]*Job, 0, len(t.jobs))
+	for _, j := range t.jobs {
+		jobs = append(jobs, j)
+	}
+	groups := make([]*runner, 0, len(t.groups))
+	for _, g := range t.groups {
+		groups = append(groups, g)
+	}
+	t.mu.RUnlock()
+
+	defer func() {
+		for _, g := range groups {
+			g.release()
+		}
+	}()
+
+	for _, j := range jobs {
+		j.js.runner.dropPending(j)
+		j.__finishRetry()
+	}
+
+	ticker := time.NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+	for _running(jobs) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
 	return nil
 }
 
+// return true if any of jobs is running
+func _running(jobs []*Job) bool {
+	for _, j := range jobs {
+		if _, r := j.js.getPendRun(); r > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// JobFilter returns true if the job should be listed, see Timer.Jobs
+type JobFilter func(j *Job) bool
+
+// ByGroup returns a JobFilter which matches the jobs in group, "" for the jobs not in any group
+func ByGroup(group string) JobFilter {
+	return func(j *Job) bool { return j.js.Group() == group }
+}
+
+// ByStatus returns a JobFilter which matches the jobs in status
+func ByStatus(status int) JobFilter {
+	return func(j *Job) bool { return j.js.Status() == status }
+}
+
+// GroupStats is the snapshot of a group
+type GroupStats struct {
+	Name    string
+	Running int     // the count of running jobs
+	Pending int     // the count of jobs waiting for a free slot
+	Max     int     // the max running jobs, -1 for unlimited
+}
+
+// AllJobStates returns the states of all the jobs in timer, sorted by name
+func (t *Timer) AllJobStates() []*JobState {
+	jobs := t.Jobs(nil)
+	out  := make([]*JobState, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, &j.js)
+	}
+	return out
+}
+
+// Job returns the job by name, nil if not exist
+func (t *Timer) Job(name string) *Job {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.jobs[name]
+}
+
+// Jobs returns the jobs matched by filter, sorted by name, all the jobs will be returned if filter is nil
+func (t *Timer) Jobs(filter JobFilter) []*Job {
+	t.mu.RLock()
+	out := make([]*Job, 0, len(t.jobs))
+	for _, j := range t.jobs {
+		if filter == nil || filter(j) {
+			out = append(out, j)
+		}
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(out, func(i, k int) bool { return out[i].name < out[k].name })
+	return out
+}
+
+// RemoveJob closes and removes the job by name from timer, and deletes it from TimerOptions.Store if set,
+// it returns false if the job not exist
+func (t *Timer) RemoveJob(name string) bool {
+	t.mu.Lock()
+	j := t.jobs[name]
+	if j == nil {
+		t.mu.Unlock()
+		return false
+	}
+	delete(t.jobs, name)
+	t.mu.Unlock()
+
+	j.Close()
+	t.queue.Remove(j)
+	if t.options.Store != nil {
+		if err := t.options.Store.Delete(name); err != nil {
+			j.js.recordError(err)
+		}
+	}
+	return true
+}
+
+// Groups returns the names of all the groups in timer, sorted by name
+func (t *Timer) Groups() []string {
+	t.mu.RLock()
+	out := make([]string, 0, len(t.groups))
+	for name := range t.groups {
+		out = append(out, name)
+	}
+	t.mu.RUnlock()
+
+	sort.Strings(out)
+	return out
+}
+
+// GroupStats returns the stats of group by name, false if the group not exist
+func (t *Timer) GroupStats(name string) (GroupStats, bool) {
+	t.mu.RLock()
+	g := t.groups[name]
+	t.mu.RUnlock()
+
+	if g == nil {
+		return GroupStats{}, false
+	}
+	return GroupStats{Name: name, Running: g.running(), Pending: g.pending(), Max: g.max()}, true
+}
+
 // return true in default
 func _getSingleton(singleton ...bool) bool {
 	if len(singleton) > 0 {
@@ -2,6 +2,7 @@ package etimer
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -30,21 +31,24 @@ func (t *Timer) parsingScheduleForJob(j *Job) error {
 		// then sets it to one tick, which means it will be run in one interval.
 		intervalTicksOfJob = 1
 	}
-	nextTicks := atomic.LoadInt64(&t.ticks) + intervalTicksOfJob
+	curTicks, now := t.__ticks(), time.Now()
+	nextTicks := curTicks + intervalTicksOfJob
 
 	if j.js.pattern == "" {
-		j.sched = &scheduleBasic{
+		s := &scheduleBasic{
 			timer     : t,
 			ticks     : intervalTicksOfJob,
 			nextTicks_: nextTicks,
 			js        : &j.js,
+			opts      : j.sopts,
 		}
+		s.init(curTicks, now)
+		j.sched = s
 		return nil
 	}
 
 	var validPs []string 
-	ps := strings.Split(j.js.pattern, ",")
-	for _, p := range ps {
+	for _, p := range splitPatterns(j.js.pattern) {
 		p = strings.TrimSpace(p)
 		if p == "" {
 			continue
@@ -60,20 +64,23 @@ func (t *Timer) parsingScheduleForJob(j *Job) error {
 	var sg *scheduleGroup
 
 	for _, vp := range validPs {
-		s, err := cron.ParseStandard(vp)
+		s, err := parseCron(vp, j.loc)
 		if err != nil {
 			return err
 		}
 
-		sched = &scheduleCron{
+		sc := &scheduleCron{
 			scheduleBasic: scheduleBasic{
 				timer     : t,
 				ticks     : intervalTicksOfJob,
 				nextTicks_: nextTicks,
 				js        : &j.js,
+				opts      : j.sopts,
 			},
 			specSched: s,
 		}
+		sc.init(curTicks, now)
+		sched = sc
 
 		if len(validPs) == 1 {
 			break
@@ -86,12 +93,43 @@ func (t *Timer) parsingScheduleForJob(j *Job) error {
 		sg.queue.Push(sched, sched.nextTicks())
 		sched = sg
 	}
+	if sg != nil {
+		sg.__commitNextStart()
+	}
 
 	j.sched = sched 
 
 	return nil
 }
 
+// cronParser supports the standard 5 fields, an optional seconds field at the beginning, the descriptors like @every 1m, @daily,
+// and the CRON_TZ= or TZ= prefix to set the location of pattern
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// splitPatterns splits the patterns by ';', or by ',' for the old format if the whole pattern can not be parsed
+func splitPatterns(pattern string) []string {
+	if strings.Contains(pattern, ";") {
+		return strings.Split(pattern, ";")
+	}
+	if _, err := cronParser.Parse(strings.TrimSpace(pattern)); err == nil {
+		return []string{pattern}
+	}
+	return strings.Split(pattern, ",")
+}
+
+// parseCron parses the pattern in loc, the CRON_TZ= or TZ= prefix in pattern has high priority than loc
+func parseCron(pattern string, loc *time.Location) (cron.Schedule, error) {
+	s, err := cronParser.Parse(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if ss, ok := s.(*cron.SpecSchedule); ok && loc != nil && !strings.HasPrefix(pattern, "CRON_TZ=") && !strings.HasPrefix(pattern, "TZ=") {
+		ss.Location = loc
+	}
+	return s, nil
+}
+
 // loop starts the ticker using a standalone goroutine.
 func (t *Timer) loop() {
 	go func() {
@@ -123,29 +161,83 @@ func (t *Timer) loop() {
 	}()
 }
 
+// loopWheel is the loop for SCHEDULER_WHEEL, it sleeps until the next priority of queue or waked up, and catches up the
+// ticks escaped by time, so it costs nothing when idle and the Interval can be less than 1ms
+func (t *Timer) loopWheel() {
+	go func() {
+		sleep := time.NewTimer(time.Hour)
+		defer sleep.Stop()
+		for {
+			wait := time.Duration(-1)
+			switch atomic.LoadInt32(&t.status) {
+			case StatusRunning:
+				now := time.Now()
+				t.clock.unfreeze(now)
+				if currentTimerTicks := t.clock.advance(&t.ticks, now); currentTimerTicks >= t.queue.NextPriority() {
+					t.proceed(currentTimerTicks, now)
+				}
+				if next := t.queue.NextPriority(); next != math.MaxInt64 {
+					wait = max(t.clock.until(&t.ticks, next, time.Now()), 0)
+				}
+
+			case StatusStopped:
+				// wait for Resume
+				t.clock.freeze()
+
+			case StatusClosed:
+				return
+			}
+
+			if wait < 0 {
+				<-t.wake
+				continue
+			}
+			sleep.Reset(wait)
+			select {
+			case <-sleep.C:
+			case <-t.wake:
+				sleep.Stop()
+			}
+		}
+	}()
+}
+
+// __wakeup wakes up the loop of SCHEDULER_WHEEL
+func (t *Timer) __wakeup() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// __ticks returns the current ticks of timer
+func (t *Timer) __ticks() int64 {
+	if t.options.Scheduler == SCHEDULER_WHEEL {
+		return t.clock.advance(&t.ticks, time.Now())
+	}
+	return atomic.LoadInt64(&t.ticks)
+}
+
 // proceed function proceeds the timer job checking and running logic.
 func (t *Timer) proceed(curTimerTicks int64, curTime time.Time) {
-	var (
-		value interface{}
-	)
 	for {
-		value = t.queue.Fetch()
+		// It pops the job which meets the ticks' requirement.
+		value := t.queue.PopReached(curTimerTicks)
 		if value == nil {
 			break
 		}
-		j := value.(*Job)
-		// It checks if it meets the ticks' requirement.
-		if curTimerTicks < j.sched.nextTicks() {
-			break
+		if task, ok := value.(*AfterTask); ok {
+			task.__run(curTimerTicks)
+			continue
 		}
-		t.queue.Pop()
+		j := value.(*Job)
 		// It checks the job running requirements and then does asynchronous running.
 		if j.sched.doCheckTicksAndTime(curTimerTicks, curTime){
 
 			// Perform job checking.
 			switch j.js.Status() {
-				case StatusStopped: return 
-				case StatusClosed : return 
+				case StatusStopped: // paused, keep it in queue to wait for next schedule
+				case StatusClosed : // removed from queue below
 				default:
 					fmt.Printf("submit '%s'\n", j.name)
 					t.submitJob(j)
@@ -161,5 +253,5 @@ func (t *Timer) proceed(curTimerTicks int64, curTime time.Time) {
 }
 
 func (t *Timer) submitJob(j *Job) {
-	dfRunner.submit(j)
+	j.js.runner.submit(j)
 }
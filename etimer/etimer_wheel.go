@@ -0,0 +1,310 @@
+package etimer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SchedulerType decides the queue used by timer to schedule the jobs
+type SchedulerType int
+
+const (
+	SCHEDULER_HEAP  SchedulerType = iota   // a priority queue based on heap, the timer ticks every Interval
+	SCHEDULER_WHEEL                        // a hierarchical timing wheel with O(1) insert and cancel, the timer sleeps when no job,
+	                                       // and catches up the ticks by time, so Interval can be less than 1ms
+)
+
+// jobQueue is the queue of timer, the values are popped when the ticks of timer reach their priorities
+type jobQueue interface {
+	Push(value interface{}, priority int64)
+	PopReached(ticks int64) interface{}     // pops a value whose priority <= ticks, returns nil if no one
+	Remove(value interface{}) bool
+	NextPriority() int64
+	Len() int
+}
+
+func newJobQueue(typ SchedulerType) jobQueue {
+	if typ == SCHEDULER_WHEEL {
+		return newTimingWheel()
+	}
+	return newPriorityQueue()
+}
+
+const (
+	wheelBits   = 8
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 4                        // covers 2^32 ticks, the values beyond it will be re-added when cascaded
+)
+
+type wheelNode struct {
+	value      interface{}
+	expire     int64
+	prev, next *wheelNode
+	list       *wheelList
+}
+
+// wheelList is a doubly linked list with a sentinel root
+type wheelList struct {
+	root wheelNode
+	len  int
+}
+
+func (l *wheelList) lazyInit() {
+	if l.root.next == nil {
+		l.root.next = &l.root
+		l.root.prev = &l.root
+	}
+}
+
+func (l *wheelList) pushBack(n *wheelNode) {
+	l.lazyInit()
+	n.prev, n.next = l.root.prev, &l.root
+	l.root.prev.next = n
+	l.root.prev = n
+	n.list = l
+	l.len += 1
+}
+
+func (l *wheelList) remove(n *wheelNode) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev, n.next, n.list = nil, nil, nil
+	l.len -= 1
+}
+
+func (l *wheelList) front() *wheelNode {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// timingWheel is a hierarchical timing wheel, level i has 256 slots and each slot of it covers 256^i ticks
+type timingWheel struct {
+	mu     sync.Mutex
+	cur    int64                                  // the ticks advanced to
+	slots  [wheelLevels][wheelSlots]wheelList
+	ready  wheelList                              // the nodes reached
+	nodes  map[interface{}]*wheelNode
+	count  int                                    // the count of nodes in slots
+}
+
+func newTimingWheel() *timingWheel {
+	return &timingWheel{nodes: map[interface{}]*wheelNode{}}
+}
+
+func (w *timingWheel) Push(value interface{}, priority int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n := w.nodes[value]; n != nil {
+		w.__remove(n)
+	}
+
+	n := &wheelNode{value: value, expire: priority}
+	w.nodes[value] = n
+	w.__add(n)
+}
+
+func (w *timingWheel) PopReached(ticks int64) interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.__advance(ticks)
+
+	n := w.ready.front()
+	if n == nil {
+		return nil
+	}
+	w.ready.remove(n)
+	delete(w.nodes, n.value)
+	return n.value
+}
+
+func (w *timingWheel) Remove(value interface{}) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := w.nodes[value]
+	if n == nil {
+		return false
+	}
+	w.__remove(n)
+	delete(w.nodes, value)
+	return true
+}
+
+// NextPriority returns the ticks to advance to, it is the exact priority if the next value is in level 0,
+// or the ticks to cascade the slot of upper levels which contains it
+func (w *timingWheel) NextPriority() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ready.len > 0 {
+		return w.cur
+	}
+	return w.__next()
+}
+
+// __next returns the ticks of the first non-empty slot after cur, see NextPriority
+func (w *timingWheel) __next() int64 {
+	var next int64 = math.MaxInt64
+	if w.count == 0 {
+		return next
+	}
+
+	for i := int64(1); i < wheelSlots; i++ {
+		if w.slots[0][(w.cur + i) & wheelMask].len > 0 {
+			next = w.cur + i
+			break
+		}
+	}
+	for level := 1; level < wheelLevels; level++ {
+		shift := wheelBits * level
+		for i := int64(1); i <= wheelSlots; i++ {
+			if w.slots[level][((w.cur >> shift) + i) & wheelMask].len > 0 {
+				if t := ((w.cur >> shift) + i) << shift; t < next {
+					next = t
+				}
+				break
+			}
+		}
+	}
+	return next
+}
+
+func (w *timingWheel) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.nodes)
+}
+
+func (w *timingWheel) __remove(n *wheelNode) {
+	if n.list != &w.ready {
+		w.count -= 1
+	}
+	n.list.remove(n)
+}
+
+// __add adds n to the slot by its remaining ticks, or to ready if reached
+func (w *timingWheel) __add(n *wheelNode) {
+	d := n.expire - w.cur
+	if d <= 0 {
+		w.ready.pushBack(n)
+		return
+	}
+
+	w.count += 1
+	for level := 0; level < wheelLevels; level++ {
+		if d < 1 << (wheelBits * (level + 1)) {
+			w.slots[level][(n.expire >> (wheelBits * level)) & wheelMask].pushBack(n)
+			return
+		}
+	}
+
+	// beyond the range, put it to the last slot of top level to be re-added when cascaded
+	level := wheelLevels - 1
+	w.slots[level][((w.cur >> (wheelBits * level)) - 1) & wheelMask].pushBack(n)
+}
+
+// __advance advances the wheel to ticks, the nodes reached will be moved to ready
+func (w *timingWheel) __advance(ticks int64) {
+	for w.cur < ticks {
+		if w.count == 0 {
+			w.cur = ticks
+			return
+		}
+
+		// skip the empty ticks
+		if w.slots[0][(w.cur + 1) & wheelMask].len == 0 {
+			next := w.__next()
+			if next > ticks {
+				w.cur = ticks
+				return
+			}
+			w.cur = next - 1
+		}
+
+		w.cur += 1
+		idx := w.cur & wheelMask
+		if idx == 0 {
+			w.__cascade(1)
+		}
+
+		l := &w.slots[0][idx]
+		for n := l.front(); n != nil; n = l.front() {
+			l.remove(n)
+			w.count -= 1
+			w.ready.pushBack(n)
+		}
+	}
+}
+
+// __cascade re-adds the nodes in the current slot of level, and cascades the upper level if the slot is the first one
+func (w *timingWheel) __cascade(level int) {
+	if level >= wheelLevels {
+		return
+	}
+
+	idx := (w.cur >> (wheelBits * level)) & wheelMask
+	if idx == 0 {
+		w.__cascade(level + 1)
+	}
+
+	l := &w.slots[level][idx]
+	for n := l.front(); n != nil; n = l.front() {
+		l.remove(n)
+		w.count -= 1
+		w.__add(n)
+	}
+}
+
+// wheelClock counts the ticks of timer by time for SCHEDULER_WHEEL, the ticks are frozen while the timer paused
+type wheelClock struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time      // the time of the last tick
+	frozen   bool
+}
+
+// advance adds the ticks escaped since the last tick to *ticks, and returns it
+func (c *wheelClock) advance(ticks *int64, now time.Time) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.frozen {
+		return atomic.LoadInt64(ticks)
+	}
+	if n := int64(now.Sub(c.last) / c.interval); n > 0 {
+		c.last = c.last.Add(c.interval * time.Duration(n))
+		return atomic.AddInt64(ticks, n)
+	}
+	return atomic.LoadInt64(ticks)
+}
+
+// until returns the duration from now to the time of next ticks
+func (c *wheelClock) until(ticks *int64, next int64, now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.last.Add(c.interval * time.Duration(next - atomic.LoadInt64(ticks))).Sub(now)
+}
+
+func (c *wheelClock) freeze() {
+	c.mu.Lock()
+	c.frozen = true
+	c.mu.Unlock()
+}
+
+func (c *wheelClock) unfreeze(now time.Time) {
+	c.mu.Lock()
+	if c.frozen {
+		c.frozen = false
+		c.last   = now
+	}
+	c.mu.Unlock()
+}
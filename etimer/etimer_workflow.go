@@ -0,0 +1,291 @@
+package etimer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrJobSkipped is the error of a run which is skipped, like the steps whose upstream failed in a Workflow
+var ErrJobSkipped = errors.New("job skipped")
+
+// WorkflowOpts is the options of Workflow
+type WorkflowOpts struct {
+	Name     string             // the name of workflow, it is also the name of the job to trigger the workflow
+	Ctx      context.Context    // the ctx of the workflow job, the running steps will be cancelled when it is done
+	Interval time.Duration      // interval for workflow to run
+	Pattern  string             // cron pattern to run workflow, has high priority than Interval, see JobOpts for the format
+	Location *time.Location     // the location of Pattern
+}
+
+// Workflow is a DAG of jobs(steps), the steps are run by the DependsOn edges in each run of workflow:
+//   1. a step runs after all the steps it depends on succeeded, the independent steps run concurrently within their group limits
+//   2. a step is skipped if any of the steps it depends on failed or skipped, and the skip is counted in its JobState
+//   3. the steps are added to timer as jobs, but they are only run by the workflow
+//   4. the workflow itself is a singleton job named WorkflowOpts.Name, its JobState reports the result of each run
+type Workflow struct {
+	name   string
+	job    *Job
+
+	mu     sync.Mutex
+	steps  []*Job
+	groups map[string]string
+	index  map[string]*Job
+	order  []*Job             // topological order of steps, set in AddWorkflow
+	done   chan stepDone
+}
+
+type stepDone struct {
+	step *Job
+	err  error
+}
+
+// NewWorkflow creates a workflow, add the steps by Workflow.AddStep and then add it to a timer by Timer.AddWorkflow
+func NewWorkflow(opts WorkflowOpts) *Workflow {
+	wf := &Workflow{
+		name  : opts.Name,
+		groups: map[string]string{},
+		index : map[string]*Job{},
+	}
+
+	wf.job = createJob(JobOpts{
+		Name       : opts.Name,
+		Ctx        : opts.Ctx,
+		Interval   : opts.Interval,
+		Pattern    : opts.Pattern,
+		Location   : opts.Location,
+		CB         : wf.run,
+		IsSingleton: true,
+		status     : StatusWaiting,
+	})
+	wf.name = wf.job.name
+
+	return wf
+}
+
+// AddStep adds a step to workflow, the step will be run in group if set
+func (wf *Workflow) AddStep(step *Job, group ...string) *Workflow {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+
+	wf.steps = append(wf.steps, step)
+	if len(group) > 0 {
+		wf.groups[step.name] = group[0]
+	}
+	return wf
+}
+
+func (wf *Workflow) Name() string { return wf.name }
+func (wf *Workflow) Job()  *Job   { return wf.job }
+
+// Steps returns the steps in topological order after the workflow added to a timer
+func (wf *Workflow) Steps() []*Job {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+
+	if wf.order != nil {
+		return append([]*Job(nil), wf.order...)
+	}
+	return append([]*Job(nil), wf.steps...)
+}
+
+// TriggerNow runs the workflow immediately
+func (wf *Workflow) TriggerNow() error {
+	return wf.job.TriggerNow()
+}
+
+// __sort checks the steps and sorts them in topological order
+func (wf *Workflow) __sort() error {
+	indeg := map[string]int{}
+	for _, s := range wf.steps {
+		if wf.index[s.name] != nil {
+			return fmt.Errorf("step '%s' already exists", s.name)
+		}
+		wf.index[s.name] = s
+	}
+	for _, s := range wf.steps {
+		if s.trigger != nil {
+			return fmt.Errorf("step '%s' can not have a Trigger, it is only run by the workflow", s.name)
+		}
+		for _, dep := range s.deps {
+			if wf.index[dep] == nil {
+				return fmt.Errorf("step '%s' depends on '%s' which is not a step of workflow", s.name, dep)
+			}
+			indeg[s.name] += 1
+		}
+	}
+
+	var queue []*Job
+	for _, s := range wf.steps {
+		if indeg[s.name] == 0 {
+			queue = append(queue, s)
+		}
+	}
+	var order []*Job
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		order = append(order, s)
+		for _, c := range wf.__children(s) {
+			if indeg[c.name] -= 1; indeg[c.name] == 0 {
+				queue = append(queue, c)
+			}
+		}
+	}
+	if len(order) != len(wf.steps) {
+		var cycle []string
+		for _, s := range wf.steps {
+			if indeg[s.name] > 0 {
+				cycle = append(cycle, s.name)
+			}
+		}
+		return fmt.Errorf("steps have cycle dependencies: %s", strings.Join(cycle, ", "))
+	}
+
+	wf.order = order
+	return nil
+}
+
+// __children returns the steps which depend on s
+func (wf *Workflow) __children(s *Job) (out []*Job) {
+	for _, c := range wf.steps {
+		for _, dep := range c.deps {
+			if dep == s.name {
+				out = append(out, c)
+				break
+			}
+		}
+	}
+	return
+}
+
+// AddWorkflow adds the workflow and its steps to timer
+func (t *Timer) AddWorkflow(wf *Workflow) error {
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+
+	if wf.order != nil {
+		return fmt.Errorf("workflow '%s' already added", wf.name)
+	}
+	if len(wf.steps) == 0 {
+		return fmt.Errorf("workflow '%s' has no steps", wf.name)
+	}
+
+	var added []string
+	ok := false
+	defer func() {
+		if !ok {
+			for _, name := range added {
+				t.RemoveJob(name)
+			}
+			wf.order = nil
+			wf.index = map[string]*Job{}
+		}
+	}()
+
+	if err := wf.__sort(); err != nil {
+		return fmt.Errorf("workflow '%s': %s", wf.name, err)
+	}
+
+	wf.done = make(chan stepDone, len(wf.steps))
+	for _, s := range wf.order {
+		s.js.setSingleton(true)
+		s.after = func(err error) { wf.done <- stepDone{step: s, err: err} }
+
+		var group []string
+		if g, ok := wf.groups[s.name]; ok {
+			group = append(group, g)
+		}
+		if err := t.__addJob(s, false, group...); err != nil {
+			return fmt.Errorf("workflow '%s': add step '%s' failed: %s", wf.name, s.name, err)
+		}
+		added = append(added, s.name)
+	}
+
+	if err := t.__addJob(wf.job, true); err != nil {
+		return fmt.Errorf("workflow '%s': %s", wf.name, err)
+	}
+
+	ok = true
+	return nil
+}
+
+// run runs all the steps of workflow once, it is the JobFunc of workflow job
+func (wf *Workflow) run(j *Job) error {
+	var (
+		pending = map[*Job]int{}   // the count of deps not finished
+		running = 0
+		failed  []string
+		skipped = map[*Job]bool{}
+	)
+
+	submit := func(s *Job) {
+		running += 1
+		s.js.runner.submit(s)
+	}
+
+	var skip func(s *Job)
+	skip = func(s *Job) {
+		if skipped[s] {
+			return
+		}
+		skipped[s] = true
+		s.js.addSkip()
+		for _, c := range wf.__children(s) {
+			skip(c)
+		}
+	}
+
+	for _, s := range wf.order {
+		pending[s] = len(s.deps)
+		if len(s.deps) == 0 {
+			submit(s)
+		}
+	}
+
+	cancelled := false
+	for running > 0 {
+		var d stepDone
+		select {
+		case d = <-wf.done:
+		case <-j.Ctx().Done():
+			if !cancelled {
+				cancelled = true
+				for _, s := range wf.order {
+					if pending[s] > 0 {
+						skip(s)
+					} else {
+						s.__cancelRun()
+					}
+				}
+			}
+			d = <-wf.done
+		}
+		running -= 1
+
+		if d.err != nil {
+			failed = append(failed, fmt.Sprintf("step '%s': %s", d.step.name, d.err))
+			for _, c := range wf.__children(d.step) {
+				skip(c)
+			}
+			continue
+		}
+
+		for _, c := range wf.__children(d.step) {
+			if pending[c] -= 1; pending[c] == 0 && !skipped[c] {
+				submit(c)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("workflow '%s' failed: %s", wf.name, strings.Join(failed, "; "))
+	}
+	if cancelled {
+		return fmt.Errorf("workflow '%s' cancelled: %w", wf.name, j.Ctx().Err())
+	}
+	return nil
+}
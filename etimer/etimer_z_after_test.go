@@ -0,0 +1,83 @@
+package etimer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfter(t *testing.T) {
+	for _, sched := range []SchedulerType{SCHEDULER_HEAP, SCHEDULER_WHEEL} {
+		tm := NewTimer(TimerOptions{Interval: time.Millisecond, Scheduler: sched})
+
+		var cnt atomic.Int32
+		fn := func() { cnt.Add(1) }
+
+		// run once
+		start := time.Now()
+		var at atomic.Int64
+		task := tm.After(context.Background(), time.Millisecond * 20, func() { at.Store(time.Now().UnixNano()); fn() })
+		assert.True(t, task.Pending())
+		time.Sleep(time.Millisecond * 60)
+		assert.Equal(t, int32(1), cnt.Load())
+		assert.GreaterOrEqual(t, time.Duration(at.Load() - start.UnixNano()), time.Millisecond * 20)
+		assert.False(t, task.Pending())
+		assert.False(t, task.Stop())
+
+		// stopped
+		task = tm.After(nil, time.Millisecond * 20, fn)
+		assert.True(t, task.Stop())
+		assert.False(t, task.Stop())
+		time.Sleep(time.Millisecond * 40)
+		assert.Equal(t, int32(1), cnt.Load())
+		assert.Equal(t, 0, tm.queue.Len())
+
+		// reset to later
+		task = tm.After(nil, time.Millisecond * 20, fn)
+		time.Sleep(time.Millisecond * 10)
+		assert.True(t, task.Reset(time.Millisecond * 40))
+		time.Sleep(time.Millisecond * 20)
+		assert.Equal(t, int32(1), cnt.Load())
+		time.Sleep(time.Millisecond * 40)
+		assert.Equal(t, int32(2), cnt.Load())
+
+		// reset after run
+		assert.False(t, task.Reset(time.Millisecond * 10))
+		time.Sleep(time.Millisecond * 40)
+		assert.Equal(t, int32(3), cnt.Load())
+
+		// cancelled by ctx
+		ctx, cancel := context.WithCancel(context.Background())
+		task = tm.At(ctx, time.Now().Add(time.Millisecond * 20), fn)
+		cancel()
+		time.Sleep(time.Millisecond * 40)
+		assert.Equal(t, int32(3), cnt.Load())
+		assert.False(t, task.Pending())
+		assert.False(t, task.Reset(time.Millisecond))
+		assert.False(t, task.Pending())
+
+		// not scheduled after shutdown
+		assert.Equal(t, nil, tm.Shutdown(context.Background()))
+		task = tm.After(nil, time.Millisecond, fn)
+		assert.False(t, task.Pending())
+	}
+}
+
+func BenchmarkAfterStop(b *testing.B) {
+	for _, sched := range []SchedulerType{SCHEDULER_HEAP, SCHEDULER_WHEEL} {
+		tm := NewTimer(TimerOptions{Interval: time.Millisecond, Scheduler: sched})
+		for i := 0; i < 10000; i++ {
+			tm.After(nil, time.Hour, func() {})
+		}
+
+		b.Run([]string{"heap", "wheel"}[sched], func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tm.After(nil, time.Minute, func() {}).Stop()
+			}
+		})
+		tm.Shutdown(context.Background())
+	}
+}
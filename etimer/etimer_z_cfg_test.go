@@ -0,0 +1,135 @@
+package etimer
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+)
+
+const testJobsCfg = `
+etimer:
+  groups:
+    g1: 2
+  jobs:
+    cfg_job1:
+      func     : cfg_counter
+      interval : 10ms
+      group    : g1
+      times    : 3
+      args     :
+        step: 2
+    cfg_job2:
+      func     : cfg_counter
+      pattern  : "@every 1h"
+    cfg_job3:
+      func     : not_registered
+      interval : 10ms
+`
+
+func TestLoadJobsFromFile(t *testing.T) {
+	var cnt atomic.Int64
+	assert.Equal(t, nil, RegisterFunc("cfg_counter", func(j *Job) error {
+		cnt.Add(int64(j.Args()["step"].(int)))
+		return nil
+	}))
+	defer UnregisterFunc("cfg_counter")
+	assert.NotEqual(t, nil, RegisterFunc("cfg_counter", func(j *Job) error { return nil }))
+
+	path := filepath.Join(t.TempDir(), "jobs.yml")
+	assert.Equal(t, nil, os.WriteFile(path, []byte(testJobsCfg), 0644))
+
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	err := tm.LoadJobsFromFile(path)
+	assert.ErrorContains(t, err, "not_registered")
+
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, int64(6), cnt.Load())
+
+	tm.mu.RLock()
+	j1, j2, j3 := tm.jobs["cfg_job1"], tm.jobs["cfg_job2"], tm.jobs["cfg_job3"]
+	tm.mu.RUnlock()
+	assert.NotNil(t, j1)
+	assert.NotNil(t, j2)
+	assert.Nil(t, j3)
+	assert.Equal(t, "g1", j1.js.runner.name_)
+	assert.Equal(t, uint64(3), j1.State().Runnings())
+	assert.True(t, j2.IsSingleton())
+}
+
+func TestRestoreJobs(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	var cnt atomic.Int64
+	assert.Equal(t, nil, RegisterFunc("restore_counter", func(j *Job) error { cnt.Add(1); return nil }))
+	defer UnregisterFunc("restore_counter")
+
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	j := NewJob(&JobOpts{Name: "restore_job", Func: "restore_counter", Interval: time.Millisecond * 10, Times: 5, Args: map[string]any{"k": "v"}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	startAt, endAt := time.Now().Add(time.Hour).Truncate(time.Second), time.Now().Add(time.Hour * 2).Truncate(time.Second)
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "restore_window", Func: "restore_counter", Interval: time.Second,
+		Jitter: time.Millisecond * 100, Spread: true, StartAt: startAt, EndAt: endAt})))
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, int64(5), cnt.Load())
+
+	// rehydrated with the func, args and progress on startup
+	tm2 := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	tm2.mu.RLock()
+	j2 := tm2.jobs["restore_job"]
+	tm2.mu.RUnlock()
+	assert.NotNil(t, j2)
+	assert.Equal(t, "v", j2.Args()["k"])
+	assert.Equal(t, uint64(5), j2.State().Runnings())
+	assert.Equal(t, int64(0), j2.State().LeftTimes())
+	assert.Equal(t, int64(5), j2.State().Times())
+
+	// the schedule options are restored too
+	tm2.mu.RLock()
+	w := tm2.jobs["restore_window"]
+	tm2.mu.RUnlock()
+	assert.NotNil(t, w)
+	want := newScheduleOpts("restore_window", time.Millisecond * 100, true, startAt, endAt)
+	assert.Equal(t, want.jitter, w.sopts.jitter)
+	assert.Equal(t, want.spread, w.sopts.spread)
+	assert.True(t, want.startAt.Equal(w.sopts.startAt))
+	assert.True(t, want.endAt.Equal(w.sopts.endAt))
+	assert.Equal(t, nil, tm2.RestoreJobs())
+}
+
+func TestRestoreGroupedJobs(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	assert.Equal(t, nil, RegisterFunc("restore_grouped", func(j *Job) error { return nil }))
+	defer UnregisterFunc("restore_grouped")
+
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	tm.SetGroup("g1", 1)
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "grouped_job", Func: "restore_grouped", Interval: time.Hour}), "g1"))
+
+	// the group not exists when restoring
+	tm2 := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	assert.NotEqual(t, nil, tm2.RestoreErr())
+	tm2.mu.RLock()
+	assert.Nil(t, tm2.jobs["grouped_job"])
+	tm2.mu.RUnlock()
+
+	// the groups are created before restoring
+	tm3 := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store, Groups: map[string]int{"g1": 1}})
+	assert.Equal(t, nil, tm3.RestoreErr())
+	tm3.mu.RLock()
+	j := tm3.jobs["grouped_job"]
+	tm3.mu.RUnlock()
+	assert.NotNil(t, j)
+	assert.Equal(t, "g1", j.State().Group())
+}
@@ -0,0 +1,104 @@
+package etimer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cronRuns(t *testing.T, pattern string, loc *time.Location, from time.Time, n int) []time.Time {
+	tm := NewTimer()
+
+	j := NewJob(&JobOpts{Name: "cron", Pattern: pattern, Location: loc, CB: func(j *Job) error { return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	return nextRuns(j.sched, from, n)
+}
+
+func TestCronPatterns(t *testing.T) {
+	sh, _ := time.LoadLocation("Asia/Shanghai")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, sh)
+
+	// seconds field
+	runs := cronRuns(t, "*/20 * * * * *", sh, from, 3)
+	assert.Equal(t, []time.Time{from.Add(time.Second * 20), from.Add(time.Second * 40), from.Add(time.Minute)}, runs)
+
+	// descriptors
+	runs = cronRuns(t, "@every 90s", nil, from, 2)
+	assert.Equal(t, []time.Time{from.Add(time.Second * 90), from.Add(time.Second * 180)}, runs)
+	runs = cronRuns(t, "@daily", sh, from, 2)
+	assert.Equal(t, []time.Time{from.AddDate(0, 0, 1), from.AddDate(0, 0, 2)}, runs)
+
+	// the CRON_TZ= prefix has high priority than Location
+	runs = cronRuns(t, "CRON_TZ=UTC 0 0 * * *", sh, from, 1)
+	assert.True(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Equal(runs[0]))
+
+	// multi patterns joined by ';', and the list fields are kept
+	runs = cronRuns(t, "0,30 8 * * *; 0 9 * * *", sh, from, 4)
+	assert.Equal(t, []time.Time{
+		from.Add(time.Hour * 8), from.Add(time.Hour * 8 + time.Minute * 30), from.Add(time.Hour * 9), from.Add(time.Hour * 32),
+	}, runs)
+
+	// old format joined by ','
+	runs = cronRuns(t, "0 8 * * *, 0 9 * * *", sh, from, 2)
+	assert.Equal(t, []time.Time{from.Add(time.Hour * 8), from.Add(time.Hour * 9)}, runs)
+}
+
+func TestCronDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Equal(t, nil, err)
+
+	// spring forward: 2024-03-10 02:00 -> 03:00, 02:30 does not exist on that day
+	from := time.Date(2024, 3, 9, 12, 0, 0, 0, ny)
+	runs := cronRuns(t, "30 2 * * *", ny, from, 2)
+	assert.Equal(t, 2, len(runs))
+	assert.Equal(t, time.Date(2024, 3, 11, 2, 30, 0, 0, ny), runs[0])
+	assert.Equal(t, time.Date(2024, 3, 12, 2, 30, 0, 0, ny), runs[1])
+
+	// hourly runs across spring forward keep the real interval of 1h
+	runs = cronRuns(t, "0 * * * *", ny, time.Date(2024, 3, 10, 0, 30, 0, 0, ny), 3)
+	assert.Equal(t, time.Hour, runs[1].Sub(runs[0]))
+	assert.Equal(t, time.Hour, runs[2].Sub(runs[1]))
+	assert.Equal(t, 3, runs[1].Hour())
+
+	// fall back: 2024-11-03 02:00 -> 01:00, 01:30 appears twice, in EDT and EST
+	from = time.Date(2024, 11, 2, 12, 0, 0, 0, ny)
+	runs = cronRuns(t, "30 1 * * *", ny, from, 3)
+	assert.Equal(t, "2024-11-03 01:30:00 -0400 EDT", runs[0].String())
+	assert.Equal(t, "2024-11-03 01:30:00 -0500 EST", runs[1].String())
+	assert.Equal(t, time.Hour, runs[1].Sub(runs[0]))
+	assert.Equal(t, time.Date(2024, 11, 4, 1, 30, 0, 0, ny), runs[2])
+
+	// the daily runs at 08:00 local time keep the wall clock across DST
+	runs = cronRuns(t, "0 8 * * *", ny, time.Date(2024, 11, 2, 0, 0, 0, 0, ny), 2)
+	assert.Equal(t, 8, runs[0].Hour())
+	assert.Equal(t, 8, runs[1].Hour())
+	assert.Equal(t, time.Hour * 25, runs[1].Sub(runs[0]))
+}
+
+func TestCronSeconds(t *testing.T) {
+	var cnt atomic.Int32
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond * 10})
+
+	j := NewJob(&JobOpts{Name: "sec", Pattern: "* * * * * *", CB: func(j *Job) error { cnt.Add(1); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+
+	runs := j.NextRuns(3)
+	assert.Equal(t, 3, len(runs))
+	assert.Equal(t, time.Second, runs[2].Sub(runs[1]))
+
+	time.Sleep(time.Millisecond * 2500)
+	assert.GreaterOrEqual(t, cnt.Load(), int32(2))
+
+	// multi patterns
+	var cnt2 atomic.Int32
+	j = NewJob(&JobOpts{Name: "sec2", Pattern: "0-29 * * * * *; 30-59 * * * * *", CB: func(j *Job) error { cnt2.Add(1); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	assert.Equal(t, 2, len(j.NextRuns(2)))
+	time.Sleep(time.Millisecond * 2500)
+	assert.GreaterOrEqual(t, cnt2.Load(), int32(2))
+
+	assert.Nil(t, NewJob(&JobOpts{Name: "not_added", Pattern: "@hourly"}).NextRuns(1))
+}
@@ -3,8 +3,11 @@ package etimer
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 
@@ -25,4 +28,67 @@ func TestXxx(t *testing.T) {
 
 
 
-}
\ No newline at end of file
+}
+
+// the jobs scheduled by timer are submitted to the runner of their group, so the limit of group applies
+func TestGroupLimit(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 1)
+
+	var cnt, cur, maxCur atomic.Int32
+	cb := func(j *Job) error {
+		if c := cur.Add(1); c > maxCur.Load() {
+			maxCur.Store(c)
+		}
+		time.Sleep(time.Millisecond * 10)
+		cur.Add(-1)
+		cnt.Add(1)
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		j := NewJob(&JobOpts{Name: fmt.Sprintf("limited_%d", i), Interval: time.Millisecond * 5, CB: cb})
+		assert.Equal(t, nil, tm.AddJob(j, "g1"))
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	tm.Shutdown(context.Background())
+	assert.Greater(t, cnt.Load(), int32(3))
+	assert.Equal(t, int32(1), maxCur.Load())
+}
+
+// the runner submits all the queued jobs on a wakeup until the group is full, the wakeups of a burst are merged
+func TestGroupBurst(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 3)
+
+	var cur, maxCur atomic.Int32
+	cb := func(j *Job) error {
+		if c := cur.Add(1); c > maxCur.Load() {
+			maxCur.Store(c)
+		}
+		time.Sleep(time.Millisecond * 50)
+		cur.Add(-1)
+		return nil
+	}
+	var jobs []*Job
+	for i := 0; i < 5; i++ {
+		j := NewJob(&JobOpts{Name: fmt.Sprintf("burst_%d", i), Interval: time.Hour, CB: cb})
+		assert.Equal(t, nil, tm.AddJob(j, "g1"))
+		jobs = append(jobs, j)
+	}
+	for _, j := range jobs {
+		tm.submitJob(j)
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, int32(3), cur.Load())
+	gs, _ := tm.GroupStats("g1")
+	assert.Equal(t, GroupStats{Name: "g1", Running: 3, Pending: 2, Max: 3}, gs)
+
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(0), cur.Load())
+	assert.Equal(t, int32(3), maxCur.Load())
+	for _, j := range jobs {
+		assert.Equal(t, uint64(1), j.State().Successs())
+	}
+}
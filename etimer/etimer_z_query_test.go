@@ -0,0 +1,75 @@
+package etimer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+)
+
+func TestQuery(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	tm.SetGroup("g1", 1)
+	tm.SetGroup("g2", 3)
+
+	cb := func(j *Job) error { time.Sleep(time.Millisecond * 100); return nil }
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "j3", Interval: time.Hour, CB: cb}), "g1"))
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "j1", Interval: time.Hour, CB: cb})))
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "j2", Interval: time.Hour, CB: cb}), "g1"))
+
+	states := tm.AllJobStates()
+	assert.Equal(t, 3, len(states))
+	assert.Equal(t, "j1", states[0].Name())
+	assert.Equal(t, "j2", states[1].Name())
+	assert.Equal(t, "j3", states[2].Name())
+	assert.Equal(t, "g1", states[1].Group())
+	assert.Equal(t, "", states[0].Group())
+
+	assert.Equal(t, "j2", tm.Job("j2").Name())
+	assert.Nil(t, tm.Job("not_exist"))
+
+	jobs := tm.Jobs(ByGroup("g1"))
+	assert.Equal(t, 2, len(jobs))
+	assert.Equal(t, "j2", jobs[0].Name())
+	assert.Equal(t, 1, len(tm.Jobs(ByGroup(""))))
+	assert.Equal(t, 3, len(tm.Jobs(ByStatus(StatusWaiting))))
+
+	assert.Equal(t, []string{"g1", "g2"}, tm.Groups())
+
+	// the group g1 runs one job at a time
+	tm.submitJob(tm.Job("j2"))
+	tm.submitJob(tm.Job("j3"))
+	time.Sleep(time.Millisecond * 20)
+	gs, ok := tm.GroupStats("g1")
+	assert.True(t, ok)
+	assert.Equal(t, GroupStats{Name: "g1", Running: 1, Pending: 1, Max: 1}, gs)
+	gs, _ = tm.GroupStats("g2")
+	assert.Equal(t, GroupStats{Name: "g2", Running: 0, Pending: 0, Max: 3}, gs)
+	_, ok = tm.GroupStats("not_exist")
+	assert.False(t, ok)
+
+	// the idle workers of group are not counted in Running
+	time.Sleep(time.Millisecond * 250)
+	gs, _ = tm.GroupStats("g1")
+	assert.Equal(t, GroupStats{Name: "g1", Running: 0, Pending: 0, Max: 1}, gs)
+
+	// remove
+	j1 := tm.Job("j1")
+	assert.True(t, tm.RemoveJob("j1"))
+	assert.False(t, tm.RemoveJob("j1"))
+	assert.Equal(t, StatusClosed, j1.State().Status())
+	assert.Nil(t, tm.Job("j1"))
+	assert.Equal(t, 2, len(tm.AllJobStates()))
+	rec, err := store.Load("j1")
+	assert.Equal(t, nil, err)
+	assert.Nil(t, rec)
+
+	// can be added again after removed
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "j1", Interval: time.Hour, CB: cb})))
+}
@@ -0,0 +1,159 @@
+package etimer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type runTimes struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (r *runTimes) cb(j *Job) error {
+	r.mu.Lock()
+	r.times = append(r.times, time.Now())
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *runTimes) get() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Time(nil), r.times...)
+}
+
+func TestJitter(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	var r runTimes
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "jitter", Interval: time.Millisecond * 20, Jitter: time.Millisecond * 15, CB: r.cb})))
+	time.Sleep(time.Millisecond * 410)
+
+	// the cadence is kept, the gaps are random
+	times := r.get()
+	assert.GreaterOrEqual(t, len(times), 15)
+	assert.LessOrEqual(t, len(times), 21)
+
+	minGap, maxGap := time.Hour, time.Duration(0)
+	for i := 1; i < len(times); i++ {
+		gap := times[i].Sub(times[i - 1])
+		minGap, maxGap = min(minGap, gap), max(maxGap, gap)
+	}
+	assert.Greater(t, maxGap - minGap, time.Millisecond * 5)
+}
+
+func TestJitterSubTick(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond * 10})
+
+	// the jitter less than the interval of timer is rounded up to a tick
+	var r runTimes
+	start := time.Now()
+	j := NewJob(&JobOpts{Name: "jitter_sub_tick", Interval: time.Millisecond * 50, Jitter: time.Millisecond * 5, CB: r.cb})
+	assert.Equal(t, nil, tm.AddJob(j))
+	assert.GreaterOrEqual(t, j.State().NextStart().Sub(start), time.Millisecond * 60)
+
+	time.Sleep(time.Millisecond * 230)
+	times := r.get()
+	assert.GreaterOrEqual(t, len(times), 3)
+	assert.LessOrEqual(t, len(times), 4)
+	assert.GreaterOrEqual(t, times[0].Sub(start), time.Millisecond * 55)
+}
+
+func TestSpread(t *testing.T) {
+	interval := time.Millisecond * 100
+	offset := func(j *Job) time.Duration {
+		return time.Duration(j.sopts.spread * float64(interval))
+	}
+
+	// the ticks of SCHEDULER_WHEEL are counted by time, the ticker of SCHEDULER_HEAP may lag behind under load
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Scheduler: SCHEDULER_WHEEL})
+	var r runTimes
+	j1 := NewJob(&JobOpts{Name: "spread1", Interval: interval, Spread: true, CB: r.cb})
+	j2 := NewJob(&JobOpts{Name: "spread2", Interval: interval, Spread: true, CB: func(j *Job) error { return nil }})
+	assert.Equal(t, nil, tm.AddJob(j1))
+	assert.Equal(t, nil, tm.AddJob(j2))
+
+	// stable by name
+	assert.Equal(t, offset(j1), offset(NewJob(&JobOpts{Name: "spread1", Spread: true})))
+	assert.NotEqual(t, offset(j1), offset(j2))
+
+	// aligned to the offset
+	for _, j := range []*Job{j1, j2} {
+		runs := j.NextRuns(3)
+		assert.Equal(t, 3, len(runs))
+		for _, next := range runs {
+			assert.Equal(t, offset(j), time.Duration(next.UnixNano() % int64(interval)))
+		}
+		assert.Equal(t, interval, runs[2].Sub(runs[1]))
+	}
+
+	time.Sleep(time.Millisecond * 350)
+	times := r.get()
+	assert.GreaterOrEqual(t, len(times), 3)
+	for _, at := range times {
+		d := (time.Duration(at.UnixNano() % int64(interval)) - offset(j1) + interval) % interval
+		assert.Less(t, d, time.Millisecond * 10)
+	}
+}
+
+func TestSpreadCron(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond * 10})
+
+	var r runTimes
+	j := NewJob(&JobOpts{Name: "spread_cron", Pattern: "* * * * * *", Spread: true, CB: r.cb})
+	assert.Equal(t, nil, tm.AddJob(j))
+
+	spread := j.sched.(*scheduleCron).spread_
+	assert.Less(t, spread, time.Second)
+	for _, next := range j.NextRuns(3) {
+		assert.Equal(t, spread, next.Sub(next.Truncate(time.Second)))
+	}
+
+	time.Sleep(time.Millisecond * 2500)
+	times := r.get()
+	assert.GreaterOrEqual(t, len(times), 1)
+	for _, at := range times {
+		d := (at.Sub(at.Truncate(time.Second)) - spread + time.Second) % time.Second
+		assert.Less(t, d, time.Millisecond * 50)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	// counted by time like TestSpread, the ticker of SCHEDULER_HEAP may lag behind under load
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Scheduler: SCHEDULER_WHEEL})
+
+	var r runTimes
+	start := time.Now()
+	j := NewJob(&JobOpts{Name: "window", Interval: time.Millisecond * 20, StartAt: start.Add(time.Millisecond * 100),
+		EndAt: start.Add(time.Millisecond * 200), CB: r.cb})
+	assert.Equal(t, nil, tm.AddJob(j))
+
+	runs := j.NextRuns(10)
+	assert.Equal(t, 6, len(runs))
+	assert.False(t, runs[0].Before(start.Add(time.Millisecond * 100)))
+	assert.False(t, runs[len(runs) - 1].After(start.Add(time.Millisecond * 200)))
+
+	time.Sleep(time.Millisecond * 300)
+	times := r.get()
+	assert.GreaterOrEqual(t, len(times), 4)
+	assert.LessOrEqual(t, len(times), 6)
+	for _, at := range times {
+		assert.False(t, at.Before(start.Add(time.Millisecond * 100)))
+		assert.False(t, at.After(start.Add(time.Millisecond * 205)))
+	}
+	assert.Equal(t, int(StatusStopped), j.State().Status())
+
+	// cron
+	from := time.Now().Truncate(time.Second).Add(time.Hour)
+	j = NewJob(&JobOpts{Name: "window_cron", Pattern: "0 * * * * *", StartAt: from, EndAt: from.Add(time.Minute * 5),
+		CB: func(j *Job) error { return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	runs = j.NextRuns(10)
+	assert.Equal(t, 5, len(runs))
+	assert.False(t, runs[0].Before(from))
+	assert.False(t, runs[4].After(from.Add(time.Minute * 5)))
+}
@@ -0,0 +1,126 @@
+package etimer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond * 10, MaxBackoff: time.Millisecond * 50}
+	assert.Equal(t, time.Millisecond * 10, p.backoff(1))
+	assert.Equal(t, time.Millisecond * 20, p.backoff(2))
+	assert.Equal(t, time.Millisecond * 40, p.backoff(3))
+	assert.Equal(t, time.Millisecond * 50, p.backoff(4))
+	assert.Equal(t, time.Millisecond * 50, p.backoff(100))
+
+	p = &RetryPolicy{Backoff: time.Millisecond * 100, Multiplier: 1, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := p.backoff(3)
+		assert.GreaterOrEqual(t, d, time.Millisecond * 50)
+		assert.LessOrEqual(t, d, time.Millisecond * 150)
+	}
+
+	assert.Equal(t, dfRetryBackoff, (&RetryPolicy{}).backoff(1))
+
+	errTemp := errors.New("temporary")
+	p = &RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool { return errors.Is(err, errTemp) }}
+	assert.True(t, p.retryable(1, errTemp))
+	assert.True(t, p.retryable(2, errTemp))
+	assert.False(t, p.retryable(3, errTemp))
+	assert.False(t, p.retryable(1, errors.New("fatal")))
+	assert.False(t, p.retryable(1, nil))
+	assert.False(t, (*RetryPolicy)(nil).retryable(1, errTemp))
+}
+
+func TestRetry(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	errTemp := errors.New("temporary")
+
+	// succeeds in the third attempt
+	var cnt atomic.Int32
+	j := NewJob(&JobOpts{Name: "retry1", Interval: time.Hour, IsSingleton: true,
+		Retry: &RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond * 10},
+		CB: func(j *Job) error {
+			if cnt.Add(1) < 3 {
+				return errTemp
+			}
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 200)
+
+	assert.Equal(t, int32(3), cnt.Load())
+	assert.Equal(t, uint64(2), j.State().Retries())
+	assert.Equal(t, 3, j.State().Attempt())
+	assert.Equal(t, uint64(1), j.State().Successs())
+	assert.Equal(t, uint64(0), j.State().Failures())
+	assert.Equal(t, 1, len(j.State().Errors()))
+	assert.Equal(t, uint64(2), j.State().Errors()[0].Count())
+
+	// exhausted
+	cnt.Store(0)
+	j = NewJob(&JobOpts{Name: "retry2", Interval: time.Hour, IsSingleton: true,
+		Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond * 10},
+		CB: func(j *Job) error { cnt.Add(1); return errTemp }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 200)
+
+	assert.Equal(t, int32(3), cnt.Load())
+	assert.Equal(t, uint64(2), j.State().Retries())
+	assert.Equal(t, uint64(1), j.State().Failures())
+	assert.Equal(t, errTemp, j.State().LastError())
+	assert.Equal(t, StatusWaiting, j.State().Status())
+
+	// not retryable
+	cnt.Store(0)
+	j = NewJob(&JobOpts{Name: "retry3", Interval: time.Hour, IsSingleton: true,
+		Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond * 10, Retryable: func(err error) bool { return errors.Is(err, errTemp) }},
+		CB: func(j *Job) error { cnt.Add(1); return errors.New("fatal") }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 100)
+
+	assert.Equal(t, int32(1), cnt.Load())
+	assert.Equal(t, uint64(0), j.State().Retries())
+	assert.Equal(t, uint64(1), j.State().Failures())
+}
+
+func TestRetryReleasesSlots(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 1)
+	errTemp := errors.New("temporary")
+
+	var cnt atomic.Int32
+	j := NewJob(&JobOpts{Name: "retry_slot", Interval: time.Hour, IsSingleton: true,
+		Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond * 100},
+		CB: func(j *Job) error { cnt.Add(1); return errTemp }})
+	var other atomic.Int32
+	o := NewJob(&JobOpts{Name: "other", Interval: time.Hour, CB: func(j *Job) error { other.Add(1); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j, "g1"))
+	assert.Equal(t, nil, tm.AddJob(o, "g1"))
+
+	// the other job of group runs during the backoff
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 30)
+	tm.submitJob(o)
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, int32(1), cnt.Load())
+	assert.Equal(t, int32(1), other.Load())
+	assert.Equal(t, uint64(1), j.State().Runnings())
+	assert.Equal(t, uint64(0), j.State().Failures())
+	gs, _ := tm.GroupStats("g1")
+	assert.Equal(t, 0, gs.Running)
+
+	// the run waiting to retry is finished by shutdown with the error of last attempt
+	assert.Equal(t, nil, tm.Shutdown(context.Background()))
+	assert.Equal(t, int32(1), cnt.Load())
+	assert.Equal(t, uint64(1), j.State().Failures())
+	assert.Equal(t, errTemp, j.State().LastError())
+}
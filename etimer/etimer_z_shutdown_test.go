@@ -0,0 +1,77 @@
+package etimer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseResume(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	var cnt atomic.Int32
+	j := NewJob(&JobOpts{Name: "pause", Interval: time.Millisecond * 10, CB: func(j *Job) error { cnt.Add(1); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	time.Sleep(time.Millisecond * 50)
+	assert.Greater(t, cnt.Load(), int32(0))
+
+	tm.Pause()
+	assert.Equal(t, StatusStopped, tm.Status())
+	time.Sleep(time.Millisecond * 20)
+	paused := cnt.Load()
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, paused, cnt.Load())
+
+	tm.Resume()
+	assert.Equal(t, StatusRunning, tm.Status())
+	time.Sleep(time.Millisecond * 50)
+	assert.Greater(t, cnt.Load(), paused)
+}
+
+func TestShutdown(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 1)
+
+	// waits for the running jobs
+	var done atomic.Int32
+	j := NewJob(&JobOpts{Name: "slow", Interval: time.Millisecond * 10, CB: func(j *Job) error {
+		time.Sleep(time.Millisecond * 50)
+		done.Add(1)
+		return nil
+	}})
+	assert.Equal(t, nil, tm.AddJob(j, "g1"))
+
+	// cancelled by ctx of job
+	var cancelled atomic.Bool
+	j2 := NewJob(&JobOpts{Name: "cancel", Interval: time.Hour, CB: func(j *Job) error {
+		<-j.Ctx().Done()
+		cancelled.Store(true)
+		return nil
+	}})
+	assert.Equal(t, nil, tm.AddJob(j2))
+	tm.submitJob(j2)
+	time.Sleep(time.Millisecond * 30)
+
+	assert.Equal(t, nil, tm.Shutdown(context.Background()))
+	assert.Equal(t, StatusClosed, tm.Status())
+	assert.Equal(t, int32(1), done.Load())
+	assert.True(t, cancelled.Load())
+
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, int32(1), done.Load())
+	assert.NotEqual(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "after", Interval: time.Second, CB: func(j *Job) error { return nil }})))
+
+	// ctx done before the running jobs return
+	tm = NewTimer(TimerOptions{Interval: time.Millisecond})
+	j = NewJob(&JobOpts{Name: "hung", Interval: time.Hour, CB: func(j *Job) error { time.Sleep(time.Millisecond * 200); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond * 20)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, tm.Shutdown(ctx))
+}
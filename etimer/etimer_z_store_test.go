@@ -0,0 +1,99 @@
+package etimer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ziyht/eden_go/ecache"
+)
+
+func TestStore(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	// the progress is persisted after each run
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	j := NewJob(&JobOpts{Name: "j1", Interval: time.Millisecond * 10, Times: 3, CB: func(j *Job) error { return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+	time.Sleep(time.Millisecond * 300)
+
+	rec, err := store.Load("j1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(3), rec.Runnings)
+	assert.Equal(t, int64(3), rec.Times)
+	assert.Equal(t, int64(0), rec.LeftTimes)
+	assert.False(t, rec.LastStart.IsZero())
+
+	// restored by name in another timer, it will not run again
+	var cnt atomic.Int32
+	tm2 := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	j = NewJob(&JobOpts{Name: "j1", Interval: time.Millisecond * 10, Times: 3, CB: func(j *Job) error { cnt.Add(1); return nil }})
+	assert.Equal(t, nil, tm2.AddJob(j))
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(0), cnt.Load())
+	assert.Equal(t, uint64(3), j.State().Runnings())
+	assert.Equal(t, StatusStopped, j.State().Status())
+
+	rec, err = store.Load("not_exist")
+	assert.Equal(t, nil, err)
+	assert.Nil(t, rec)
+}
+
+func TestStoreMisfire(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	cases := []struct {
+		policy MisfirePolicy
+		runs   int32
+	}{
+		{MISFIRE_RUN_ONCE, 1},
+		{MISFIRE_RUN_ALL , 3},
+		{MISFIRE_SKIP    , 0},
+	}
+
+	for _, cs := range cases {
+		// 3 runs missed: -2.5h, -1.5h, -0.5h
+		assert.Equal(t, nil, store.Save(&JobRecord{Name: "j2", Interval: time.Hour, Runnings: 5, NextStart: time.Now().Add(-time.Hour * 5 / 2)}))
+
+		var cnt atomic.Int32
+		tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store, Misfire: cs.policy})
+		j := NewJob(&JobOpts{Name: "j2", Interval: time.Hour, CB: func(j *Job) error { cnt.Add(1); return nil }})
+		assert.Equal(t, nil, tm.AddJob(j))
+		time.Sleep(time.Millisecond * 200)
+
+		assert.Equal(t, cs.runs, cnt.Load(), "policy %d", cs.policy)
+		assert.Equal(t, uint64(5 + cs.runs), j.State().Runnings())
+
+		rec, err := store.Load("j2")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(5 + cs.runs), rec.Runnings)
+	}
+}
+
+// run with -race, the job state is persisted while the other runs of job are updating it
+func TestStoreConcurrentRuns(t *testing.T) {
+	c, err := ecache.NewDBCache(ecache.DBCacheOpts{Dsn: "badger:" + t.TempDir()})
+	assert.Equal(t, nil, err)
+	defer c.Close()
+	store := NewECacheStore(c.NewRegion("jobs"))
+
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond, Store: store})
+	j := NewJob(&JobOpts{Name: "concurrent", Interval: time.Millisecond, Times: 50, CB: func(j *Job) error {
+		time.Sleep(time.Millisecond * 3)
+		return nil
+	}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	time.Sleep(time.Millisecond * 300)
+
+	rec, err := store.Load("concurrent")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, j.State().Runnings(), rec.Runnings)
+	assert.Equal(t, j.State().LastEnd().UnixNano(), rec.LastEnd.UnixNano())
+}
@@ -0,0 +1,147 @@
+package etimer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestTimeout(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	// the run is cancelled at the deadline, and the values of JobOpts.Ctx are kept
+	var val atomic.Value
+	j := NewJob(&JobOpts{Name: "timeout", Interval: time.Hour, IsSingleton: true, Timeout: time.Millisecond * 20,
+		Ctx: context.WithValue(context.Background(), ctxKey{}, "v"),
+		CB: func(j *Job) error {
+			val.Store(j.Ctx().Value(ctxKey{}))
+			<-j.Ctx().Done()
+			return j.Ctx().Err()
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 100)
+
+	assert.Equal(t, "v", val.Load())
+	assert.Equal(t, uint64(1), j.State().Timeouts())
+	assert.Equal(t, uint64(1), j.State().Failures())
+	assert.True(t, errors.Is(j.State().LastError(), ErrJobTimeout))
+
+	// each attempt of retries has its own deadline
+	j = NewJob(&JobOpts{Name: "timeout_retry", Interval: time.Hour, IsSingleton: true, Timeout: time.Millisecond * 20,
+		Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Retryable: func(err error) bool { return errors.Is(err, ErrJobTimeout) }},
+		CB: func(j *Job) error {
+			<-j.Ctx().Done()
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 200)
+
+	assert.Equal(t, uint64(3), j.State().Timeouts())
+	assert.Equal(t, uint64(2), j.State().Retries())
+	assert.Equal(t, uint64(1), j.State().Failures())
+}
+
+func TestCancelByClose(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	var cancelled atomic.Bool
+	j := NewJob(&JobOpts{Name: "close", Interval: time.Hour, IsSingleton: true,
+		CB: func(j *Job) error {
+			<-j.Ctx().Done()
+			cancelled.Store(true)
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 20)
+	assert.False(t, cancelled.Load())
+
+	j.Close()
+	time.Sleep(time.Millisecond * 20)
+	assert.True(t, cancelled.Load())
+	assert.Equal(t, uint64(0), j.State().Timeouts())
+
+	// cancelled by timer
+	cancelled.Store(false)
+	j = NewJob(&JobOpts{Name: "timer_cancel", Interval: time.Hour, IsSingleton: true,
+		CB: func(j *Job) error {
+			<-j.Ctx().Done()
+			cancelled.Store(true)
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 20)
+	tm.cancel()
+	time.Sleep(time.Millisecond * 20)
+	assert.True(t, cancelled.Load())
+}
+
+func TestOverlap(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	// queue the runs and run them one by one
+	var cnt, cur, maxCur atomic.Int32
+	j := NewJob(&JobOpts{Name: "allow", Interval: time.Hour,
+		CB: func(j *Job) error {
+			if c := cur.Add(1); c > maxCur.Load() {
+				maxCur.Store(c)
+			}
+			time.Sleep(time.Millisecond * 20)
+			cur.Add(-1)
+			cnt.Add(1)
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	tm.submitJob(j)
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(3), cnt.Load())
+	assert.Equal(t, int32(1), maxCur.Load())
+
+	// skip the runs while the previous one is running
+	cnt.Store(0)
+	j = NewJob(&JobOpts{Name: "skip", Interval: time.Hour, IsSingleton: true, Overlap: OVERLAP_SKIP,
+		CB: func(j *Job) error {
+			cnt.Add(1)
+			time.Sleep(time.Millisecond * 50)
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 10)
+	tm.submitJob(j)
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(1), cnt.Load())
+
+	// cancel the running one and run again
+	var cancels atomic.Int32
+	cnt.Store(0)
+	j = NewJob(&JobOpts{Name: "restart", Interval: time.Hour, Overlap: OVERLAP_RESTART,
+		CB: func(j *Job) error {
+			cnt.Add(1)
+			select {
+			case <-j.Ctx().Done():
+				cancels.Add(1)
+			case <-time.After(time.Millisecond * 50):
+			}
+			return nil
+		}})
+	assert.Equal(t, nil, tm.AddJob(j))
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 10)
+	tm.submitJob(j)
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(2), cnt.Load())
+	assert.Equal(t, int32(1), cancels.Load())
+}
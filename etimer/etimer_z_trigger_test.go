@@ -0,0 +1,106 @@
+package etimer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerNow(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	var cnt atomic.Int32
+	j := NewJob(&JobOpts{Name: "now", Interval: time.Hour, Times: 3, IsSingleton: true, CB: func(j *Job) error {
+		cnt.Add(1)
+		time.Sleep(time.Millisecond * 20)
+		return nil
+	}})
+	assert.NotEqual(t, nil, j.TriggerNow())
+	assert.Equal(t, nil, tm.AddJob(j))
+
+	// singleton: one running and one pending
+	assert.Equal(t, nil, j.TriggerNow())
+	time.Sleep(time.Millisecond * 5)
+	assert.Equal(t, nil, j.TriggerNow())
+	assert.Equal(t, nil, j.TriggerNow())
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(2), cnt.Load())
+	assert.Equal(t, int64(1), j.State().LeftTimes())
+
+	// counted in Times
+	assert.Equal(t, nil, j.TriggerNow())
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, int32(3), cnt.Load())
+	assert.Equal(t, int64(0), j.State().LeftTimes())
+	assert.NotEqual(t, nil, j.TriggerNow())
+
+	j.Close()
+	assert.NotEqual(t, nil, j.TriggerNow())
+}
+
+func TestTriggerAt(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	var at atomic.Int64
+	j := NewJob(&JobOpts{Name: "at", Interval: time.Hour, CB: func(j *Job) error { at.Store(time.Now().UnixNano()); return nil }})
+	assert.Equal(t, nil, tm.AddJob(j))
+
+	start := time.Now()
+	assert.Equal(t, nil, j.TriggerAt(start.Add(time.Millisecond * 50)))
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, int64(0), at.Load())
+	time.Sleep(time.Millisecond * 50)
+	assert.GreaterOrEqual(t, time.Duration(at.Load() - start.UnixNano()), time.Millisecond * 50)
+
+	// cancelled by Close
+	at.Store(0)
+	assert.Equal(t, nil, j.TriggerAt(time.Now().Add(time.Millisecond * 30)))
+	j.Close()
+	time.Sleep(time.Millisecond * 60)
+	assert.Equal(t, int64(0), at.Load())
+}
+
+func TestTriggerChan(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 1)
+
+	var cnt, cur, maxCur atomic.Int32
+	ch := make(chan struct{})
+	j := NewJob(&JobOpts{Name: "chan", Trigger: ch, CB: func(j *Job) error {
+		if c := cur.Add(1); c > maxCur.Load() {
+			maxCur.Store(c)
+		}
+		time.Sleep(time.Millisecond * 10)
+		cur.Add(-1)
+		cnt.Add(1)
+		return nil
+	}})
+	assert.Equal(t, nil, tm.AddJob(j, "g1"))
+
+	// not scheduled without Interval and Pattern
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, int32(0), cnt.Load())
+
+	ch <- struct{}{}
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, int32(1), cnt.Load())
+
+	// the receivings are ignored if stopped
+	j.Stop()
+	ch <- struct{}{}
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, int32(1), cnt.Load())
+	j.Start()
+
+	// not singleton, but runs within the limit of group
+	for i := 0; i < 3; i++ {
+		ch <- struct{}{}
+	}
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(1), maxCur.Load())
+	assert.GreaterOrEqual(t, cnt.Load(), int32(2))
+
+	close(ch)
+}
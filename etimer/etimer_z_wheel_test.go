@@ -0,0 +1,152 @@
+package etimer
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimingWheel(t *testing.T) {
+	testJobQueue(t, newTimingWheel())
+}
+
+func TestPriorityQueue(t *testing.T) {
+	testJobQueue(t, newPriorityQueue())
+}
+
+func testJobQueue(t *testing.T, w jobQueue) {
+	r := rand.New(rand.NewSource(1))
+
+	type item struct{ id int }
+	prios := map[*item]int64{}
+	for i := 0; i < 5000; i++ {
+		var p int64
+		switch i % 4 {
+		case 0: p = r.Int63n(256)
+		case 1: p = r.Int63n(1 << 16)
+		case 2: p = r.Int63n(1 << 24)
+		case 3: p = r.Int63n(1 << 34)
+		}
+		it := &item{i}
+		prios[it] = p
+		w.Push(it, p)
+	}
+	assert.Equal(t, 5000, w.Len())
+
+	// push again to update the priority, and remove some
+	n := 0
+	for it := range prios {
+		if n += 1; n % 10 == 0 {
+			assert.True(t, w.Remove(it))
+			assert.False(t, w.Remove(it))
+			delete(prios, it)
+		} else if n % 10 == 1 {
+			prios[it] += 1000
+			w.Push(it, prios[it])
+		}
+	}
+	assert.Equal(t, len(prios), w.Len())
+
+	var ticks int64
+	for len(prios) > 0 {
+		next := w.NextPriority()
+		for _, p := range prios {
+			assert.LessOrEqual(t, next, p)
+		}
+
+		ticks += r.Int63n(1 << (r.Intn(30) + 1))
+		for v := w.PopReached(ticks); v != nil; v = w.PopReached(ticks) {
+			it := v.(*item)
+			assert.LessOrEqual(t, prios[it], ticks)
+			delete(prios, it)
+		}
+		for _, p := range prios {
+			assert.Greater(t, p, ticks)
+		}
+	}
+	assert.Equal(t, 0, w.Len())
+	assert.Equal(t, int64(math.MaxInt64), w.NextPriority())
+}
+
+func TestWheelScheduler(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Microsecond * 100, Scheduler: SCHEDULER_WHEEL})
+
+	// sub-ms interval
+	var cnt atomic.Int32
+	j := tm.AddInterval(nil, time.Microsecond * 500, func(j *Job) error { cnt.Add(1); return nil })
+	time.Sleep(time.Millisecond * 100)
+	assert.Greater(t, cnt.Load(), int32(50))
+
+	// paused
+	tm.Pause()
+	time.Sleep(time.Millisecond * 5)
+	c := cnt.Load()
+	time.Sleep(time.Millisecond * 20)
+	assert.Equal(t, c, cnt.Load())
+	tm.Resume()
+	time.Sleep(time.Millisecond * 20)
+	assert.Greater(t, cnt.Load(), c)
+
+	// removed from queue
+	assert.True(t, tm.RemoveJob(j.name))
+	assert.Equal(t, 0, tm.queue.Len())
+
+	// waked up from idle, the ticks escaped while idle are not counted for the new job
+	time.Sleep(time.Millisecond * 50)
+	var at atomic.Int64
+	start := time.Now()
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "once", Interval: time.Millisecond * 20, Times: 1, CB: func(j *Job) error {
+		at.Store(time.Now().UnixNano())
+		return nil
+	}})))
+	time.Sleep(time.Millisecond * 50)
+	assert.GreaterOrEqual(t, time.Duration(at.Load() - start.UnixNano()), time.Millisecond * 20)
+}
+
+func benchmarkQueues(b *testing.B, fn func(b *testing.B, q jobQueue)) {
+	b.Run("heap" , func(b *testing.B) { fn(b, newPriorityQueue()) })
+	b.Run("wheel", func(b *testing.B) { fn(b, newTimingWheel()) })
+}
+
+func BenchmarkQueuePush(b *testing.B) {
+	benchmarkQueues(b, func(b *testing.B, q jobQueue) {
+		for i := 0; i < b.N; i++ {
+			q.Push(i, int64(i * 7919 % 100000))
+		}
+	})
+}
+
+func BenchmarkQueueRemove(b *testing.B) {
+	benchmarkQueues(b, func(b *testing.B, q jobQueue) {
+		vals := make([]*int, 10000)
+		for i := range vals {
+			vals[i] = new(int)
+			q.Push(vals[i], int64(i * 7919 % 100000))
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			v := vals[i % len(vals)]
+			q.Remove(v)
+			q.Push(v, int64(i * 7919 % 100000))
+		}
+	})
+}
+
+func BenchmarkQueueAdvance(b *testing.B) {
+	benchmarkQueues(b, func(b *testing.B, q jobQueue) {
+		for i := 0; i < 10000; i++ {
+			q.Push(i, int64(i))
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ticks := int64(i)
+			for v := q.PopReached(ticks); v != nil; v = q.PopReached(ticks) {
+				q.Push(v, ticks + 10000)
+			}
+		}
+	})
+}
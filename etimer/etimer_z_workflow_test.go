@@ -0,0 +1,181 @@
+package etimer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stepRecorder struct {
+	mu      sync.Mutex
+	order   []string
+	cur     atomic.Int32
+	maxCur  atomic.Int32
+}
+
+func (r *stepRecorder) step(name string, d time.Duration, err error, deps ...string) *Job {
+	return NewJob(&JobOpts{Name: name, DependsOn: deps, CB: func(j *Job) error {
+		cur := r.cur.Add(1)
+		for {
+			max := r.maxCur.Load()
+			if cur <= max || r.maxCur.CompareAndSwap(max, cur) {
+				break
+			}
+		}
+		time.Sleep(d)
+		r.cur.Add(-1)
+
+		r.mu.Lock()
+		r.order = append(r.order, name)
+		r.mu.Unlock()
+		return err
+	}})
+}
+
+func (r *stepRecorder) got() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.order...)
+}
+
+func TestWorkflow(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	// a -> (b, c) -> d
+	r  := &stepRecorder{}
+	wf := NewWorkflow(WorkflowOpts{Name: "wf1", Interval: time.Hour})
+	wf.AddStep(r.step("d", time.Millisecond * 10, nil, "b", "c"))
+	wf.AddStep(r.step("b", time.Millisecond * 50, nil, "a"))
+	wf.AddStep(r.step("c", time.Millisecond * 50, nil, "a"))
+	wf.AddStep(r.step("a", time.Millisecond * 10, nil))
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+
+	steps := wf.Steps()
+	assert.Equal(t, "a", steps[0].Name())
+	assert.Equal(t, "d", steps[3].Name())
+	assert.NotNil(t, tm.Job("b"))
+	assert.Nil(t, tm.Job("b").NextRuns(1))
+	assert.True(t, tm.Job("b").IsStep())
+	assert.NotEqual(t, nil, tm.Job("b").TriggerNow())
+	assert.NotEqual(t, nil, tm.Job("b").TriggerAt(time.Now()))
+
+	assert.Equal(t, nil, wf.TriggerNow())
+	time.Sleep(time.Millisecond * 200)
+
+	order := r.got()
+	assert.Equal(t, 4, len(order))
+	assert.Equal(t, "a", order[0])
+	assert.Equal(t, "d", order[3])
+	assert.Equal(t, int32(2), r.maxCur.Load())
+	assert.Equal(t, uint64(1), wf.Job().State().Successs())
+	for _, s := range steps {
+		assert.Equal(t, uint64(1), s.State().Successs(), s.Name())
+	}
+
+	// the steps in a group run within the group limits
+	tm.SetGroup("g1", 1)
+	r  = &stepRecorder{}
+	wf = NewWorkflow(WorkflowOpts{Name: "wf2", Interval: time.Hour})
+	wf.AddStep(r.step("wf2_a", time.Millisecond * 30, nil), "g1")
+	wf.AddStep(r.step("wf2_b", time.Millisecond * 30, nil), "g1")
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+	assert.Equal(t, nil, wf.TriggerNow())
+	time.Sleep(time.Millisecond * 150)
+	assert.Equal(t, 2, len(r.got()))
+	assert.Equal(t, int32(1), r.maxCur.Load())
+}
+
+func TestWorkflowFailure(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+
+	// a -> b(failed) -> c, d is independent
+	r  := &stepRecorder{}
+	wf := NewWorkflow(WorkflowOpts{Name: "wf_fail", Interval: time.Hour})
+	wf.AddStep(r.step("f_a", time.Millisecond, nil))
+	wf.AddStep(r.step("f_b", time.Millisecond, errors.New("b failed"), "f_a"))
+	wf.AddStep(r.step("f_c", time.Millisecond, nil, "f_b"))
+	wf.AddStep(r.step("f_d", time.Millisecond * 20, nil))
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+	assert.Equal(t, nil, wf.TriggerNow())
+	time.Sleep(time.Millisecond * 100)
+
+	assert.ElementsMatch(t, []string{"f_a", "f_b", "f_d"}, r.got())
+	assert.Equal(t, uint64(1), tm.Job("f_b").State().Failures())
+	assert.Equal(t, uint64(0), tm.Job("f_c").State().Runnings())
+	assert.Equal(t, uint64(1), tm.Job("f_c").State().Skips())
+	assert.Equal(t, uint64(1), wf.Job().State().Failures())
+	assert.Contains(t, wf.Job().State().LastError().Error(), "step 'f_b': b failed")
+}
+
+func TestWorkflowSchedule(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond * 10})
+
+	r  := &stepRecorder{}
+	wf := NewWorkflow(WorkflowOpts{Name: "wf_cron", Pattern: "* * * * * *"})
+	wf.AddStep(r.step("s_a", time.Millisecond, nil))
+	wf.AddStep(r.step("s_b", time.Millisecond, nil, "s_a"))
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+	time.Sleep(time.Millisecond * 2200)
+
+	assert.GreaterOrEqual(t, wf.Job().State().Successs(), uint64(1))
+	assert.Equal(t, tm.Job("s_a").State().Successs(), tm.Job("s_b").State().Successs())
+}
+
+func TestWorkflowInvalid(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	cb := func(j *Job) error { return nil }
+
+	wf := NewWorkflow(WorkflowOpts{Name: "cycle", Interval: time.Hour})
+	wf.AddStep(NewJob(&JobOpts{Name: "c1", CB: cb, DependsOn: []string{"c2"}}))
+	wf.AddStep(NewJob(&JobOpts{Name: "c2", CB: cb, DependsOn: []string{"c1"}}))
+	wf.AddStep(NewJob(&JobOpts{Name: "c3", CB: cb}))
+	err := tm.AddWorkflow(wf)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "cycle dependencies: c1, c2")
+
+	wf = NewWorkflow(WorkflowOpts{Name: "missing", Interval: time.Hour})
+	wf.AddStep(NewJob(&JobOpts{Name: "m1", CB: cb, DependsOn: []string{"not_exist"}}))
+	assert.NotEqual(t, nil, tm.AddWorkflow(wf))
+
+	wf = NewWorkflow(WorkflowOpts{Name: "trigger", Interval: time.Hour})
+	wf.AddStep(NewJob(&JobOpts{Name: "t1", CB: cb, Trigger: make(chan struct{})}))
+	err = tm.AddWorkflow(wf)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "step 't1' can not have a Trigger")
+
+	// the steps added are removed if failed
+	assert.Equal(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "exist", Interval: time.Hour, CB: cb})))
+	wf = NewWorkflow(WorkflowOpts{Name: "rollback", Interval: time.Hour})
+	wf.AddStep(NewJob(&JobOpts{Name: "r1", CB: cb}))
+	wf.AddStep(NewJob(&JobOpts{Name: "exist", CB: cb, DependsOn: []string{"r1"}}))
+	assert.NotEqual(t, nil, tm.AddWorkflow(wf))
+	assert.Nil(t, tm.Job("r1"))
+
+	assert.NotEqual(t, nil, tm.AddJob(NewJob(&JobOpts{Name: "dep", Interval: time.Hour, CB: cb, DependsOn: []string{"exist"}})))
+}
+
+func TestWorkflowShutdown(t *testing.T) {
+	tm := NewTimer(TimerOptions{Interval: time.Millisecond})
+	tm.SetGroup("g1", 1)
+
+	// wd_b is pending in g1 when shutdown, it is dropped and reported to the workflow as skipped
+	r  := &stepRecorder{}
+	wf := NewWorkflow(WorkflowOpts{Name: "wf_down", Interval: time.Hour})
+	wf.AddStep(r.step("wd_a", time.Millisecond * 50, nil), "g1")
+	wf.AddStep(r.step("wd_b", time.Millisecond, nil), "g1")
+	assert.Equal(t, nil, tm.AddWorkflow(wf))
+	assert.Equal(t, nil, wf.TriggerNow())
+	time.Sleep(time.Millisecond * 20)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Equal(t, nil, tm.Shutdown(ctx))
+	assert.Equal(t, 1, len(r.got()))
+	assert.Equal(t, uint64(1), wf.Job().State().Failures())
+	assert.Contains(t, wf.Job().State().LastError().Error(), ErrJobSkipped.Error())
+}